@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
+	"github.com/korotovsky/slack-mcp-server/pkg/server"
+	"github.com/mark3labs/mcp-go/mcp"
+	"go.uber.org/zap"
+)
+
+// argFlags collects the repeated --arg key=value flags.
+type argFlags map[string]string
+
+func (a argFlags) String() string {
+	return fmt.Sprint(map[string]string(a))
+}
+
+func (a argFlags) Set(v string) error {
+	key, value, ok := strings.Cut(v, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected key=value, got %q", v)
+	}
+	a[key] = value
+	return nil
+}
+
+// runCall calls a tool the way an MCP client would, with the same policies,
+// quotas and audit log, and prints its text result.
+func runCall(args []string) int {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		fmt.Fprint(os.Stderr, "Usage: slack-mcp-server call <tool> [--arg key=value ...] [--json]\n")
+		return 2
+	}
+	tool := args[0]
+
+	f := newCommandFlags("call " + tool)
+	toolArgs := argFlags{}
+	f.Var(toolArgs, "arg", "Tool argument as key=value, can be repeated. Arrays and objects are given as JSON")
+	asJSON := f.Bool("json", false, "Print the whole tool result as JSON")
+	cfg, logger, err := f.load(args[1:], true)
+	if err != nil {
+		return fail(err)
+	}
+	defer logger.Sync()
+
+	ctx, stop := commandContext()
+	defer stop()
+
+	// the caches of the workspace of the call are loaded, it is picked
+	// with --workspace or --arg workspace=name
+	workspaces := newWorkspaces(cfg, cfg.WorkspaceConfigs(), logger)
+	if f.workspace != "" && workspaces.Multiple() && toolArgs["workspace"] == "" {
+		toolArgs["workspace"] = f.workspace
+	}
+	p, err := workspaces.Get(toolArgs["workspace"])
+	if err != nil {
+		return fail(err)
+	}
+	if !p.Demo() {
+		if err := p.RefreshUsers(ctx); err != nil {
+			return fail(fmt.Errorf("failed to load users: %w", err))
+		}
+		if err := p.RefreshChannels(ctx); err != nil {
+			return fail(fmt.Errorf("failed to load channels: %w", err))
+		}
+		if err := p.RefreshEmoji(ctx); err != nil {
+			logger.Warn("Failed to cache emoji, custom emoji will not be resolved", zap.Error(err))
+		}
+	}
+
+	s := server.NewMCPServer(config.NewLive(cfg), workspaces, logger)
+	defer func() {
+		// flushes the audit log
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+		defer cancel()
+		if err := s.Shutdown(shutdownCtx); err != nil {
+			logger.Warn("Shutdown was not clean", zap.Error(err))
+		}
+	}()
+
+	res, err := s.CallTool(ctx, tool, toolArgs)
+	if err != nil {
+		return fail(err)
+	}
+
+	if *asJSON {
+		out, err := json.MarshalIndent(res, "", "  ")
+		if err != nil {
+			return fail(err)
+		}
+		fmt.Println(string(out))
+	} else {
+		out := os.Stdout
+		if res.IsError {
+			out = os.Stderr
+		}
+		for _, content := range res.Content {
+			if text, ok := content.(mcp.TextContent); ok {
+				fmt.Fprintln(out, text.Text)
+			}
+		}
+	}
+
+	if res.IsError {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,353 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
+	"github.com/korotovsky/slack-mcp-server/pkg/provider"
+	"go.uber.org/zap"
+)
+
+const commandsUsage = `Usage:
+  slack-mcp-server [-t stdio|sse|http] [flags]   run the MCP server
+  slack-mcp-server auth test                     check the Slack tokens
+  slack-mcp-server cache warm|inspect|clear      manage the users, channels and emoji caches
+  slack-mcp-server doctor                        check the proxy, CA, TLS and Slack reachability
+  slack-mcp-server call <tool> [--arg k=v ...]   call an MCP tool and print its result
+
+Every command accepts -c/--config, --log-level and -w/--workspace to only use
+one of several workspaces, run a command with -h for its flags.
+`
+
+// runCommand runs a subcommand and returns the exit code.
+func runCommand(name string, args []string) int {
+	switch name {
+	case "auth":
+		return runAuth(args)
+	case "cache":
+		return runCache(args)
+	case "doctor":
+		return runDoctor(args)
+	case "call":
+		return runCall(args)
+	case "help":
+		fmt.Print(commandsUsage)
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n%s", name, commandsUsage)
+		return 2
+	}
+}
+
+// commandFlags are the flags shared by the subcommands.
+type commandFlags struct {
+	*flag.FlagSet
+	configPath string
+	logLevel   string
+	workspace  string
+}
+
+func newCommandFlags(name string) *commandFlags {
+	f := &commandFlags{FlagSet: flag.NewFlagSet(name, flag.ExitOnError)}
+	f.StringVar(&f.configPath, "c", os.Getenv("SLACK_MCP_CONFIG"), "Path to a YAML or TOML config file")
+	f.StringVar(&f.configPath, "config", os.Getenv("SLACK_MCP_CONFIG"), "Path to a YAML or TOML config file")
+	f.StringVar(&f.logLevel, "log-level", "", "Log level (debug, info, warn, error)")
+	f.StringVar(&f.workspace, "w", "", "Only use this workspace, all are used by default")
+	f.StringVar(&f.workspace, "workspace", "", "Only use this workspace, all are used by default")
+	return f
+}
+
+// workspaceConfigs returns the configurations of the workspaces to use, see
+// config.Config.WorkspaceConfigs.
+func (f *commandFlags) workspaceConfigs(cfg *config.Config) ([]*config.Config, error) {
+	configs := cfg.WorkspaceConfigs()
+	if f.workspace == "" {
+		return configs, nil
+	}
+	for _, wc := range configs {
+		if wc.WorkspaceName == f.workspace {
+			return []*config.Config{wc}, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown workspace %q", f.workspace)
+}
+
+// workspacePrefix labels the output of a workspace when there are several.
+func workspacePrefix(cfg *config.Config) string {
+	if cfg.WorkspaceName == "" {
+		return ""
+	}
+	return cfg.WorkspaceName + ": "
+}
+
+// load parses args and loads the configuration. Logs go to stderr, so the
+// output of the command can be piped.
+func (f *commandFlags) load(args []string, validate bool) (*config.Config, *zap.Logger, error) {
+	if err := f.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	flags := map[string]string{"transport": "stdio"}
+	if f.logLevel != "" {
+		flags["log_level"] = f.logLevel
+	}
+	cfg, err := config.Load(f.configPath, flags)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cfg.MultiTenant {
+		// the tokens are sent by the clients of the server
+		return nil, nil, errors.New("commands need the Slack tokens of the configuration, they cannot be used with SLACK_MCP_MULTI_TENANT")
+	}
+	if validate {
+		if err := cfg.Validate(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	logger, _, err := newLogger(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, logger, nil
+}
+
+func commandContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+func fail(err error) int {
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	return 1
+}
+
+func runAuth(args []string) int {
+	if len(args) == 0 || args[0] != "test" {
+		fmt.Fprint(os.Stderr, "Usage: slack-mcp-server auth test [--json]\n")
+		return 2
+	}
+
+	f := newCommandFlags("auth test")
+	asJSON := f.Bool("json", false, "Print the result as JSON")
+	cfg, logger, err := f.load(args[1:], true)
+	if err != nil {
+		return fail(err)
+	}
+	defer logger.Sync()
+	configs, err := f.workspaceConfigs(cfg)
+	if err != nil {
+		return fail(err)
+	}
+
+	var results []map[string]string
+	for _, wc := range configs {
+		if wc.Demo() {
+			return fail(errors.New(workspacePrefix(wc) + "demo credentials are set, there is nothing to test"))
+		}
+
+		client, err := provider.Connect(wc, logger)
+		if err != nil {
+			return fail(fmt.Errorf("%sauthentication failed: %w", workspacePrefix(wc), err))
+		}
+		resp := client.AuthResponse()
+
+		tokenType := "xoxc/xoxd (browser session)"
+		if client.IsOAuth() {
+			tokenType = "xoxp (User OAuth)"
+		}
+		enterprise := resp.EnterpriseID
+		if enterprise == "" && !*asJSON {
+			enterprise = "-"
+		}
+		results = append(results, map[string]string{
+			"workspace":  wc.WorkspaceName,
+			"team":       resp.Team,
+			"team_id":    resp.TeamID,
+			"user":       resp.User,
+			"user_id":    resp.UserID,
+			"enterprise": enterprise,
+			"url":        resp.URL,
+			"token_type": tokenType,
+		})
+	}
+
+	if *asJSON {
+		// a single object unless there are several workspaces
+		var v any = results
+		if len(results) == 1 {
+			delete(results[0], "workspace")
+			v = results[0]
+		}
+		out, _ := json.MarshalIndent(v, "", "  ")
+		fmt.Println(string(out))
+		return 0
+	}
+
+	for i, r := range results {
+		if i > 0 {
+			fmt.Println()
+		}
+		if r["workspace"] != "" {
+			fmt.Printf("Workspace:  %s\n", r["workspace"])
+		}
+		fmt.Printf("Team:       %s (%s)\n", r["team"], r["team_id"])
+		fmt.Printf("User:       %s (%s)\n", r["user"], r["user_id"])
+		fmt.Printf("Enterprise: %s\n", r["enterprise"])
+		fmt.Printf("URL:        %s\n", r["url"])
+		fmt.Printf("Token type: %s\n", r["token_type"])
+	}
+	return 0
+}
+
+func runCache(args []string) int {
+	const usage = "Usage: slack-mcp-server cache warm|inspect|clear\n"
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	sub := args[0]
+	f := newCommandFlags("cache " + sub)
+	switch sub {
+	case "warm":
+		cfg, logger, err := f.load(args[1:], true)
+		if err != nil {
+			return fail(err)
+		}
+		defer logger.Sync()
+		configs, err := f.workspaceConfigs(cfg)
+		if err != nil {
+			return fail(err)
+		}
+		for _, wc := range configs {
+			if code := cacheWarm(wc, workspaceLogger(wc, logger)); code != 0 {
+				return code
+			}
+		}
+		return 0
+	case "inspect", "clear":
+		// the cache files are found without tokens
+		cfg, logger, err := f.load(args[1:], false)
+		if err != nil {
+			return fail(err)
+		}
+		defer logger.Sync()
+		configs, err := f.workspaceConfigs(cfg)
+		if err != nil {
+			return fail(err)
+		}
+		code := 0
+		for _, wc := range configs {
+			if sub == "inspect" {
+				code = max(code, cacheInspect(wc))
+			} else {
+				code = max(code, cacheClear(wc))
+			}
+		}
+		return code
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+}
+
+// cacheWarm fetches the users and channels from Slack and rewrites their
+// cache files, so that a server started afterwards is ready right away.
+func cacheWarm(cfg *config.Config, logger *zap.Logger) int {
+	if cfg.Demo() {
+		return fail(errors.New(workspacePrefix(cfg) + "demo credentials are set, there is nothing to cache"))
+	}
+
+	ctx, stop := commandContext()
+	defer stop()
+
+	p := provider.New(cfg, logger)
+	if err := p.FetchUsers(ctx); err != nil {
+		return fail(fmt.Errorf("%sfailed to fetch users: %w", workspacePrefix(cfg), err))
+	}
+	if err := p.FetchChannels(ctx); err != nil {
+		return fail(fmt.Errorf("%sfailed to fetch channels: %w", workspacePrefix(cfg), err))
+	}
+	if err := p.RefreshEmoji(ctx); err != nil {
+		logger.Warn("Failed to cache emoji, custom emoji will not be resolved", zap.Error(err))
+	}
+
+	paths := make(map[string]string)
+	for _, c := range provider.CacheFiles(cfg) {
+		paths[c.Name] = c.Path
+	}
+	for _, stat := range p.CacheStats() {
+		fmt.Printf("%s%-8s %6d entries  %s\n", workspacePrefix(cfg), stat.Name, stat.Size, paths[stat.Name])
+	}
+	return 0
+}
+
+func cacheInspect(cfg *config.Config) int {
+	prefix := workspacePrefix(cfg)
+	for _, c := range provider.CacheFiles(cfg) {
+		info, err := os.Stat(c.Path)
+		if errors.Is(err, fs.ErrNotExist) {
+			fmt.Printf("%s%-8s %s: missing\n", prefix, c.Name, c.Path)
+			continue
+		}
+		if err != nil {
+			fmt.Printf("%s%-8s %s: %v\n", prefix, c.Name, c.Path, err)
+			continue
+		}
+
+		entries, err := countCacheEntries(c.Path)
+		if err != nil {
+			fmt.Printf("%s%-8s %s: unreadable, it is fetched again on start: %v\n", prefix, c.Name, c.Path, err)
+			continue
+		}
+		fmt.Printf("%s%-8s %s: %d entries, %d bytes, updated %s (%s ago)\n",
+			prefix, c.Name, c.Path, entries, info.Size(),
+			info.ModTime().Format(time.RFC3339), time.Since(info.ModTime()).Round(time.Second))
+	}
+	return 0
+}
+
+// countCacheEntries counts the entries of a users or channels cache, which
+// are lists, or of the emoji cache, which is a map.
+func countCacheEntries(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	var list []json.RawMessage
+	if err := json.Unmarshal(data, &list); err == nil {
+		return len(list), nil
+	}
+	var emoji provider.EmojiCache
+	if err := json.Unmarshal(data, &emoji); err != nil {
+		return 0, err
+	}
+	return len(emoji.Emoji), nil
+}
+
+func cacheClear(cfg *config.Config) int {
+	code := 0
+	prefix := workspacePrefix(cfg)
+	for _, c := range provider.CacheFiles(cfg) {
+		err := os.Remove(c.Path)
+		switch {
+		case err == nil:
+			fmt.Printf("%s%-8s %s: removed\n", prefix, c.Name, c.Path)
+		case errors.Is(err, fs.ErrNotExist):
+			fmt.Printf("%s%-8s %s: missing\n", prefix, c.Name, c.Path)
+		default:
+			fmt.Fprintf(os.Stderr, "%s%-8s %s: %v\n", prefix, c.Name, c.Path, err)
+			code = 1
+		}
+	}
+	return code
+}
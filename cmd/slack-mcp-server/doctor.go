@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
+	"github.com/korotovsky/slack-mcp-server/pkg/provider"
+	"github.com/korotovsky/slack-mcp-server/pkg/transport"
+	"go.uber.org/zap"
+)
+
+const (
+	slackAPITestURL = "https://slack.com/api/api.test"
+	edgeAPIURL      = "https://edgeapi.slack.com/"
+	doctorTimeout   = 10 * time.Second
+)
+
+type checkStatus string
+
+const (
+	checkOK   checkStatus = "OK"
+	checkWarn checkStatus = "WARN"
+	checkFail checkStatus = "FAIL"
+	checkSkip checkStatus = "SKIP"
+)
+
+// runDoctor checks the settings and the network path to Slack one by one and
+// prints the outcome of each, it exits with 1 when any check fails.
+func runDoctor(args []string) int {
+	f := newCommandFlags("doctor")
+	cfg, logger, err := f.load(args, false)
+	if err != nil {
+		return fail(err)
+	}
+	defer logger.Sync()
+
+	ctx, stop := commandContext()
+	defer stop()
+
+	configs, err := f.workspaceConfigs(cfg)
+	if err != nil {
+		return fail(err)
+	}
+
+	failed := false
+	report := func(name string, status checkStatus, detail string) {
+		fmt.Printf("[%-4s] %-13s %s\n", status, name, detail)
+		if status == checkFail {
+			failed = true
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		report("configuration", checkFail, err.Error())
+	} else {
+		report("configuration", checkOK, "valid")
+	}
+
+	proxyName, proxyStatus, proxyDetail := checkProxy(ctx, cfg)
+	report(proxyName, proxyStatus, proxyDetail)
+	caName, caStatus, caDetail := checkCA(cfg)
+	report(caName, caStatus, caDetail)
+
+	if name := transport.Fingerprint(cfg); name != "" {
+		report("tls", checkOK, "custom TLS handshake of "+name)
+	} else {
+		report("tls", checkOK, "standard Go TLS handshake")
+	}
+
+	if proxyStatus == checkFail || caStatus == checkFail {
+		for _, name := range []string{"slack api", "edge api", "auth"} {
+			report(name, checkSkip, "fix the proxy and CA first")
+		}
+		return 1
+	}
+
+	// the client checks the proxy, CA and TLS settings together
+	client := transport.ProvideHTTPClient(cfg, nil, logger)
+	report(checkSlackAPI(ctx, client))
+	report(checkEdgeAPI(ctx, client, configs))
+	for _, wc := range configs {
+		name, status, detail := checkAuth(wc, logger)
+		if wc.WorkspaceName != "" {
+			name += " " + wc.WorkspaceName
+		}
+		report(name, status, detail)
+	}
+
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+func checkProxy(ctx context.Context, cfg *config.Config) (string, checkStatus, string) {
+	const name = "proxy"
+	if cfg.Proxy == "" {
+		return name, checkSkip, "SLACK_MCP_PROXY is not set"
+	}
+	u, err := url.Parse(cfg.Proxy)
+	if err != nil || u.Host == "" {
+		return name, checkFail, "SLACK_MCP_PROXY is not a valid proxy URL"
+	}
+
+	host := u.Host
+	if u.Port() == "" {
+		port := "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+		host = net.JoinHostPort(u.Hostname(), port)
+	}
+	dialer := &net.Dialer{Timeout: doctorTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return name, checkFail, fmt.Sprintf("cannot connect to %s: %v", host, err)
+	}
+	conn.Close()
+	return name, checkOK, "reachable at " + host
+}
+
+func checkCA(cfg *config.Config) (string, checkStatus, string) {
+	const name = "ca"
+	if cfg.ServerCAInsecure {
+		return name, checkWarn, "SLACK_MCP_SERVER_CA_INSECURE is set, server certificates are not verified"
+	}
+	if cfg.ServerCA == "" {
+		detail := "system certificates"
+		if cfg.ServerCAToolkit {
+			detail += " and the HTTP Toolkit CA"
+		}
+		return name, checkOK, detail
+	}
+
+	data, err := os.ReadFile(cfg.ServerCA)
+	if err != nil {
+		return name, checkFail, err.Error()
+	}
+	count := 0
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return name, checkFail, fmt.Sprintf("%s: %v", cfg.ServerCA, err)
+		}
+		if time.Now().After(cert.NotAfter) {
+			return name, checkFail, fmt.Sprintf("%s: certificate %q expired on %s", cfg.ServerCA, cert.Subject.CommonName, cert.NotAfter.Format(time.DateOnly))
+		}
+		count++
+	}
+	if count == 0 {
+		return name, checkFail, fmt.Sprintf("%s: no PEM certificate found", cfg.ServerCA)
+	}
+	return name, checkOK, fmt.Sprintf("%d certificates from %s", count, cfg.ServerCA)
+}
+
+func checkSlackAPI(ctx context.Context, client *http.Client) (string, checkStatus, string) {
+	const name = "slack api"
+	status, data, err := doctorGet(ctx, client, slackAPITestURL)
+	if err != nil {
+		return name, checkFail, err.Error()
+	}
+
+	var body struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return name, checkFail, fmt.Sprintf("unexpected answer from %s, HTTP %d", slackAPITestURL, status)
+	}
+	if !body.OK {
+		return name, checkFail, fmt.Sprintf("%s answered %q", slackAPITestURL, body.Error)
+	}
+	return name, checkOK, "reachable at " + slackAPITestURL
+}
+
+// checkEdgeAPI only checks that the edge API answers, any HTTP status will
+// do as the request is not authenticated. It is skipped when no workspace
+// uses browser session tokens.
+func checkEdgeAPI(ctx context.Context, client *http.Client, configs []*config.Config) (string, checkStatus, string) {
+	const name = "edge api"
+	if !slices.ContainsFunc(configs, func(c *config.Config) bool { return c.XOXPToken == "" }) {
+		return name, checkSkip, "only used with browser session tokens"
+	}
+	status, _, err := doctorGet(ctx, client, edgeAPIURL)
+	if err != nil {
+		return name, checkFail, err.Error()
+	}
+	return name, checkOK, fmt.Sprintf("reachable at %s, HTTP %d", edgeAPIURL, status)
+}
+
+func checkAuth(cfg *config.Config, logger *zap.Logger) (string, checkStatus, string) {
+	const name = "auth"
+	if cfg.Demo() {
+		return name, checkSkip, "demo credentials are set"
+	}
+	if cfg.XOXPToken == "" && (cfg.XOXCToken == "" || cfg.XOXDToken == "") {
+		return name, checkSkip, "no tokens are set"
+	}
+	client, err := provider.Connect(cfg, logger)
+	if err != nil {
+		return name, checkFail, err.Error()
+	}
+	resp := client.AuthResponse()
+	return name, checkOK, fmt.Sprintf("%s in %s (%s)", resp.User, resp.Team, resp.URL)
+}
+
+// doctorGet returns the status and the beginning of the body of target.
+func doctorGet(ctx context.Context, client *http.Client, target string) (int, []byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("cannot reach %s: %w", target, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	if err != nil {
+		return 0, nil, fmt.Errorf("cannot read the answer of %s: %w", target, err)
+	}
+	return resp.StatusCode, data, nil
+}
@@ -2,144 +2,350 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
 	"github.com/korotovsky/slack-mcp-server/pkg/provider"
 	"github.com/korotovsky/slack-mcp-server/pkg/server"
+	"github.com/korotovsky/slack-mcp-server/pkg/tracing"
 	"github.com/mattn/go-isatty"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
-var defaultSseHost = "127.0.0.1"
-var defaultSsePort = 13080
-
 func main() {
-	var transport string
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		os.Exit(runCommand(os.Args[1], os.Args[2:]))
+	}
+
+	var configPath, transport, host, logLevel string
+	var port int
+	var printConfig bool
+	flag.StringVar(&configPath, "c", os.Getenv("SLACK_MCP_CONFIG"), "Path to a YAML or TOML config file")
+	flag.StringVar(&configPath, "config", os.Getenv("SLACK_MCP_CONFIG"), "Path to a YAML or TOML config file")
 	flag.StringVar(&transport, "t", "stdio", "Transport type (stdio, sse or http)")
 	flag.StringVar(&transport, "transport", "stdio", "Transport type (stdio, sse or http)")
+	flag.StringVar(&host, "host", "127.0.0.1", "Host to listen on in sse and http modes")
+	flag.IntVar(&port, "port", 13080, "Port to listen on in sse and http modes")
+	flag.StringVar(&logLevel, "log-level", "info", "Log level (debug, info, warn, error)")
+	flag.BoolVar(&printConfig, "print-config", false, "Print the effective configuration with secrets masked and exit")
 	flag.Parse()
 
-	logger, err := newLogger(transport)
+	// only the flags given on the command line override the file and env
+	flags := make(map[string]string)
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "t", "transport":
+			flags["transport"] = transport
+		case "host":
+			flags["host"] = host
+		case "port":
+			flags["port"] = strconv.Itoa(port)
+		case "log-level":
+			flags["log_level"] = logLevel
+		}
+	})
+
+	cfg, err := config.Load(configPath, flags)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration:\n%v\n", err)
+		os.Exit(1)
+	}
+	if printConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration:\n%v\n", err)
+		os.Exit(1)
+	}
+	if printConfig {
+		return
+	}
+
+	logger, level, err := newLogger(cfg)
 	if err != nil {
 		panic(err)
 	}
 	defer logger.Sync()
 
-	err = validateToolConfig(os.Getenv("SLACK_MCP_ADD_MESSAGE_TOOL"))
+	for _, warning := range cfg.Warnings {
+		logger.Warn(warning, zap.String("context", "console"))
+	}
+	logConfig(cfg, logger)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.OTelEndpoint, cfg.OTelSampleRatio)
 	if err != nil {
-		logger.Fatal("error in SLACK_MCP_ADD_MESSAGE_TOOL",
+		logger.Fatal("Failed to set up tracing",
 			zap.String("context", "console"),
 			zap.Error(err),
 		)
 	}
+	defer shutdownTracing(context.Background())
+
+	live := config.NewLive(cfg)
+	var (
+		workspaceConfigs []*config.Config
+		workspaces       *provider.Workspaces
+		s                *server.MCPServer
+	)
+	if cfg.MultiTenant {
+		s = server.NewMultiTenantMCPServer(live, logger)
+	} else {
+		workspaceConfigs = cfg.WorkspaceConfigs()
+		workspaces = newWorkspaces(cfg, workspaceConfigs, logger)
+		s = server.NewMCPServer(live, workspaces, logger)
+	}
 
-	p := provider.New(transport, logger)
-	s := server.NewMCPServer(p, logger)
-
+	reload := func() { reloadConfig(configPath, flags, live, s, level, logger) }
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
 	go func() {
-		var once sync.Once
-
-		newUsersWatcher(p, &once, logger)()
-		newChannelsWatcher(p, &once, logger)()
+		for {
+			select {
+			case <-hup:
+				reload()
+			case <-ctx.Done():
+				return
+			}
+		}
 	}()
+	if configPath != "" && cfg.ConfigWatch > 0 {
+		go config.Watch(ctx, configPath, cfg.ConfigWatch, reload)
+	}
+
+	// the caches of tenants are loaded by the server on their first call
+	var once sync.Once
+	for i, wc := range workspaceConfigs {
+		p, wsLogger := workspaces.All()[i], workspaceLogger(wc, logger)
+		go func() {
+			newUsersWatcher(ctx, wc, p, workspaces, &once, wsLogger)()
+			newChannelsWatcher(ctx, wc, p, workspaces, &once, wsLogger)()
+			newEmojiWatcher(ctx, wc, p, wsLogger)()
+		}()
+	}
 
-	switch transport {
+	host, port = cfg.Host, cfg.Port
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+	switch cfg.Transport {
 	case "stdio":
-		if err := s.ServeStdio(); err != nil {
+		if err := s.ServeStdio(ctx); err != nil {
 			logger.Fatal("Server error",
 				zap.String("context", "console"),
 				zap.Error(err),
 			)
 		}
 	case "sse":
-		host := os.Getenv("SLACK_MCP_HOST")
-		if host == "" {
-			host = defaultSseHost
-		}
-		port := os.Getenv("SLACK_MCP_PORT")
-		if port == "" {
-			port = strconv.Itoa(defaultSsePort)
-		}
-
-		sseServer := s.ServeSSE(":" + port)
+		sseServer := s.ServeSSE(":" + strconv.Itoa(port))
 		logger.Info(
-			fmt.Sprintf("SSE server listening on %s", fmt.Sprintf("%s:%s/sse", host, port)),
+			fmt.Sprintf("SSE server listening on %s/sse", addr),
 			zap.String("context", "console"),
 			zap.String("host", host),
-			zap.String("port", port),
+			zap.Int("port", port),
 		)
 
-		if ready, _ := p.IsReady(); !ready {
-			logger.Info("Slack MCP Server is still warming up caches",
-				zap.String("context", "console"),
-			)
+		if workspaces != nil {
+			if ready, _ := workspaces.IsReady(); !ready {
+				logger.Info("Slack MCP Server is still warming up caches",
+					zap.String("context", "console"),
+				)
+			}
 		}
 
-		if err := sseServer.Start(host + ":" + port); err != nil {
-			logger.Fatal("Server error",
-				zap.String("context", "console"),
-				zap.Error(err),
-			)
-		}
+		serveUntilSignal(ctx, func() error { return sseServer.Start(addr) }, logger)
 	case "http":
-		host := os.Getenv("SLACK_MCP_HOST")
-		if host == "" {
-			host = defaultSseHost
-		}
-		port := os.Getenv("SLACK_MCP_PORT")
-		if port == "" {
-			port = strconv.Itoa(defaultSsePort)
-		}
-
-		httpServer := s.ServeHTTP(":" + port)
+		httpServer := s.ServeHTTP(":" + strconv.Itoa(port))
 		logger.Info(
-			fmt.Sprintf("HTTP server listening on %s", fmt.Sprintf("%s:%s", host, port)),
+			fmt.Sprintf("HTTP server listening on %s", addr),
 			zap.String("context", "console"),
 			zap.String("host", host),
-			zap.String("port", port),
+			zap.Int("port", port),
 		)
 
-		if ready, _ := p.IsReady(); !ready {
-			logger.Info("Slack MCP Server is still warming up caches",
-				zap.String("context", "console"),
-			)
+		if workspaces != nil {
+			if ready, _ := workspaces.IsReady(); !ready {
+				logger.Info("Slack MCP Server is still warming up caches",
+					zap.String("context", "console"),
+				)
+			}
+		}
+
+		serveUntilSignal(ctx, func() error { return httpServer.Start(addr) }, logger)
+	}
+
+	stop()
+	logger.Info("Shutting down...",
+		zap.String("context", "console"),
+	)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := s.Shutdown(shutdownCtx); err != nil {
+		logger.Warn("Shutdown was not clean",
+			zap.String("context", "console"),
+			zap.Error(err),
+		)
+		return
+	}
+	logger.Info("Shutdown complete",
+		zap.String("context", "console"),
+	)
+}
+
+// reloadConfig loads the configuration again on SIGHUP or when the config
+// file changes, applies the settings that can change while running and logs
+// what changed. The current configuration is kept when the new one is
+// invalid.
+func reloadConfig(path string, flags map[string]string, live *config.Live, s *server.MCPServer, level zap.AtomicLevel, logger *zap.Logger) {
+	next, err := config.Load(path, flags)
+	if err == nil {
+		err = next.Validate()
+	}
+	if err != nil {
+		logger.Error("Failed to reload configuration, keeping the current one",
+			zap.String("context", "console"),
+			zap.Error(err),
+		)
+		return
+	}
+
+	current := live.Get()
+	changes := current.Diff(next)
+	if len(changes) == 0 {
+		logger.Info("Configuration reloaded, nothing changed",
+			zap.String("context", "console"),
+		)
+		return
+	}
+
+	reloaded := current.Reload(next)
+	if err := s.Reload(reloaded); err != nil {
+		logger.Error("Failed to reload configuration, keeping the current one",
+			zap.String("context", "console"),
+			zap.Error(err),
+		)
+		return
+	}
+	if err := level.UnmarshalText([]byte(reloaded.LogLevel)); err != nil {
+		logger.Error("Failed to change the log level",
+			zap.String("context", "console"),
+			zap.Error(err),
+		)
+	}
+
+	for _, c := range changes {
+		fields := []zap.Field{
+			zap.String("context", "console"),
+			zap.String("setting", c.Env),
+			zap.String("old", c.Old),
+			zap.String("new", c.New),
+		}
+		if c.Reloadable {
+			logger.Info("Configuration changed", fields...)
+		} else {
+			logger.Warn("Configuration change needs a restart to apply", fields...)
 		}
+	}
+}
+
+// logConfig logs the settings that are set, with secrets masked.
+func logConfig(cfg *config.Config, logger *zap.Logger) {
+	values := cfg.Values()
+	envs := make([]string, 0, len(values))
+	for env, v := range values {
+		if v != "" {
+			envs = append(envs, env)
+		}
+	}
+	sort.Strings(envs)
+
+	fields := []zap.Field{zap.String("context", "console")}
+	for _, env := range envs {
+		fields = append(fields, zap.String(config.Key(env), values[env]))
+	}
+	logger.Info("Effective configuration", fields...)
+}
 
-		if err := httpServer.Start(host + ":" + port); err != nil {
+// serveUntilSignal runs start until ctx is done by SIGINT or SIGTERM, the
+// server is stopped by the Shutdown that follows.
+func serveUntilSignal(ctx context.Context, start func() error, logger *zap.Logger) {
+	errc := make(chan error, 1)
+	go func() {
+		errc <- start()
+	}()
+
+	select {
+	case err := <-errc:
+		if !errors.Is(err, http.ErrServerClosed) {
 			logger.Fatal("Server error",
 				zap.String("context", "console"),
 				zap.Error(err),
 			)
 		}
-	default:
-		logger.Fatal("Invalid transport type",
+	case <-ctx.Done():
+	}
+}
+
+// newWorkspaces builds the provider of every workspace, configs are the
+// configurations returned by cfg.WorkspaceConfigs.
+func newWorkspaces(cfg *config.Config, configs []*config.Config, logger *zap.Logger) *provider.Workspaces {
+	providers := make([]*provider.ApiProvider, 0, len(configs))
+	for _, wc := range configs {
+		providers = append(providers, provider.New(wc, workspaceLogger(wc, logger)))
+	}
+	workspaces, err := provider.NewWorkspaces(providers, cfg.DefaultWorkspace)
+	if err != nil {
+		logger.Fatal("Invalid workspaces",
 			zap.String("context", "console"),
-			zap.String("transport", transport),
-			zap.String("allowed", "stdio, sse, http"),
+			zap.Error(err),
 		)
 	}
+	return workspaces
+}
+
+// workspaceLogger names the workspace in the logs of a server with several
+// workspaces.
+func workspaceLogger(cfg *config.Config, logger *zap.Logger) *zap.Logger {
+	if cfg.WorkspaceName == "" {
+		return logger
+	}
+	return logger.With(zap.String("workspace", cfg.WorkspaceName))
 }
 
-func newUsersWatcher(p *provider.ApiProvider, once *sync.Once, logger *zap.Logger) func() {
+func newUsersWatcher(ctx context.Context, cfg *config.Config, p *provider.ApiProvider, all *provider.Workspaces, once *sync.Once, logger *zap.Logger) func() {
 	return func() {
 		logger.Info("Caching users collection...",
 			zap.String("context", "console"),
 		)
 
-		if os.Getenv("SLACK_MCP_XOXP_TOKEN") == "demo" || (os.Getenv("SLACK_MCP_XOXC_TOKEN") == "demo" && os.Getenv("SLACK_MCP_XOXD_TOKEN") == "demo") {
+		if cfg.Demo() {
 			logger.Info("Demo credentials are set, skip",
 				zap.String("context", "console"),
 			)
 			return
 		}
 
-		err := p.RefreshUsers(context.Background())
+		err := p.RefreshUsers(ctx)
+		if err != nil && ctx.Err() != nil {
+			// shutting down
+			return
+		}
 		if err != nil {
 			logger.Fatal("Error booting provider",
 				zap.String("context", "console"),
@@ -147,7 +353,7 @@ func newUsersWatcher(p *provider.ApiProvider, once *sync.Once, logger *zap.Logge
 			)
 		}
 
-		ready, _ := p.IsReady()
+		ready, _ := all.IsReady()
 		if ready {
 			once.Do(func() {
 				logger.Info("Slack MCP Server is fully ready",
@@ -158,20 +364,24 @@ func newUsersWatcher(p *provider.ApiProvider, once *sync.Once, logger *zap.Logge
 	}
 }
 
-func newChannelsWatcher(p *provider.ApiProvider, once *sync.Once, logger *zap.Logger) func() {
+func newChannelsWatcher(ctx context.Context, cfg *config.Config, p *provider.ApiProvider, all *provider.Workspaces, once *sync.Once, logger *zap.Logger) func() {
 	return func() {
 		logger.Info("Caching channels collection...",
 			zap.String("context", "console"),
 		)
 
-		if os.Getenv("SLACK_MCP_XOXP_TOKEN") == "demo" || (os.Getenv("SLACK_MCP_XOXC_TOKEN") == "demo" && os.Getenv("SLACK_MCP_XOXD_TOKEN") == "demo") {
+		if cfg.Demo() {
 			logger.Info("Demo credentials are set, skip.",
 				zap.String("context", "console"),
 			)
 			return
 		}
 
-		err := p.RefreshChannels(context.Background())
+		err := p.RefreshChannels(ctx)
+		if err != nil && ctx.Err() != nil {
+			// shutting down
+			return
+		}
 		if err != nil {
 			logger.Fatal("Error booting provider",
 				zap.String("context", "console"),
@@ -179,7 +389,7 @@ func newChannelsWatcher(p *provider.ApiProvider, once *sync.Once, logger *zap.Lo
 			)
 		}
 
-		ready, _ := p.IsReady()
+		ready, _ := all.IsReady()
 		if ready {
 			once.Do(func() {
 				logger.Info("Slack MCP Server is fully ready.",
@@ -190,54 +400,49 @@ func newChannelsWatcher(p *provider.ApiProvider, once *sync.Once, logger *zap.Lo
 	}
 }
 
-func validateToolConfig(config string) error {
-	if config == "" || config == "true" || config == "1" {
-		return nil
-	}
-
-	items := strings.Split(config, ",")
-	hasNegated := false
-	hasPositive := false
+func newEmojiWatcher(ctx context.Context, cfg *config.Config, p *provider.ApiProvider, logger *zap.Logger) func() {
+	return func() {
+		logger.Info("Caching emoji collection...",
+			zap.String("context", "console"),
+		)
 
-	for _, item := range items {
-		item = strings.TrimSpace(item)
-		if item == "" {
-			continue
-		}
-		if strings.HasPrefix(item, "!") {
-			hasNegated = true
-		} else {
-			hasPositive = true
+		if cfg.Demo() {
+			logger.Info("Demo credentials are set, skip.",
+				zap.String("context", "console"),
+			)
+			return
 		}
-	}
 
-	if hasNegated && hasPositive {
-		return fmt.Errorf("cannot mix allowed and disallowed (! prefixed) channels")
+		// custom emoji are cosmetic, they are rendered as :name: without the cache
+		if err := p.RefreshEmoji(ctx); err != nil && ctx.Err() == nil {
+			logger.Warn("Failed to cache emoji, custom emoji will not be resolved",
+				zap.String("context", "console"),
+				zap.Error(err),
+			)
+		}
 	}
-
-	return nil
 }
 
-func newLogger(transport string) (*zap.Logger, error) {
+// newLogger builds the logger and returns its level, which changes on
+// configuration reload.
+func newLogger(cfg *config.Config) (*zap.Logger, zap.AtomicLevel, error) {
 	atomicLevel := zap.NewAtomicLevelAt(zap.InfoLevel)
-	if envLevel := os.Getenv("SLACK_MCP_LOG_LEVEL"); envLevel != "" {
-		if err := atomicLevel.UnmarshalText([]byte(envLevel)); err != nil {
-			fmt.Printf("Invalid log level '%s': %v, using 'info'\n", envLevel, err)
-		}
+	if err := atomicLevel.UnmarshalText([]byte(cfg.LogLevel)); err != nil {
+		return nil, atomicLevel, err
 	}
 
-	useJSON := shouldUseJSONFormat()
-	useColors := shouldUseColors() && !useJSON
+	useJSON := shouldUseJSONFormat(cfg.LogFormat)
+	useColors := shouldUseColors(cfg.LogColor) && !useJSON
 
 	outputPath := "stdout"
-	if transport == "stdio" {
+	if cfg.Transport == "stdio" {
 		outputPath = "stderr"
 	}
 
-	var config zap.Config
+	var zapConfig zap.Config
 
 	if useJSON {
-		config = zap.Config{
+		zapConfig = zap.Config{
 			Level:            atomicLevel,
 			Development:      false,
 			Encoding:         "json",
@@ -255,7 +460,7 @@ func newLogger(transport string) (*zap.Logger, error) {
 			},
 		}
 	} else {
-		config = zap.Config{
+		zapConfig = zap.Config{
 			Level:            atomicLevel,
 			Development:      true,
 			Encoding:         "console",
@@ -275,19 +480,19 @@ func newLogger(transport string) (*zap.Logger, error) {
 		}
 	}
 
-	logger, err := config.Build(zap.AddCaller())
+	logger, err := zapConfig.Build(zap.AddCaller())
 	if err != nil {
-		return nil, err
+		return nil, atomicLevel, err
 	}
 
 	logger = logger.With(zap.String("app", "slack-mcp-server"))
 
-	return logger, err
+	return logger, atomicLevel, err
 }
 
 // shouldUseJSONFormat determines if JSON format should be used
-func shouldUseJSONFormat() bool {
-	if format := os.Getenv("SLACK_MCP_LOG_FORMAT"); format != "" {
+func shouldUseJSONFormat(format string) bool {
+	if format != "" {
 		return strings.ToLower(format) == "json"
 	}
 
@@ -313,9 +518,13 @@ func shouldUseJSONFormat() bool {
 	return false
 }
 
-func shouldUseColors() bool {
-	if colorEnv := os.Getenv("SLACK_MCP_LOG_COLOR"); colorEnv != "" {
-		return colorEnv == "true" || colorEnv == "1"
+func shouldUseColors(color string) bool {
+	if color != "" {
+		switch strings.ToLower(color) {
+		case "1", "true", "yes", "on":
+			return true
+		}
+		return false
 	}
 
 	if os.Getenv("NO_COLOR") != "" {
@@ -0,0 +1,156 @@
+// Package access decides which conversations the server may read from. The
+// policy hides channels from every read tool and resource, so the model
+// never sees their content or their names.
+package access
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+const (
+	ClassPublic  = "public"
+	ClassPrivate = "private"
+	ClassIM      = "im"
+	ClassMpIM    = "mpim"
+)
+
+// Channel is what the policy needs to know about a conversation.
+type Channel struct {
+	ID   string
+	Name string
+	// Class is one of public, private, im or mpim.
+	Class string
+}
+
+// ClassOf returns the class of a conversation from its flags.
+func ClassOf(isPrivate, isIM, isMpIM bool) string {
+	switch {
+	case isIM:
+		return ClassIM
+	case isMpIM:
+		return ClassMpIM
+	case isPrivate:
+		return ClassPrivate
+	default:
+		return ClassPublic
+	}
+}
+
+// Policy holds the allow and deny lists. A nil Policy allows everything.
+type Policy struct {
+	allow []entry
+	deny  []entry
+}
+
+type entry struct {
+	class   string
+	pattern string
+}
+
+// New parses comma separated allow and deny lists. Entries are channel IDs,
+// #channel or @user names, glob patterns over either such as #hr-* or
+// @mpdm-*, or one of the classes public, private, im and mpim. A non-empty
+// allow list hides everything it does not match, deny entries always win.
+func New(allow, deny string) (*Policy, error) {
+	a, err := parseEntries(allow)
+	if err != nil {
+		return nil, err
+	}
+	d, err := parseEntries(deny)
+	if err != nil {
+		return nil, err
+	}
+	if len(a) == 0 && len(d) == 0 {
+		return nil, nil
+	}
+	return &Policy{allow: a, deny: d}, nil
+}
+
+func parseEntries(s string) ([]entry, error) {
+	var res []entry
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		switch strings.ToLower(item) {
+		case ClassPublic, ClassPrivate, ClassIM, ClassMpIM:
+			res = append(res, entry{class: strings.ToLower(item)})
+			continue
+		}
+		if _, err := path.Match(item, ""); err != nil {
+			return nil, fmt.Errorf("invalid channel pattern %q: %w", item, err)
+		}
+		res = append(res, entry{pattern: item})
+	}
+	return res, nil
+}
+
+// Enabled reports whether any conversation is hidden.
+func (p *Policy) Enabled() bool {
+	return p != nil
+}
+
+// Allowed reports whether the conversation may be read.
+func (p *Policy) Allowed(c Channel) bool {
+	if p == nil {
+		return true
+	}
+	for _, e := range p.deny {
+		if e.matches(c) {
+			return false
+		}
+	}
+	if len(p.allow) == 0 {
+		return true
+	}
+	for _, e := range p.allow {
+		if e.matches(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// UserAllowed reports whether a user may be listed, only deny entries that
+// name the user, e.g. U1234567890 or @alice, hide users.
+func (p *Policy) UserAllowed(userID, userName string) bool {
+	if p == nil {
+		return true
+	}
+	for _, e := range p.deny {
+		if e.pattern != "" && (matchPattern(e.pattern, userID) || matchPattern(e.pattern, "@"+userName)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Excluded reports whether the conversation is hidden by a deny entry that
+// names it, as opposed to its class. Search queries exclude such channels
+// up front, class based rules would exclude too many.
+func (p *Policy) Excluded(c Channel) bool {
+	if p == nil {
+		return false
+	}
+	for _, e := range p.deny {
+		if e.pattern != "" && e.matches(c) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e entry) matches(c Channel) bool {
+	if e.class != "" {
+		return e.class == c.Class
+	}
+	return matchPattern(e.pattern, c.ID) || (c.Name != "" && matchPattern(e.pattern, c.Name))
+}
+
+func matchPattern(pattern, s string) bool {
+	ok, _ := path.Match(pattern, s)
+	return ok
+}
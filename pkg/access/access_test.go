@@ -0,0 +1,65 @@
+package access
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	general = Channel{ID: "C1", Name: "#general", Class: ClassPublic}
+	hr      = Channel{ID: "C2", Name: "#hr-payroll", Class: ClassPublic}
+	legal   = Channel{ID: "G3", Name: "#legal", Class: ClassPrivate}
+	dm      = Channel{ID: "D4", Name: "@alice", Class: ClassIM}
+	group   = Channel{ID: "G5", Name: "@mpdm-alice--bob-1", Class: ClassMpIM}
+)
+
+func TestUnitPolicyDeny(t *testing.T) {
+	p, err := New("", "#hr-*, G3, im")
+	require.NoError(t, err)
+	require.True(t, p.Enabled())
+
+	assert.True(t, p.Allowed(general))
+	assert.False(t, p.Allowed(hr))
+	assert.False(t, p.Allowed(legal))
+	assert.False(t, p.Allowed(dm))
+	assert.True(t, p.Allowed(group))
+
+	assert.True(t, p.Excluded(hr))
+	assert.True(t, p.Excluded(legal))
+	assert.False(t, p.Excluded(dm), "class rules are not excluded from search queries")
+}
+
+func TestUnitPolicyAllow(t *testing.T) {
+	p, err := New("public, @mpdm-*", "#hr-*")
+	require.NoError(t, err)
+
+	assert.True(t, p.Allowed(general))
+	assert.False(t, p.Allowed(hr), "deny wins over allow")
+	assert.False(t, p.Allowed(legal))
+	assert.False(t, p.Allowed(dm))
+	assert.True(t, p.Allowed(group))
+	assert.False(t, p.Allowed(Channel{ID: "C9", Class: ClassPrivate}))
+}
+
+func TestUnitPolicyUsers(t *testing.T) {
+	p, err := New("public", "@ceo, U42, private")
+	require.NoError(t, err)
+
+	assert.True(t, p.UserAllowed("U1", "alice"))
+	assert.False(t, p.UserAllowed("U2", "ceo"))
+	assert.False(t, p.UserAllowed("U42", "bob"))
+}
+
+func TestUnitPolicyConfig(t *testing.T) {
+	p, err := New(" , ", "")
+	require.NoError(t, err)
+	assert.Nil(t, p)
+	assert.False(t, p.Enabled())
+	assert.True(t, p.Allowed(hr))
+	assert.True(t, p.UserAllowed("U1", "alice"))
+
+	_, err = New("", "#hr-[")
+	assert.Error(t, err)
+}
@@ -0,0 +1,279 @@
+// Package audit records tool calls and Slack writes. Every record carries the
+// hash of the previous one, so removing or editing a record breaks the chain
+// and shows up in Verify.
+package audit
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	KindToolCall   = "tool_call"
+	KindSlackWrite = "slack_write"
+	// KindRecovery notes a repair of the log, such as a record cut short by
+	// a crash.
+	KindRecovery = "recovery"
+
+	StatusOK    = "ok"
+	StatusError = "error"
+)
+
+// Caller identifies who made a tool call.
+type Caller struct {
+	// APIKey is a fingerprint of the API key, never the key itself.
+	APIKey  string `json:"api_key,omitempty"`
+	Client  string `json:"client,omitempty"`
+	Session string `json:"session,omitempty"`
+}
+
+// Record is one line of the audit log.
+type Record struct {
+	Seq         int64     `json:"seq"`
+	Time        time.Time `json:"time"`
+	Kind        string    `json:"kind"`
+	Caller      Caller    `json:"caller"`
+	Tool        string    `json:"tool"`
+	Channel     string    `json:"channel,omitempty"`
+	Status      string    `json:"status"`
+	Error       string    `json:"error,omitempty"`
+	DurationMs  int64     `json:"duration_ms,omitempty"`
+	Method      string    `json:"method,omitempty"`
+	MessageTs   string    `json:"message_ts,omitempty"`
+	ContentHash string    `json:"content_hash,omitempty"`
+	PrevHash    string    `json:"prev_hash"`
+	Hash        string    `json:"hash"`
+}
+
+// Sink stores records. Write is called in order, with the chain fields set.
+type Sink interface {
+	Write(rec Record) error
+	Close() error
+}
+
+// Head is implemented by sinks that can tell the last record they stored,
+// so the chain continues across restarts.
+type Head interface {
+	Last() (seq int64, hash string, err error)
+}
+
+// Recoverer is implemented by sinks that repair what an interrupted write
+// left behind when they are opened.
+type Recoverer interface {
+	// Recovered describes the repair, empty when there was none.
+	Recovered() string
+}
+
+// Auditor chains records and hands them to the sinks. A nil Auditor records
+// nothing.
+type Auditor struct {
+	mu     sync.Mutex
+	seq    int64
+	last   string
+	sinks  []Sink
+	closed bool
+	logger *zap.Logger
+
+	// primary is the sink the chain is resumed from, a record it fails to
+	// store is not chained
+	primary Sink
+}
+
+// New creates an auditor writing to sinks, the chain is resumed from the
+// first sink that knows its head. Repairs of the sinks are recorded.
+func New(logger *zap.Logger, sinks ...Sink) (*Auditor, error) {
+	a := &Auditor{sinks: sinks, logger: logger}
+	for _, s := range sinks {
+		if h, ok := s.(Head); ok {
+			seq, hash, err := h.Last()
+			if err != nil {
+				return nil, err
+			}
+			a.seq, a.last, a.primary = seq, hash, s
+			break
+		}
+	}
+	for _, s := range sinks {
+		if r, ok := s.(Recoverer); ok && r.Recovered() != "" {
+			logger.Warn("Recovered the audit log", zap.String("recovery", r.Recovered()))
+			a.Log(Record{Kind: KindRecovery, Status: StatusError, Error: r.Recovered()})
+		}
+	}
+	return a, nil
+}
+
+// Log chains the record and writes it to every sink. Sink errors are logged,
+// a broken sink must not fail the tool call it records. A record the primary
+// sink fails to store is dropped, so the chain never refers to it.
+func (a *Auditor) Log(rec Record) {
+	if a == nil {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed {
+		return
+	}
+
+	if rec.Time.IsZero() {
+		rec.Time = time.Now().UTC()
+	}
+	rec.Seq = a.seq + 1
+	rec.PrevHash = a.last
+	rec.Hash = hashRecord(rec)
+
+	if a.primary != nil {
+		if err := a.primary.Write(rec); err != nil {
+			a.logger.Error("Failed to write audit record, dropped it",
+				zap.Int64("seq", rec.Seq),
+				zap.String("tool", rec.Tool),
+				zap.Error(err),
+			)
+			return
+		}
+	}
+	a.seq, a.last = rec.Seq, rec.Hash
+
+	for _, s := range a.sinks {
+		if s == a.primary {
+			continue
+		}
+		if err := s.Write(rec); err != nil {
+			a.logger.Error("Failed to write audit record",
+				zap.Int64("seq", rec.Seq),
+				zap.String("tool", rec.Tool),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// Close closes the sinks, flushing what they buffer.
+func (a *Auditor) Close() error {
+	if a == nil {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed {
+		return nil
+	}
+	a.closed = true
+
+	var first error
+	for _, s := range a.sinks {
+		if err := s.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// ContentHash returns the hash recorded for the text of a message.
+func ContentHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func hashRecord(rec Record) string {
+	rec.Hash = ""
+	data, _ := json.Marshal(rec)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Verify checks the chain of the JSON lines in r, starting from prevHash,
+// empty for the first file. It returns the hash of the last record, to
+// verify the next file with.
+func Verify(r io.Reader, prevHash string) (string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return "", fmt.Errorf("line %d: %w", line, err)
+		}
+		if rec.PrevHash != prevHash {
+			return "", fmt.Errorf("line %d: record %d does not follow the previous record", line, rec.Seq)
+		}
+		if hashRecord(rec) != rec.Hash {
+			return "", fmt.Errorf("line %d: record %d was modified", line, rec.Seq)
+		}
+		prevHash = rec.Hash
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return prevHash, nil
+}
+
+type ctxKey struct{}
+
+type state struct {
+	auditor *Auditor
+	call    *Record
+}
+
+// NewContext returns a context writes are recorded from, for work done
+// outside of a tool call such as delivering queued messages.
+func NewContext(ctx context.Context, a *Auditor) context.Context {
+	return context.WithValue(ctx, ctxKey{}, &state{auditor: a})
+}
+
+// WithCall returns a context for a tool call, handlers fill the call record
+// in with SetChannel and writes inherit its caller.
+func WithCall(ctx context.Context, a *Auditor, call *Record) context.Context {
+	return context.WithValue(ctx, ctxKey{}, &state{auditor: a, call: call})
+}
+
+// SetChannel records the resolved channel of the current tool call.
+func SetChannel(ctx context.Context, channel string) {
+	if st, ok := ctx.Value(ctxKey{}).(*state); ok && st.call != nil {
+		st.call.Channel = channel
+	}
+}
+
+// Write records a Slack write, method is the Slack API method, e.g.
+// chat.postMessage.
+func Write(ctx context.Context, method, channel, ts, text string, err error) {
+	st, ok := ctx.Value(ctxKey{}).(*state)
+	if !ok || st.auditor == nil {
+		return
+	}
+
+	rec := Record{
+		Kind:      KindSlackWrite,
+		Tool:      "outbox",
+		Channel:   channel,
+		Status:    StatusOK,
+		Method:    method,
+		MessageTs: ts,
+	}
+	if text != "" {
+		rec.ContentHash = ContentHash(text)
+	}
+	if err != nil {
+		rec.Status = StatusError
+		rec.Error = err.Error()
+	}
+	if st.call != nil {
+		rec.Tool = st.call.Tool
+		rec.Caller = st.call.Caller
+		if st.call.Channel == "" {
+			st.call.Channel = channel
+		}
+	}
+	st.auditor.Log(rec)
+}
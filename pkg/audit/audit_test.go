@@ -0,0 +1,227 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestUnitAuditorChain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := NewFileSink(path, 0, 0)
+	require.NoError(t, err)
+	a, err := New(zap.NewNop(), sink)
+	require.NoError(t, err)
+
+	a.Log(Record{Kind: KindToolCall, Tool: "conversations_history", Channel: "C1", Status: StatusOK})
+	a.Log(Record{Kind: KindToolCall, Tool: "channels_list", Status: StatusOK})
+	require.NoError(t, a.Close())
+
+	// the chain resumes after a restart
+	sink, err = NewFileSink(path, 0, 0)
+	require.NoError(t, err)
+	a, err = New(zap.NewNop(), sink)
+	require.NoError(t, err)
+	a.Log(Record{Kind: KindToolCall, Tool: "emoji_list", Status: StatusOK})
+	require.NoError(t, a.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 3)
+	_, err = Verify(strings.NewReader(string(data)), "")
+	require.NoError(t, err)
+
+	var rec Record
+	require.NoError(t, json.Unmarshal([]byte(lines[2]), &rec))
+	assert.Equal(t, int64(3), rec.Seq)
+
+	tampered := strings.Replace(string(data), `"channel":"C1"`, `"channel":"C2"`, 1)
+	_, err = Verify(strings.NewReader(tampered), "")
+	assert.ErrorContains(t, err, "record 1 was modified")
+
+	removed := lines[0] + "\n" + lines[2] + "\n"
+	_, err = Verify(strings.NewReader(removed), "")
+	assert.ErrorContains(t, err, "does not follow")
+}
+
+func TestUnitAuditorRecovery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := NewFileSink(path, 0, 0)
+	require.NoError(t, err)
+	a, err := New(zap.NewNop(), sink)
+	require.NoError(t, err)
+	a.Log(Record{Kind: KindToolCall, Tool: "channels_list", Status: StatusOK})
+	require.NoError(t, a.Close())
+
+	// a crash in the middle of an append
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"seq":2,"time":"2025-`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	sink, err = NewFileSink(path, 0, 0)
+	require.NoError(t, err, "an incomplete record does not stop the server")
+	a, err = New(zap.NewNop(), sink)
+	require.NoError(t, err)
+	a.Log(Record{Kind: KindToolCall, Tool: "emoji_list", Status: StatusOK})
+	require.NoError(t, a.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	_, err = Verify(strings.NewReader(string(data)), "")
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 3)
+	var rec Record
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &rec))
+	assert.Equal(t, KindRecovery, rec.Kind)
+	assert.Equal(t, int64(2), rec.Seq)
+	assert.Contains(t, rec.Error, "dropped an incomplete record of 22 bytes")
+}
+
+// flakySink stores records in memory and fails the writes it is told to.
+type flakySink struct {
+	records []Record
+	fail    bool
+}
+
+func (s *flakySink) Write(rec Record) error {
+	if s.fail {
+		return errors.New("disk full")
+	}
+	s.records = append(s.records, rec)
+	return nil
+}
+
+func (s *flakySink) Close() error                 { return nil }
+func (s *flakySink) Last() (int64, string, error) { return 0, "", nil }
+
+func TestUnitAuditorPrimaryFailure(t *testing.T) {
+	primary, other := &flakySink{}, &flakySink{}
+	a, err := New(zap.NewNop(), primary, other)
+	require.NoError(t, err)
+
+	a.Log(Record{Kind: KindToolCall, Tool: "channels_list", Status: StatusOK})
+	primary.fail = true
+	a.Log(Record{Kind: KindToolCall, Tool: "emoji_list", Status: StatusOK})
+	primary.fail = false
+	a.Log(Record{Kind: KindToolCall, Tool: "users_list", Status: StatusOK})
+
+	require.Len(t, primary.records, 2)
+	assert.Equal(t, primary.records, other.records, "records the primary sink lost are not sent elsewhere")
+	assert.Equal(t, int64(2), primary.records[1].Seq)
+	assert.Equal(t, primary.records[0].Hash, primary.records[1].PrevHash, "the chain skips the lost record")
+}
+
+func TestUnitFileSinkRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := NewFileSink(path, 600, 2)
+	require.NoError(t, err)
+	a, err := New(zap.NewNop(), sink)
+	require.NoError(t, err)
+
+	for i := 0; i < 12; i++ {
+		a.Log(Record{Kind: KindToolCall, Tool: "conversations_history", Status: StatusOK})
+	}
+	require.NoError(t, a.Close())
+
+	assert.FileExists(t, path+".1")
+	assert.FileExists(t, path+".2")
+	assert.NoFileExists(t, path+".3")
+
+	// rotated files chain into each other, the oldest kept one starts
+	// after records that were dropped
+	data, err := os.ReadFile(path + ".2")
+	require.NoError(t, err)
+	var head Record
+	require.NoError(t, json.Unmarshal([]byte(strings.SplitN(string(data), "\n", 2)[0]), &head))
+	prev := head.PrevHash
+
+	for _, p := range []string{path + ".2", path + ".1", path} {
+		f, err := os.Open(p)
+		require.NoError(t, err)
+		prev, err = Verify(f, prev)
+		f.Close()
+		require.NoError(t, err, p)
+	}
+}
+
+func TestUnitFileSinkNoRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := NewFileSink(path, 600, 0)
+	require.NoError(t, err)
+	a, err := New(zap.NewNop(), sink)
+	require.NoError(t, err)
+
+	for i := 0; i < 12; i++ {
+		a.Log(Record{Kind: KindToolCall, Tool: "conversations_history", Status: StatusOK})
+	}
+	require.NoError(t, a.Close())
+
+	assert.NoFileExists(t, path+".1")
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 12, strings.Count(string(data), "\n"), "no record is dropped when no old file is kept")
+	_, err = Verify(strings.NewReader(string(data)), "")
+	assert.NoError(t, err)
+}
+
+func TestUnitWriteContext(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received []Record
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		var rec Record
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&rec))
+		mu.Lock()
+		received = append(received, rec)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	a, err := New(zap.NewNop(), NewWebhookSink(srv.URL, "secret", zap.NewNop()))
+	require.NoError(t, err)
+
+	call := &Record{Kind: KindToolCall, Tool: "conversations_add_message", Caller: Caller{Client: "test/1.0"}, Status: StatusOK}
+	ctx := WithCall(context.Background(), a, call)
+	Write(ctx, "chat.postMessage", "C1", "1700000000.000100", "hello", nil)
+	a.Log(*call)
+
+	Write(NewContext(context.Background(), a), "chat.postMessage", "C2", "", "queued", errors.New("channel_not_found"))
+	// no auditor, nothing is recorded
+	Write(context.Background(), "chat.postMessage", "C3", "", "dropped", nil)
+	require.NoError(t, a.Close())
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, received, 3)
+
+	assert.Equal(t, KindSlackWrite, received[0].Kind)
+	assert.Equal(t, "conversations_add_message", received[0].Tool)
+	assert.Equal(t, "test/1.0", received[0].Caller.Client)
+	assert.Equal(t, "1700000000.000100", received[0].MessageTs)
+	assert.Equal(t, ContentHash("hello"), received[0].ContentHash)
+
+	assert.Equal(t, KindToolCall, received[1].Kind)
+	assert.Equal(t, "C1", received[1].Channel)
+	assert.Equal(t, received[0].Hash, received[1].PrevHash)
+
+	assert.Equal(t, "outbox", received[2].Tool)
+	assert.Equal(t, StatusError, received[2].Status)
+	assert.Equal(t, "channel_not_found", received[2].Error)
+}
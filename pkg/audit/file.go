@@ -0,0 +1,203 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// tailSize is how much of the end of a file is read to find its last record.
+const tailSize = 64 * 1024
+
+// FileSink appends records as JSON lines. Once the file would grow past
+// maxBytes it is renamed to path.1, older files shift to path.2 and so on,
+// up to maxFiles old files. The file is never rotated when maxBytes or
+// maxFiles is 0, rotating would drop the whole log.
+type FileSink struct {
+	mu       sync.Mutex
+	path     string
+	maxBytes int64
+	maxFiles int
+	f        *os.File
+	size     int64
+	// recovered notes an incomplete record dropped when the file was opened
+	recovered string
+}
+
+// NewFileSink opens path for appending, creating it when missing. An
+// incomplete last line, left by a crash during a write, is dropped.
+func NewFileSink(path string, maxBytes int64, maxFiles int) (*FileSink, error) {
+	s := &FileSink{path: path, maxBytes: maxBytes, maxFiles: maxFiles}
+	dropped, err := cutIncompleteLine(path)
+	if err != nil {
+		return nil, err
+	}
+	if dropped > 0 {
+		s.recovered = fmt.Sprintf("dropped an incomplete record of %d bytes at the end of %s, left by an interrupted write", dropped, path)
+	}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileSink) open() error {
+	f, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to stat audit log: %w", err)
+	}
+	s.f, s.size = f, info.Size()
+	return nil
+}
+
+func (s *FileSink) Write(rec Record) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.maxBytes > 0 && s.maxFiles > 0 && s.size > 0 && s.size+int64(len(line)) > s.maxBytes {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+	if _, err := s.f.Write(line); err != nil {
+		// a partial line would break the next record
+		s.f.Truncate(s.size)
+		return err
+	}
+	s.size += int64(len(line))
+	return nil
+}
+
+func (s *FileSink) rotate() error {
+	if err := s.f.Close(); err != nil {
+		return err
+	}
+	os.Remove(s.backup(s.maxFiles))
+	for i := s.maxFiles - 1; i >= 1; i-- {
+		if err := os.Rename(s.backup(i), s.backup(i+1)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to rotate audit log: %w", err)
+		}
+	}
+	if err := os.Rename(s.path, s.backup(1)); err != nil {
+		return fmt.Errorf("failed to rotate audit log: %w", err)
+	}
+	return s.open()
+}
+
+func (s *FileSink) backup(i int) string {
+	return fmt.Sprintf("%s.%d", s.path, i)
+}
+
+// Last returns the last record of the current file, or of the newest old
+// file when the current one is empty.
+func (s *FileSink) Last() (int64, string, error) {
+	for _, path := range []string{s.path, s.backup(1)} {
+		rec, err := lastRecord(path)
+		if err != nil {
+			return 0, "", err
+		}
+		if rec != nil {
+			return rec.Seq, rec.Hash, nil
+		}
+	}
+	return 0, "", nil
+}
+
+// Recovered describes the incomplete record dropped on open, if any.
+func (s *FileSink) Recovered() string {
+	return s.recovered
+}
+
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.f.Close()
+}
+
+func lastRecord(path string) (*Record, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	offset := info.Size() - tailSize
+	if offset < 0 {
+		offset = 0
+	}
+	data, err := io.ReadAll(io.NewSectionReader(f, offset, info.Size()-offset))
+	if err != nil {
+		return nil, err
+	}
+
+	data = bytes.TrimRight(data, "\n")
+	if len(data) == 0 {
+		return nil, nil
+	}
+	if i := bytes.LastIndexByte(data, '\n'); i >= 0 {
+		data = data[i+1:]
+	}
+	var rec Record
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("failed to read the last audit record of %s: %w", path, err)
+	}
+	return &rec, nil
+}
+
+// cutIncompleteLine truncates path after its last newline and returns the
+// number of bytes dropped.
+func cutIncompleteLine(path string) (int64, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat audit log: %w", err)
+	}
+	size := info.Size()
+	offset := max(0, size-tailSize)
+	data, err := io.ReadAll(io.NewSectionReader(f, offset, size-offset))
+	if err != nil {
+		return 0, err
+	}
+	if len(data) == 0 || data[len(data)-1] == '\n' {
+		return 0, nil
+	}
+
+	i := bytes.LastIndexByte(data, '\n')
+	if i < 0 && offset > 0 {
+		return 0, fmt.Errorf("failed to find the last audit record of %s", path)
+	}
+	keep := offset + int64(i) + 1
+	if err := f.Truncate(keep); err != nil {
+		return 0, fmt.Errorf("failed to drop the incomplete audit record of %s: %w", path, err)
+	}
+	return size - keep, nil
+}
@@ -0,0 +1,103 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	webhookQueueSize = 1000
+	webhookTimeout   = 10 * time.Second
+)
+
+// WebhookSink posts every record as JSON to a URL. Records are sent in the
+// background, so a slow endpoint does not hold tool calls up; when the
+// queue is full records are dropped with a warning, the file sink keeps
+// them.
+type WebhookSink struct {
+	url    string
+	token  string
+	client *http.Client
+	queue  chan Record
+	done   chan struct{}
+	once   sync.Once
+	logger *zap.Logger
+}
+
+// NewWebhookSink starts a sink posting to url, token is sent as a bearer
+// token when set.
+func NewWebhookSink(url, token string, logger *zap.Logger) *WebhookSink {
+	s := &WebhookSink{
+		url:    url,
+		token:  token,
+		client: &http.Client{Timeout: webhookTimeout},
+		queue:  make(chan Record, webhookQueueSize),
+		done:   make(chan struct{}),
+		logger: logger,
+	}
+	go s.run()
+	return s
+}
+
+func (s *WebhookSink) Write(rec Record) error {
+	select {
+	case s.queue <- rec:
+		return nil
+	default:
+		return fmt.Errorf("audit webhook queue is full, record %d dropped", rec.Seq)
+	}
+}
+
+// Close sends what is queued and stops the sink.
+func (s *WebhookSink) Close() error {
+	s.once.Do(func() { close(s.queue) })
+	<-s.done
+	return nil
+}
+
+func (s *WebhookSink) run() {
+	defer close(s.done)
+	for rec := range s.queue {
+		if err := s.post(rec); err != nil {
+			s.logger.Warn("Failed to send audit record to webhook",
+				zap.Int64("seq", rec.Seq),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+func (s *WebhookSink) post(rec Record) error {
+	body, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+	return nil
+}
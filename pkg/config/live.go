@@ -0,0 +1,141 @@
+package config
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// Live holds the configuration in effect, it is swapped as a whole on
+// reload so one Get never returns half of a change. The policies built from
+// it are swapped separately, see MCPServer.Reload.
+type Live struct {
+	current atomic.Pointer[Config]
+}
+
+// NewLive returns a Live holding c.
+func NewLive(c *Config) *Live {
+	l := &Live{}
+	l.current.Store(c)
+	return l
+}
+
+// Get returns the configuration in effect, it must not be modified.
+func (l *Live) Get() *Config {
+	return l.current.Load()
+}
+
+// Set replaces the configuration in effect.
+func (l *Live) Set(c *Config) {
+	l.current.Store(c)
+}
+
+// Change is a setting that differs between two configurations, secrets are
+// masked in Old and New.
+type Change struct {
+	Env      string
+	Old, New string
+	// Reloadable is false for settings that only apply after a restart.
+	Reloadable bool
+}
+
+// Diff lists the settings of next that differ from c.
+func (c *Config) Diff(next *Config) []Change {
+	nextFields := next.fields()
+	var res []Change
+	for i, f := range c.fields() {
+		n := nextFields[i]
+		if reflect.DeepEqual(f.value.Interface(), n.value.Interface()) {
+			continue
+		}
+		res = append(res, Change{Env: f.env, Old: f.display(), New: n.display(), Reloadable: f.reload})
+	}
+
+	// workspaces only change on restart
+	oldWS, newWS := workspaceFields(c), workspaceFields(next)
+	envs := make([]string, 0, len(oldWS)+len(newWS))
+	for env := range oldWS {
+		envs = append(envs, env)
+	}
+	for env := range newWS {
+		if _, ok := oldWS[env]; !ok {
+			envs = append(envs, env)
+		}
+	}
+	sort.Strings(envs)
+	for _, env := range envs {
+		o, n := oldWS[env], newWS[env]
+		if o.value.IsValid() && n.value.IsValid() && o.value.Interface() == n.value.Interface() {
+			continue
+		}
+		var change Change
+		change.Env = env
+		if o.value.IsValid() {
+			change.Old = o.display()
+		}
+		if n.value.IsValid() {
+			change.New = n.display()
+		}
+		if change.Old != "" || change.New != "" {
+			res = append(res, change)
+		}
+	}
+	return res
+}
+
+func workspaceFields(c *Config) map[string]field {
+	res := make(map[string]field)
+	for i := range c.Workspaces {
+		for _, f := range c.Workspaces[i].fields() {
+			res[f.env] = f
+		}
+	}
+	return res
+}
+
+// Reload returns a copy of c with the reloadable settings taken from next,
+// the other settings keep their current value until a restart.
+func (c *Config) Reload(next *Config) *Config {
+	res := *c
+	res.Warnings = nil
+	resFields, nextFields := res.fields(), next.fields()
+	for i, f := range resFields {
+		if f.reload {
+			f.value.Set(nextFields[i].value)
+		}
+	}
+	return &res
+}
+
+// Watch calls onChange when the file at path is modified, checking every
+// interval until ctx is done. A file that cannot be read is skipped until it
+// comes back, e.g. while an editor replaces it.
+func Watch(ctx context.Context, path string, interval time.Duration, onChange func()) {
+	stamp := func() (time.Time, int64, bool) {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, 0, false
+		}
+		return info.ModTime(), info.Size(), true
+	}
+
+	lastMod, lastSize, _ := stamp()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		mod, size, ok := stamp()
+		if !ok || (mod.Equal(lastMod) && size == lastSize) {
+			continue
+		}
+		lastMod, lastSize = mod, size
+		onChange()
+	}
+}
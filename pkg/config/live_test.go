@@ -0,0 +1,65 @@
+package config
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitDiffAndReload(t *testing.T) {
+	current := Default()
+	current.XOXPToken = "xoxp-old"
+	current.AddMessageTool = "C1"
+
+	next := Default()
+	next.XOXPToken = "xoxp-new"
+	next.AddMessageTool = "C1,C2"
+	next.LogLevel = "debug"
+	next.Port = 9000
+
+	changes := current.Diff(next)
+	require.Len(t, changes, 4)
+	byEnv := make(map[string]Change)
+	for _, c := range changes {
+		byEnv[c.Env] = c
+	}
+	assert.Equal(t, Change{Env: "SLACK_MCP_ADD_MESSAGE_TOOL", Old: "C1", New: "C1,C2", Reloadable: true}, byEnv["SLACK_MCP_ADD_MESSAGE_TOOL"])
+	assert.Equal(t, Change{Env: "SLACK_MCP_XOXP_TOKEN", Old: mask, New: mask}, byEnv["SLACK_MCP_XOXP_TOKEN"])
+	assert.False(t, byEnv["SLACK_MCP_PORT"].Reloadable)
+	assert.True(t, byEnv["SLACK_MCP_LOG_LEVEL"].Reloadable)
+
+	reloaded := current.Reload(next)
+	assert.Equal(t, "C1,C2", reloaded.AddMessageTool)
+	assert.Equal(t, "debug", reloaded.LogLevel)
+	assert.Equal(t, 13080, reloaded.Port)
+	assert.Equal(t, "xoxp-old", reloaded.XOXPToken)
+	assert.Equal(t, "C1", current.AddMessageTool, "the current configuration is not modified")
+
+	assert.Empty(t, current.Diff(current))
+}
+
+func TestUnitWatch(t *testing.T) {
+	path := writeFile(t, "config.yaml", "port: 9000\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changed := make(chan struct{}, 1)
+	go Watch(ctx, path, 10*time.Millisecond, func() { changed <- struct{}{} })
+
+	select {
+	case <-changed:
+		t.Fatal("unexpected change before the file was written")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	require.NoError(t, os.WriteFile(path, []byte("port: 9001\nhost: 0.0.0.0\n"), 0o600))
+	select {
+	case <-changed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("change not detected")
+	}
+}
@@ -0,0 +1,220 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const workspaceEnvPrefix = envPrefix + "WORKSPACE_"
+
+var workspaceName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// Workspace is a named set of Slack tokens and cache files. A server with
+// workspaces serves each of them with its own client and caches, the file
+// table workspaces.<name> or the SLACK_MCP_WORKSPACE_<NAME>_<KEY> variables
+// set them, e.g. SLACK_MCP_WORKSPACE_ACME_XOXP_TOKEN.
+type Workspace struct {
+	Name          string `key:"-"`
+	XOXPToken     string `key:"xoxp_token" secret:"true"`
+	XOXCToken     string `key:"xoxc_token" secret:"true"`
+	XOXDToken     string `key:"xoxd_token" secret:"true"`
+	UsersCache    string `key:"users_cache"`
+	ChannelsCache string `key:"channels_cache"`
+	EmojiCache    string `key:"emoji_cache"`
+}
+
+func (w *Workspace) fields() []field {
+	v := reflect.ValueOf(w).Elem()
+	t := v.Type()
+	var res []field
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key := f.Tag.Get("key")
+		if key == "-" {
+			continue
+		}
+		res = append(res, field{
+			env:    workspaceEnv(w.Name, key),
+			key:    key,
+			secret: f.Tag.Get("secret") == "true",
+			value:  v.Field(i),
+		})
+	}
+	return res
+}
+
+// workspaceEnv returns the environment variable of a workspace setting, dashes
+// in the name become underscores.
+func workspaceEnv(name, key string) string {
+	return workspaceEnvPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_")+"_"+key)
+}
+
+func workspaceKeys() []string {
+	var keys []string
+	for _, f := range (&Workspace{}).fields() {
+		keys = append(keys, f.key)
+	}
+	return keys
+}
+
+// workspaceLoader collects the workspaces of the file and of the environment.
+type workspaceLoader map[string]*Workspace
+
+func (l workspaceLoader) get(name string) *Workspace {
+	w, ok := l[name]
+	if !ok {
+		w = &Workspace{Name: name}
+		l[name] = w
+	}
+	return w
+}
+
+func (l workspaceLoader) set(name, key, raw string) error {
+	for _, f := range l.get(name).fields() {
+		if f.key == key {
+			return set(f.value, raw)
+		}
+	}
+	return fmt.Errorf("unknown key %q", key)
+}
+
+// loadFile reads the workspaces table of a config file.
+func (l workspaceLoader) loadFile(path string, table any) []error {
+	byName, ok := table.(map[string]any)
+	if !ok {
+		return []error{fmt.Errorf("%s: workspaces must be a table of workspaces by name", path)}
+	}
+	var errs []error
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		values, ok := byName[name].(map[string]any)
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: workspaces.%s must be a table", path, name))
+			continue
+		}
+		l.get(name)
+		for k, v := range values {
+			raw, err := fileValue(v)
+			if err == nil {
+				err = l.set(name, strings.ToLower(k), raw)
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: workspaces.%s.%s: %w", path, name, k, err))
+			}
+		}
+	}
+	return errs
+}
+
+// loadEnv reads the SLACK_MCP_WORKSPACE_<NAME>_<KEY> variables, underscores
+// in the name become dashes.
+func (l workspaceLoader) loadEnv() []error {
+	var errs []error
+	keys := workspaceKeys()
+	for _, kv := range os.Environ() {
+		env, raw, _ := strings.Cut(kv, "=")
+		rest, ok := strings.CutPrefix(env, workspaceEnvPrefix)
+		if !ok || strings.TrimSpace(raw) == "" {
+			continue
+		}
+		found := false
+		for _, key := range keys {
+			name, ok := strings.CutSuffix(rest, "_"+strings.ToUpper(key))
+			if !ok || name == "" {
+				continue
+			}
+			found = true
+			name = strings.ReplaceAll(strings.ToLower(name), "_", "-")
+			if err := l.set(name, key, raw); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", env, err))
+			}
+			break
+		}
+		if !found {
+			errs = append(errs, fmt.Errorf("%s: unknown workspace setting, expected one of %s", env, strings.Join(keys, ", ")))
+		}
+	}
+	return errs
+}
+
+// workspaces returns the workspaces sorted by name.
+func (l workspaceLoader) workspaces() []Workspace {
+	if len(l) == 0 {
+		return nil
+	}
+	res := make([]Workspace, 0, len(l))
+	for _, w := range l {
+		res = append(res, *w)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+// WorkspaceConfigs returns a configuration per workspace, which has the
+// tokens and cache files of that workspace. Without workspaces it returns c
+// alone.
+func (c *Config) WorkspaceConfigs() []*Config {
+	if len(c.Workspaces) == 0 {
+		return []*Config{c}
+	}
+	res := make([]*Config, 0, len(c.Workspaces))
+	for _, w := range c.Workspaces {
+		wc := *c
+		wc.Workspaces = nil
+		wc.WorkspaceName = w.Name
+		wc.XOXPToken = w.XOXPToken
+		wc.XOXCToken = w.XOXCToken
+		wc.XOXDToken = w.XOXDToken
+		wc.UsersCache = w.UsersCache
+		wc.ChannelsCache = w.ChannelsCache
+		wc.EmojiCache = w.EmojiCache
+		res = append(res, &wc)
+	}
+	return res
+}
+
+func (c *Config) validateWorkspaces(add func(format string, args ...any)) {
+	if len(c.Workspaces) == 0 {
+		if c.XOXPToken == "" && (c.XOXCToken == "" || c.XOXDToken == "") {
+			add("authentication required: either SLACK_MCP_XOXP_TOKEN (User OAuth) or both SLACK_MCP_XOXC_TOKEN and SLACK_MCP_XOXD_TOKEN (session-based) must be provided")
+		}
+		if c.DefaultWorkspace != "" {
+			add("SLACK_MCP_DEFAULT_WORKSPACE is set but no workspace is configured")
+		}
+		return
+	}
+
+	for _, f := range c.fields() {
+		switch f.env {
+		case "SLACK_MCP_XOXP_TOKEN", "SLACK_MCP_XOXC_TOKEN", "SLACK_MCP_XOXD_TOKEN",
+			"SLACK_MCP_USERS_CACHE", "SLACK_MCP_CHANNELS_CACHE", "SLACK_MCP_EMOJI_CACHE":
+			if !f.value.IsZero() {
+				add("%s cannot be used with workspaces, set it for each workspace instead", f.env)
+			}
+		}
+	}
+
+	found := c.DefaultWorkspace == ""
+	for _, w := range c.Workspaces {
+		if !workspaceName.MatchString(w.Name) {
+			add("workspace name %q must be lower case letters, digits and dashes", w.Name)
+		}
+		if w.XOXPToken == "" && (w.XOXCToken == "" || w.XOXDToken == "") {
+			add("workspace %s: authentication required: either xoxp_token or both xoxc_token and xoxd_token must be provided", w.Name)
+		}
+		if w.Name == c.DefaultWorkspace {
+			found = true
+		}
+	}
+	if !found {
+		add("SLACK_MCP_DEFAULT_WORKSPACE: unknown workspace %q", c.DefaultWorkspace)
+	}
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitLoadWorkspaces(t *testing.T) {
+	path := writeFile(t, "config.yaml", `default_workspace: acme
+workspaces:
+  acme:
+    xoxp_token: xoxp-file
+    users_cache: /tmp/acme_users.json
+  beta-corp:
+    xoxc_token: xoxc-beta
+    xoxd_token: xoxd-beta
+`)
+	t.Setenv("SLACK_MCP_WORKSPACE_ACME_XOXP_TOKEN", "xoxp-env")
+	t.Setenv("SLACK_MCP_WORKSPACE_GAMMA_XOXP_TOKEN", "xoxp-gamma")
+
+	cfg, err := Load(path, nil)
+	require.NoError(t, err)
+	require.NoError(t, cfg.Validate())
+
+	require.Len(t, cfg.Workspaces, 3)
+	assert.Equal(t, Workspace{Name: "acme", XOXPToken: "xoxp-env", UsersCache: "/tmp/acme_users.json"}, cfg.Workspaces[0])
+	assert.Equal(t, "beta-corp", cfg.Workspaces[1].Name)
+	assert.Equal(t, "gamma", cfg.Workspaces[2].Name)
+
+	configs := cfg.WorkspaceConfigs()
+	require.Len(t, configs, 3)
+	assert.Equal(t, "acme", configs[0].WorkspaceName)
+	assert.Equal(t, "xoxp-env", configs[0].XOXPToken)
+	assert.Equal(t, "/tmp/acme_users.json", configs[0].UsersCache)
+	assert.Equal(t, "xoxc-beta", configs[1].XOXCToken)
+	assert.Empty(t, configs[1].UsersCache)
+	assert.Nil(t, configs[1].Workspaces)
+	assert.Equal(t, 13080, configs[2].Port, "the other settings are shared")
+
+	values := cfg.Values()
+	assert.Equal(t, mask, values["SLACK_MCP_WORKSPACE_BETA_CORP_XOXC_TOKEN"])
+	var buf bytes.Buffer
+	require.NoError(t, cfg.Print(&buf))
+	assert.Contains(t, buf.String(), "workspaces:\n    acme:\n        users_cache: /tmp/acme_users.json\n        xoxp_token: '********'\n")
+	assert.NotContains(t, buf.String(), "xoxp-env")
+
+	t.Setenv("SLACK_MCP_WORKSPACE_ACME_TOKEN", "xoxp-1")
+	_, err = Load(path, nil)
+	assert.ErrorContains(t, err, "SLACK_MCP_WORKSPACE_ACME_TOKEN: unknown workspace setting")
+}
+
+func TestUnitValidateWorkspaces(t *testing.T) {
+	cfg := Default()
+	cfg.XOXPToken = "xoxp-1"
+	cfg.Workspaces = []Workspace{{Name: "Acme", XOXPToken: "xoxp-2"}, {Name: "beta", XOXCToken: "xoxc-1"}}
+	cfg.DefaultWorkspace = "gamma"
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	for _, want := range []string{
+		"SLACK_MCP_XOXP_TOKEN cannot be used with workspaces",
+		`workspace name "Acme"`,
+		"workspace beta: authentication required",
+		`unknown workspace "gamma"`,
+	} {
+		assert.Contains(t, err.Error(), want)
+	}
+
+	single := Default()
+	single.XOXPToken = "xoxp-1"
+	single.DefaultWorkspace = "acme"
+	assert.ErrorContains(t, single.Validate(), "no workspace is configured")
+}
+
+func TestUnitDiffWorkspaces(t *testing.T) {
+	current := Default()
+	current.Workspaces = []Workspace{{Name: "acme", XOXPToken: "xoxp-1"}}
+	next := Default()
+	next.Workspaces = []Workspace{{Name: "acme", XOXPToken: "xoxp-1"}, {Name: "beta", XOXPToken: "xoxp-2"}}
+
+	assert.Equal(t, []Change{{Env: "SLACK_MCP_WORKSPACE_BETA_XOXP_TOKEN", New: mask}}, current.Diff(next))
+	assert.Empty(t, next.Diff(next))
+	assert.Len(t, current.Reload(next).Workspaces, 1, "workspaces are not reloadable")
+}
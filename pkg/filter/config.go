@@ -0,0 +1,107 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultMaxLength is Slack's limit for the text of a message.
+const DefaultMaxLength = 40000
+
+// Config configures the built-in rules.
+type Config struct {
+	// Actions sets the default action per rule, e.g.
+	// "secrets=deny,broadcast=warn,max_length=redact".
+	Actions string
+	// Overrides sets actions per channel ID or name, channels separated by
+	// semicolons, e.g. "#alerts:broadcast=off;C1234567890:max_length=deny".
+	Overrides    string
+	MaxLength    int
+	DenyPatterns []string
+}
+
+// New builds the chain of built-in rules. Secrets are denied unless
+// configured otherwise, deny list patterns are denied once there are any,
+// the other rules are off by default.
+func New(cfg Config) (*Chain, error) {
+	actions := map[string]Action{
+		RuleSecrets:   ActionDeny,
+		RuleDenyList:  ActionOff,
+		RuleBroadcast: ActionOff,
+		RuleMaxLength: ActionOff,
+	}
+	if len(cfg.DenyPatterns) > 0 {
+		actions[RuleDenyList] = ActionDeny
+	}
+
+	defaults, err := parseActions(cfg.Actions)
+	if err != nil {
+		return nil, err
+	}
+	for rule, a := range defaults {
+		actions[rule] = a
+	}
+
+	maxLength := cfg.MaxLength
+	if maxLength <= 0 {
+		maxLength = DefaultMaxLength
+	}
+	denyList, err := DenyListRule(cfg.DenyPatterns)
+	if err != nil {
+		return nil, err
+	}
+
+	// max length goes last, so it measures the text after redactions
+	c := NewChain().
+		Add(SecretsRule(), actions[RuleSecrets]).
+		Add(denyList, actions[RuleDenyList]).
+		Add(BroadcastRule(), actions[RuleBroadcast]).
+		Add(MaxLengthRule(maxLength), actions[RuleMaxLength])
+
+	for _, entry := range strings.Split(cfg.Overrides, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		channel, list, ok := strings.Cut(entry, ":")
+		if !ok || strings.TrimSpace(channel) == "" {
+			return nil, fmt.Errorf("invalid content filter override %q, expected channel:rule=action", entry)
+		}
+		overrides, err := parseActions(list)
+		if err != nil {
+			return nil, err
+		}
+		for rule, a := range overrides {
+			c.Override(strings.TrimSpace(channel), rule, a)
+		}
+	}
+
+	return c, nil
+}
+
+func parseActions(s string) (map[string]Action, error) {
+	res := make(map[string]Action)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		rule, action, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid content filter setting %q, expected rule=action", item)
+		}
+		rule = strings.TrimSpace(rule)
+		switch rule {
+		case RuleSecrets, RuleDenyList, RuleBroadcast, RuleMaxLength:
+		default:
+			return nil, fmt.Errorf("unknown content filter rule %q, expected one of %s, %s, %s or %s",
+				rule, RuleSecrets, RuleDenyList, RuleBroadcast, RuleMaxLength)
+		}
+		a, err := parseAction(action)
+		if err != nil {
+			return nil, err
+		}
+		res[rule] = a
+	}
+	return res, nil
+}
@@ -0,0 +1,176 @@
+// Package filter checks outgoing messages against a chain of content rules.
+// Each rule denies, redacts or warns about what it matches, globally or per
+// channel.
+package filter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type Action string
+
+const (
+	ActionOff    Action = "off"
+	ActionWarn   Action = "warn"
+	ActionRedact Action = "redact"
+	ActionDeny   Action = "deny"
+)
+
+const redacted = "[REDACTED]"
+
+func parseAction(s string) (Action, error) {
+	switch a := Action(strings.ToLower(strings.TrimSpace(s))); a {
+	case ActionOff, ActionWarn, ActionRedact, ActionDeny:
+		return a, nil
+	default:
+		return "", fmt.Errorf("invalid filter action %q, expected off, warn, redact or deny", s)
+	}
+}
+
+// Match is a part of a message a rule objects to.
+type Match struct {
+	Start, End int
+	// Reason names what was found without repeating it, e.g. "AWS access key".
+	Reason string
+}
+
+// Rule finds content that should not be posted.
+type Rule interface {
+	Name() string
+	Find(text string) []Match
+	// Redact rewrites the matches, it is given the matches of Find.
+	Redact(text string, matches []Match) string
+}
+
+// DeniedError is returned when a rule with the deny action matches.
+type DeniedError struct {
+	Rule    string
+	Reasons []string
+}
+
+func (e *DeniedError) Error() string {
+	return fmt.Sprintf("message blocked by content filter %q: %s", e.Rule, strings.Join(e.Reasons, ", "))
+}
+
+// Result is the message after the chain ran.
+type Result struct {
+	Text     string
+	Warnings []string
+}
+
+type chainRule struct {
+	rule   Rule
+	action Action
+}
+
+// Chain runs the rules in order, with per channel overrides of their actions.
+type Chain struct {
+	rules     []chainRule
+	overrides map[string]map[string]Action
+}
+
+func NewChain() *Chain {
+	return &Chain{overrides: make(map[string]map[string]Action)}
+}
+
+// Add appends a rule with its default action.
+func (c *Chain) Add(rule Rule, action Action) *Chain {
+	c.rules = append(c.rules, chainRule{rule: rule, action: action})
+	return c
+}
+
+// Override sets the action of a rule for a channel, by ID or name.
+func (c *Chain) Override(channel, rule string, action Action) {
+	if c.overrides[channel] == nil {
+		c.overrides[channel] = make(map[string]Action)
+	}
+	c.overrides[channel][rule] = action
+}
+
+// Enabled reports whether any rule can act on a message.
+func (c *Chain) Enabled() bool {
+	for _, r := range c.rules {
+		if r.action != ActionOff {
+			return true
+		}
+	}
+	for _, o := range c.overrides {
+		for _, a := range o {
+			if a != ActionOff {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Apply runs the chain on a message to a channel, known by its ID and name.
+func (c *Chain) Apply(channelID, channelName, text string) (Result, error) {
+	res := Result{Text: text}
+	for _, r := range c.rules {
+		action := c.action(channelID, channelName, r)
+		if action == ActionOff {
+			continue
+		}
+
+		matches := r.rule.Find(res.Text)
+		if len(matches) == 0 {
+			continue
+		}
+
+		switch action {
+		case ActionDeny:
+			return Result{}, &DeniedError{Rule: r.rule.Name(), Reasons: reasons(matches)}
+		case ActionRedact:
+			res.Text = r.rule.Redact(res.Text, matches)
+			res.Warnings = append(res.Warnings, fmt.Sprintf("content filter %q redacted %s", r.rule.Name(), strings.Join(reasons(matches), ", ")))
+		case ActionWarn:
+			res.Warnings = append(res.Warnings, fmt.Sprintf("content filter %q found %s", r.rule.Name(), strings.Join(reasons(matches), ", ")))
+		}
+	}
+	return res, nil
+}
+
+func (c *Chain) action(channelID, channelName string, r chainRule) Action {
+	for _, ch := range []string{channelID, channelName} {
+		if a, ok := c.overrides[ch][r.rule.Name()]; ok && ch != "" {
+			return a
+		}
+	}
+	return r.action
+}
+
+func reasons(matches []Match) []string {
+	seen := make(map[string]struct{}, len(matches))
+	var res []string
+	for _, m := range matches {
+		if _, ok := seen[m.Reason]; !ok {
+			seen[m.Reason] = struct{}{}
+			res = append(res, m.Reason)
+		}
+	}
+	return res
+}
+
+// replaceMatches swaps every match for repl, overlapping matches are merged.
+func replaceMatches(text string, matches []Match, repl func(Match) string) string {
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Start < matches[j].Start })
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		if m.End <= last {
+			continue
+		}
+		if m.Start < last {
+			m.Start = last
+		}
+		b.WriteString(text[last:m.Start])
+		b.WriteString(repl(m))
+		last = m.End
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
@@ -0,0 +1,143 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+)
+
+const (
+	RuleSecrets   = "secrets"
+	RuleBroadcast = "broadcast"
+	RuleMaxLength = "max_length"
+	RuleDenyList  = "deny_list"
+)
+
+var broadcastSpecialRe = regexp.MustCompile(`^<!(\w+).*>$`)
+
+type pattern struct {
+	re     *regexp.Regexp
+	reason string
+}
+
+// patternRule matches a list of regular expressions and redacts every match.
+type patternRule struct {
+	name     string
+	patterns []pattern
+	redact   func(match string) string
+}
+
+func (r *patternRule) Name() string {
+	return r.name
+}
+
+func (r *patternRule) Find(text string) []Match {
+	var matches []Match
+	for _, p := range r.patterns {
+		for _, loc := range p.re.FindAllStringSubmatchIndex(text, -1) {
+			start, end := loc[0], loc[1]
+			// a "m" group narrows the match down, e.g. past a leading boundary
+			if i := p.re.SubexpIndex("m"); i > 0 && loc[2*i] >= 0 {
+				start, end = loc[2*i], loc[2*i+1]
+			}
+			matches = append(matches, Match{Start: start, End: end, Reason: p.reason})
+		}
+	}
+	return matches
+}
+
+func (r *patternRule) Redact(text string, matches []Match) string {
+	return replaceMatches(text, matches, func(m Match) string {
+		if r.redact != nil {
+			return r.redact(text[m.Start:m.End])
+		}
+		return redacted
+	})
+}
+
+// SecretsRule finds credentials such as Slack and GitHub tokens, AWS keys and
+// private keys.
+func SecretsRule() Rule {
+	return &patternRule{
+		name: RuleSecrets,
+		patterns: []pattern{
+			{regexp.MustCompile(`xox[abposre]-[A-Za-z0-9-]{10,}`), "Slack token"},
+			{regexp.MustCompile(`xapp-\d-[A-Za-z0-9-]{10,}`), "Slack app token"},
+			{regexp.MustCompile(`https://hooks\.slack\.com/(?:services|workflows)/[A-Za-z0-9/_-]+`), "Slack webhook URL"},
+			{regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`), "AWS access key"},
+			{regexp.MustCompile(`(?i)aws.{0,20}(?:secret|private).{0,20}?['"=:\s](?P<m>[A-Za-z0-9/+]{40})\b`), "AWS secret key"},
+			{regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`), "GitHub token"},
+			{regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{22,}\b`), "GitHub token"},
+			{regexp.MustCompile(`-----BEGIN (?:[A-Z0-9]+ )*PRIVATE KEY(?: BLOCK)?-----(?s:.*?)(?:-----END (?:[A-Z0-9]+ )*PRIVATE KEY(?: BLOCK)?-----|$)`), "private key"},
+		},
+	}
+}
+
+// BroadcastRule finds @channel, @here and @everyone, which notify everyone in
+// a channel. Redacting drops the @ so the word stays.
+func BroadcastRule() Rule {
+	return &patternRule{
+		name: RuleBroadcast,
+		patterns: []pattern{
+			{regexp.MustCompile(`(?:^|[^\w@])(?P<m>@(?:channel|here|everyone))\b`), "@channel/@here mention"},
+			{regexp.MustCompile(`<!(?:channel|here|everyone)(?:\|[^>]*)?>`), "@channel/@here mention"},
+		},
+		redact: func(match string) string {
+			if match[0] == '@' {
+				return match[1:]
+			}
+			return broadcastSpecialRe.ReplaceAllString(match, "$1")
+		},
+	}
+}
+
+// DenyListRule finds matches of user supplied regular expressions.
+func DenyListRule(patterns []string) (Rule, error) {
+	r := &patternRule{name: RuleDenyList}
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid deny list pattern %q: %w", p, err)
+		}
+		r.patterns = append(r.patterns, pattern{re: re, reason: "deny list pattern " + p})
+	}
+	return r, nil
+}
+
+type maxLengthRule struct {
+	max int
+}
+
+// MaxLengthRule finds messages longer than max characters, redacting cuts
+// them down to max with an ellipsis.
+func MaxLengthRule(max int) Rule {
+	return &maxLengthRule{max: max}
+}
+
+func (r *maxLengthRule) Name() string {
+	return RuleMaxLength
+}
+
+func (r *maxLengthRule) Find(text string) []Match {
+	n := utf8.RuneCountInString(text)
+	if n <= r.max {
+		return nil
+	}
+	// byte offset of the last character that fits with the ellipsis
+	cut, count := len(text), 0
+	for i := range text {
+		if count == r.max-1 {
+			cut = i
+			break
+		}
+		count++
+	}
+	return []Match{{Start: cut, End: len(text), Reason: fmt.Sprintf("message of %d characters over the limit of %d", n, r.max)}}
+}
+
+func (r *maxLengthRule) Redact(text string, matches []Match) string {
+	if len(matches) == 0 {
+		return text
+	}
+	return text[:matches[0].Start] + "…"
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/access"
+	"github.com/korotovsky/slack-mcp-server/pkg/provider"
+	"github.com/slack-go/slack"
+)
+
+// maxSearchExclusions caps the -in: filters added to a search query, matches
+// in further hidden channels are still dropped from the results.
+const maxSearchExclusions = 20
+
+// checkReadable fails for conversations hidden by the read policy. The error
+// does not tell hidden channels apart from missing ones on purpose.
+func checkReadable(ctx context.Context, ap *provider.ApiProvider, channel string) error {
+	if !ap.ReadableChannel(ctx, channel) {
+		return fmt.Errorf("channel %q not found or not readable", channel)
+	}
+	return nil
+}
+
+// filterReadableChannels drops hidden conversations from a list of cached channels.
+func filterReadableChannels(policy *access.Policy, channels []provider.Channel) []provider.Channel {
+	if !policy.Enabled() {
+		return channels
+	}
+	res := channels[:0:0]
+	for _, c := range channels {
+		if policy.Allowed(provider.AccessChannel(c)) {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// filterReadableMatches drops search matches from hidden conversations.
+func filterReadableMatches(ap *provider.ApiProvider, matches []slack.SearchMessage) []slack.SearchMessage {
+	policy := ap.ReadPolicy()
+	if !policy.Enabled() {
+		return matches
+	}
+	channels := ap.ProvideChannelsMaps().Channels
+	res := matches[:0:0]
+	for _, m := range matches {
+		c, ok := channels[m.Channel.ID]
+		if ok && !policy.Allowed(provider.AccessChannel(c)) {
+			continue
+		}
+		if !ok && !policy.Allowed(searchAccessChannel(m.Channel)) {
+			continue
+		}
+		res = append(res, m)
+	}
+	return res
+}
+
+// searchAccessChannel describes the conversation of a search match that is
+// not cached to the read policy, named like the channels cache names it:
+// @ for DMs and group DMs, # for channels.
+func searchAccessChannel(c slack.CtxChannel) access.Channel {
+	isIM := strings.HasPrefix(c.ID, "D")
+	name := "#" + c.Name
+	if isIM || c.IsMPIM {
+		name = "@" + c.Name
+	}
+	return access.Channel{
+		ID:    c.ID,
+		Name:  name,
+		Class: access.ClassOf(c.IsPrivate, isIM, c.IsMPIM),
+	}
+}
+
+// searchExclusions returns -in: filters for cached channels the read policy
+// hides by ID or name, so they do not use up the page of results.
+func searchExclusions(ap *provider.ApiProvider) []string {
+	policy := ap.ReadPolicy()
+	if !policy.Enabled() {
+		return nil
+	}
+	var res []string
+	for _, c := range ap.ProvideChannelsMaps().Channels {
+		if c.IsIM || c.IsMpIM || !policy.Excluded(provider.AccessChannel(c)) {
+			continue
+		}
+		res = append(res, "-in:"+c.Name)
+	}
+	sort.Strings(res)
+	if len(res) > maxSearchExclusions {
+		res = res[:maxSearchExclusions]
+	}
+	return res
+}
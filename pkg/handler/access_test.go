@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
+	"github.com/korotovsky/slack-mcp-server/pkg/provider"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestUnitFilterReadableMatchesUncached(t *testing.T) {
+	cfg := config.Default()
+	cfg.XOXPToken = "demo"
+	cfg.ReadDeny = "@mpdm-*, #hr-*"
+	ap, err := provider.Open(cfg, zap.NewNop())
+	require.NoError(t, err)
+
+	match := func(id, name string, mpim bool) slack.SearchMessage {
+		var m slack.SearchMessage
+		m.Channel.ID, m.Channel.Name, m.Channel.IsMPIM = id, name, mpim
+		m.Channel.IsPrivate = mpim
+		return m
+	}
+	got := filterReadableMatches(ap, []slack.SearchMessage{
+		match("G1", "mpdm-alice--bob-1", true),
+		match("C1", "hr-payroll", false),
+		match("C2", "general", false),
+	})
+	require.Len(t, got, 1, "uncached group DMs are named like cached ones")
+	assert.Equal(t, "C2", got[0].Channel.ID)
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/server/approval"
+	"github.com/korotovsky/slack-mcp-server/pkg/text"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/slack-go/slack"
+)
+
+// AddMessagePreview describes a conversations_add_message call for approval.
+func (ch *ConversationsHandler) AddMessagePreview(ctx context.Context, request mcp.CallToolRequest) (*approval.Preview, error) {
+	return ch.messagePreview(ctx, request, "post a message")
+}
+
+// SchedulePreview describes a messages_schedule call for approval.
+func (ch *ConversationsHandler) SchedulePreview(ctx context.Context, request mcp.CallToolRequest) (*approval.Preview, error) {
+	preview, err := ch.messagePreview(ctx, request, "schedule a message")
+	if err != nil {
+		return nil, err
+	}
+
+	postAt := strings.TrimSpace(request.GetString("post_at", ""))
+	if t, err := parseFlexibleDateTime(postAt, time.Now(), ch.apiProvider.Location()); err == nil {
+		postAt = t.In(ch.apiProvider.Location()).Format(time.RFC1123)
+	}
+	preview.PostAt = postAt
+	return preview, nil
+}
+
+// messagePreview renders the payload the way Slack will show it, with
+// mentions resolved, along with the target channel.
+func (ch *ConversationsHandler) messagePreview(ctx context.Context, request mcp.CallToolRequest, action string) (*approval.Preview, error) {
+	params, err := ch.parseParamsToolAddMessage(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &approval.Preview{
+		Action:   action,
+		Channel:  params.channel,
+		ThreadTs: params.threadTs,
+		Text:     params.text,
+		Warnings: params.warnings,
+	}
+	if c, ok := ch.apiProvider.ProvideChannelsMaps().Channels[params.channel]; ok {
+		preview.ChannelName = c.Name
+	}
+
+	if params.contentType == "text/markdown" {
+		conv := text.MarkdownToBlocks(params.text, ch.mentionLookup())
+		preview.Warnings = append(preview.Warnings, conv.Warnings...)
+		if len(conv.Blocks) > 0 {
+			preview.Text = text.BlocksToMarkdown(slack.Blocks{BlockSet: conv.Blocks}, ch.textResolver())
+		} else {
+			preview.Text = text.MrkdwnToMarkdown(conv.Text, ch.textResolver())
+		}
+	}
+
+	return preview, nil
+}
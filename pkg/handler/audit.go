@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"github.com/korotovsky/slack-mcp-server/pkg/audit"
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
+	"go.uber.org/zap"
+)
+
+// newAuditor opens the audit log when SLACK_MCP_AUDIT_FILE or
+// SLACK_MCP_AUDIT_WEBHOOK is set.
+func newAuditor(cfg *config.Config, logger *zap.Logger) *audit.Auditor {
+	path := cfg.AuditFile
+	webhook := cfg.AuditWebhook
+	if path == "" && webhook == "" {
+		return nil
+	}
+
+	var sinks []audit.Sink
+	if path != "" {
+		file, err := audit.NewFileSink(path, int64(cfg.AuditMaxSize)*1024*1024, cfg.AuditMaxFiles)
+		if err != nil {
+			logger.Fatal("Failed to open audit log",
+				zap.String("audit_file", path),
+				zap.Error(err),
+			)
+		}
+		sinks = append(sinks, file)
+	}
+	if webhook != "" {
+		sinks = append(sinks, audit.NewWebhookSink(webhook, cfg.AuditWebhookToken, logger))
+	}
+
+	a, err := audit.New(logger, sinks...)
+	if err != nil {
+		logger.Fatal("Failed to resume audit log", zap.String("audit_file", path), zap.Error(err))
+	}
+	logger.Info("Audit log enabled",
+		zap.String("context", "console"),
+		zap.String("audit_file", path),
+		zap.Bool("webhook", webhook != ""),
+	)
+	return a
+}
+
+// Auditor returns the audit log of tool calls and writes, nil when disabled.
+func (ch *ConversationsHandler) Auditor() *audit.Auditor {
+	return ch.auditor
+}
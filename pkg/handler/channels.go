@@ -3,14 +3,15 @@ package handler
 import (
 	"context"
 	"encoding/base64"
-	"fmt"
+	"errors"
 	"sort"
 	"strings"
 
 	"github.com/gocarina/gocsv"
+	"github.com/korotovsky/slack-mcp-server/pkg/access"
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
 	"github.com/korotovsky/slack-mcp-server/pkg/provider"
 	"github.com/korotovsky/slack-mcp-server/pkg/server/auth"
-	"github.com/korotovsky/slack-mcp-server/pkg/text"
 	"github.com/mark3labs/mcp-go/mcp"
 	"go.uber.org/zap"
 )
@@ -24,13 +25,28 @@ type Channel struct {
 	Cursor      string `json:"cursor"`
 }
 
+// ChannelSearchResult is a row of the channels_search tool, unlike Channel
+// it also describes channels that are not part of the channels cache.
+type ChannelSearchResult struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Topic       string `json:"topic"`
+	Purpose     string `json:"purpose"`
+	MemberCount int    `json:"memberCount"`
+	IsMember    bool   `json:"isMember"`
+	IsArchived  bool   `json:"isArchived"`
+	IsPrivate   bool   `json:"isPrivate"`
+	Cursor      string `json:"cursor"`
+}
+
 type ChannelsHandler struct {
 	apiProvider *provider.ApiProvider
+	config      *config.Live
 	validTypes  map[string]bool
 	logger      *zap.Logger
 }
 
-func NewChannelsHandler(apiProvider *provider.ApiProvider, logger *zap.Logger) *ChannelsHandler {
+func NewChannelsHandler(apiProvider *provider.ApiProvider, cfg *config.Live, logger *zap.Logger) *ChannelsHandler {
 	validTypes := make(map[string]bool, len(provider.AllChanTypes))
 	for _, v := range provider.AllChanTypes {
 		validTypes[v] = true
@@ -38,6 +54,7 @@ func NewChannelsHandler(apiProvider *provider.ApiProvider, logger *zap.Logger) *
 
 	return &ChannelsHandler{
 		apiProvider: apiProvider,
+		config:      cfg,
 		validTypes:  validTypes,
 		logger:      logger,
 	}
@@ -47,7 +64,7 @@ func (ch *ChannelsHandler) ChannelsResource(ctx context.Context, request mcp.Rea
 	ch.logger.Debug("ChannelsResource called", zap.Any("params", request.Params))
 
 	// mark3labs/mcp-go does not support middlewares for resources.
-	if authenticated, err := auth.IsAuthenticated(ctx, ch.apiProvider.ServerTransport(), ch.logger); !authenticated {
+	if authenticated, err := auth.IsAuthenticated(ctx, ch.apiProvider.ServerTransport(), ch.config.Get().APIKey, ch.logger); !authenticated {
 		ch.logger.Error("Authentication failed for channels resource", zap.Error(err))
 		return nil, err
 	}
@@ -59,25 +76,17 @@ func (ch *ChannelsHandler) ChannelsResource(ctx context.Context, request mcp.Rea
 		return nil, err
 	}
 
-	ar, err := ch.apiProvider.Slack().AuthTest()
-	if err != nil {
-		ch.logger.Error("Auth test failed", zap.Error(err))
-		return nil, err
-	}
-
-	ws, err := text.Workspace(ar.URL)
-	if err != nil {
-		ch.logger.Error("Failed to parse workspace from URL",
-			zap.String("url", ar.URL),
-			zap.Error(err),
-		)
-		return nil, fmt.Errorf("failed to parse workspace from URL: %v", err)
-	}
-
 	channels := ch.apiProvider.ProvideChannelsMaps().Channels
 	ch.logger.Debug("Retrieved channels from provider", zap.Int("count", len(channels)))
 
+	rd := ch.apiProvider.Redactor()
+	policy := ch.apiProvider.ReadPolicy()
+	usersInv := ch.apiProvider.ProvideUsersMap().UsersInv
 	for _, channel := range channels {
+		if !policy.Allowed(provider.AccessChannel(channel)) {
+			continue
+		}
+		channel = redactChannel(rd, usersInv, channel)
 		channelList = append(channelList, Channel{
 			ID:          channel.ID,
 			Name:        channel.Name,
@@ -95,7 +104,7 @@ func (ch *ChannelsHandler) ChannelsResource(ctx context.Context, request mcp.Rea
 
 	return []mcp.ResourceContents{
 		mcp.TextResourceContents{
-			URI:      "slack://" + ws + "/channels",
+			URI:      "slack://" + ch.apiProvider.Workspace() + "/channels",
 			MIMEType: "text/csv",
 			Text:     string(csvBytes),
 		},
@@ -162,6 +171,8 @@ func (ch *ChannelsHandler) ChannelsHandler(ctx context.Context, request mcp.Call
 	channels := filterChannelsByTypes(allChannels, channelTypes)
 	ch.logger.Debug("Channels after filtering by type", zap.Int("count", len(channels)))
 
+	channels = filterReadableChannels(ch.apiProvider.ReadPolicy(), channels)
+
 	var chans []provider.Channel
 
 	chans, nextcur = paginateChannels(
@@ -175,7 +186,10 @@ func (ch *ChannelsHandler) ChannelsHandler(ctx context.Context, request mcp.Call
 		zap.Bool("has_next_page", nextcur != ""),
 	)
 
+	rd := ch.apiProvider.Redactor()
+	usersInv := ch.apiProvider.ProvideUsersMap().UsersInv
 	for _, channel := range chans {
+		channel = redactChannel(rd, usersInv, channel)
 		channelList = append(channelList, Channel{
 			ID:          channel.ID,
 			Name:        channel.Name,
@@ -209,6 +223,83 @@ func (ch *ChannelsHandler) ChannelsHandler(ctx context.Context, request mcp.Call
 	return mcp.NewToolResultText(string(csvBytes)), nil
 }
 
+func (ch *ChannelsHandler) ChannelsSearchHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("ChannelsSearchHandler called", zap.Any("params", request.Params))
+
+	query := strings.TrimSpace(request.GetString("query", ""))
+	cursor := request.GetString("cursor", "")
+	limit := request.GetInt("limit", 20)
+
+	if query == "" {
+		ch.logger.Error("Empty query for channels search")
+		return nil, errors.New("query must be a non-empty string")
+	}
+	if limit < 1 || limit > 100 {
+		ch.logger.Error("Invalid limit", zap.Int("limit", limit))
+		return nil, errors.New("limit must be an integer between 1 and 100")
+	}
+
+	channels, nextcur, err := ch.apiProvider.Slack().SearchChannelsContext(ctx, query, cursor, limit)
+	if err != nil {
+		ch.logger.Error("Channels search failed", zap.String("query", query), zap.Error(err))
+		return nil, err
+	}
+
+	ch.logger.Debug("Channels search completed",
+		zap.Int("count", len(channels)),
+		zap.Bool("has_next_page", nextcur != ""),
+	)
+
+	rd := ch.apiProvider.Redactor()
+	usersInv := ch.apiProvider.ProvideUsersMap().UsersInv
+	policy := ch.apiProvider.ReadPolicy()
+	var results []ChannelSearchResult
+	for _, channel := range channels {
+		name := channel.Name
+		prefix := "#"
+		if channel.IsIM || channel.IsMpIM {
+			prefix = "@"
+		}
+		if name != "" && !strings.HasPrefix(name, prefix) {
+			name = prefix + name
+		}
+		if !policy.Allowed(access.Channel{
+			ID:    channel.ID,
+			Name:  name,
+			Class: access.ClassOf(channel.IsPrivate, channel.IsIM, channel.IsMpIM),
+		}) {
+			continue
+		}
+		results = append(results, ChannelSearchResult{
+			ID:          channel.ID,
+			Name:        redactChannelName(rd, usersInv, name, channel.IsIM, channel.IsMpIM),
+			Topic:       rd.Text(channel.Topic.Value),
+			Purpose:     rd.Text(channel.Purpose.Value),
+			MemberCount: channel.NumMembers,
+			IsMember:    channel.IsMember,
+			IsArchived:  channel.IsArchived,
+			IsPrivate:   channel.IsPrivate,
+		})
+	}
+
+	if nextcur != "" {
+		// the search may stop before it finds a match, a row with just
+		// the cursor lets it resume
+		if len(results) == 0 {
+			results = append(results, ChannelSearchResult{})
+		}
+		results[len(results)-1].Cursor = nextcur
+	}
+
+	csvBytes, err := gocsv.MarshalBytes(&results)
+	if err != nil {
+		ch.logger.Error("Failed to marshal channels to CSV", zap.Error(err))
+		return nil, err
+	}
+
+	return mcp.NewToolResultText(string(csvBytes)), nil
+}
+
 func filterChannelsByTypes(channels map[string]provider.Channel, types []string) []provider.Channel {
 	logger := zap.L()
 
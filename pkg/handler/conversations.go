@@ -6,20 +6,24 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
-	"os"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gocarina/gocsv"
+	"github.com/korotovsky/slack-mcp-server/pkg/audit"
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
+	"github.com/korotovsky/slack-mcp-server/pkg/filter"
+	"github.com/korotovsky/slack-mcp-server/pkg/limiter"
+	"github.com/korotovsky/slack-mcp-server/pkg/outbox"
 	"github.com/korotovsky/slack-mcp-server/pkg/provider"
 	"github.com/korotovsky/slack-mcp-server/pkg/server/auth"
 	"github.com/korotovsky/slack-mcp-server/pkg/text"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/slack-go/slack"
-	slackGoUtil "github.com/takara2314/slack-go-util"
 	"go.uber.org/zap"
+	"golang.org/x/time/rate"
 )
 
 const (
@@ -39,16 +43,19 @@ var validFilterKeys = map[string]struct{}{
 }
 
 type Message struct {
-	MsgID     string `json:"msgID"`
-	UserID    string `json:"userID"`
-	UserName  string `json:"userUser"`
-	RealName  string `json:"realName"`
-	Channel   string `json:"channelID"`
-	ThreadTs  string `json:"ThreadTs"`
-	Text      string `json:"text"`
-	Time      string `json:"time"`
-	Reactions string `json:"reactions,omitempty"`
-	Cursor    string `json:"cursor"`
+	MsgID        string `json:"msgID"`
+	UserID       string `json:"userID"`
+	UserName     string `json:"userUser"`
+	RealName     string `json:"realName"`
+	Channel      string `json:"channelID"`
+	ThreadTs     string `json:"ThreadTs"`
+	Text         string `json:"text"`
+	Time         string `json:"time"`
+	TimeRelative string `json:"timeRelative"`
+	Edited       string `json:"edited,omitempty"`
+	Reactions    string `json:"reactions,omitempty"`
+	Permalink    string `json:"permalink"`
+	Cursor       string `json:"cursor"`
 }
 
 type User struct {
@@ -64,12 +71,20 @@ type conversationParams struct {
 	latest   string
 	cursor   string
 	activity bool
+
+	reactionUsers bool
 }
 
 type searchParams struct {
 	query string
 	limit int
 	page  int
+
+	contextMessages int
+	contextThread   bool
+	contextBudget   int
+
+	reactionUsers bool
 }
 
 type addMessageParams struct {
@@ -77,18 +92,82 @@ type addMessageParams struct {
 	threadTs    string
 	text        string
 	contentType string
+
+	// warnings of the content filters, text is already filtered
+	warnings []string
 }
 
 type ConversationsHandler struct {
 	apiProvider *provider.ApiProvider
+	config      *config.Live
 	logger      *zap.Logger
+
+	// fetchLimiter throttles the extra history and replies calls made to
+	// expand message context on top of the user requested ones.
+	fetchLimiter *rate.Limiter
+
+	// outbox delays posts when SLACK_MCP_OUTBOX_DELAY is set, nil otherwise.
+	outbox *outbox.Outbox
+
+	filters *filter.Chain
+
+	// auditor records tool calls and writes, nil when disabled.
+	auditor *audit.Auditor
+
+	// peers are the handlers of every workspace by name, they share the
+	// outbox, so a queued message is posted by the handler of its workspace.
+	peers map[string]*ConversationsHandler
 }
 
-func NewConversationsHandler(apiProvider *provider.ApiProvider, logger *zap.Logger) *ConversationsHandler {
-	return &ConversationsHandler{
-		apiProvider: apiProvider,
-		logger:      logger,
+func NewConversationsHandler(apiProvider *provider.ApiProvider, cfg *config.Live, logger *zap.Logger) *ConversationsHandler {
+	ch := &ConversationsHandler{
+		apiProvider:  apiProvider,
+		config:       cfg,
+		logger:       logger,
+		fetchLimiter: limiter.Tier3.Limiter(),
+		outbox:       newOutbox(cfg.Get(), logger),
+		filters:      newContentFilters(cfg.Get(), logger),
+		auditor:      newAuditor(cfg.Get(), logger),
+		peers:        make(map[string]*ConversationsHandler),
+	}
+	// in multi-tenant mode there is no provider until a tenant calls
+	if apiProvider != nil {
+		ch.peers[apiProvider.Workspace()] = ch
+	}
+	return ch
+}
+
+// ForWorkspace returns the handler of another workspace, it shares the
+// outbox, content filters and audit log of ch. It must be called before the
+// handlers are used.
+func (ch *ConversationsHandler) ForWorkspace(apiProvider *provider.ApiProvider) *ConversationsHandler {
+	peer := ch.WithProvider(apiProvider)
+	ch.peers[apiProvider.Workspace()] = peer
+	return peer
+}
+
+// WithProvider returns a handler that reads and writes with apiProvider and
+// shares the content filters and audit log of ch. Unlike ForWorkspace, the
+// handler is not known to ch, which is how the handlers of tenants are kept
+// apart.
+func (ch *ConversationsHandler) WithProvider(apiProvider *provider.ApiProvider) *ConversationsHandler {
+	peer := *ch
+	peer.apiProvider = apiProvider
+	peer.fetchLimiter = limiter.Tier3.Limiter()
+	return &peer
+}
+
+// peer returns the handler of the named workspace, ch itself for an empty
+// name.
+func (ch *ConversationsHandler) peer(workspace string) (*ConversationsHandler, error) {
+	if workspace == "" {
+		return ch, nil
+	}
+	peer, ok := ch.peers[workspace]
+	if !ok {
+		return nil, fmt.Errorf("workspace %q is not configured", workspace)
 	}
+	return peer, nil
 }
 
 // UsersResource streams a CSV of all users
@@ -96,7 +175,7 @@ func (ch *ConversationsHandler) UsersResource(ctx context.Context, request mcp.R
 	ch.logger.Debug("UsersResource called", zap.Any("params", request.Params))
 
 	// authentication
-	if authenticated, err := auth.IsAuthenticated(ctx, ch.apiProvider.ServerTransport(), ch.logger); !authenticated {
+	if authenticated, err := auth.IsAuthenticated(ctx, ch.apiProvider.ServerTransport(), ch.config.Get().APIKey, ch.logger); !authenticated {
 		ch.logger.Error("Authentication failed for users resource", zap.Error(err))
 		return nil, err
 	}
@@ -107,31 +186,20 @@ func (ch *ConversationsHandler) UsersResource(ctx context.Context, request mcp.R
 		return nil, err
 	}
 
-	// Slack auth test
-	ar, err := ch.apiProvider.Slack().AuthTest()
-	if err != nil {
-		ch.logger.Error("Slack AuthTest failed", zap.Error(err))
-		return nil, err
-	}
-
-	ws, err := text.Workspace(ar.URL)
-	if err != nil {
-		ch.logger.Error("Failed to parse workspace from URL",
-			zap.String("url", ar.URL),
-			zap.Error(err),
-		)
-		return nil, fmt.Errorf("failed to parse workspace from URL: %v", err)
-	}
-
 	// collect users
 	usersMaps := ch.apiProvider.ProvideUsersMap()
 	users := usersMaps.Users
 	usersList := make([]User, 0, len(users))
+	rd := ch.apiProvider.Redactor()
+	policy := ch.apiProvider.ReadPolicy()
 	for _, user := range users {
+		if !policy.UserAllowed(user.ID, user.Name) {
+			continue
+		}
 		usersList = append(usersList, User{
 			UserID:   user.ID,
-			UserName: user.Name,
-			RealName: user.RealName,
+			UserName: rd.UserName(user.ID, user.Name),
+			RealName: rd.RealName(user.ID, user.RealName),
 		})
 	}
 
@@ -144,7 +212,7 @@ func (ch *ConversationsHandler) UsersResource(ctx context.Context, request mcp.R
 
 	return []mcp.ResourceContents{
 		mcp.TextResourceContents{
-			URI:      "slack://" + ws + "/users",
+			URI:      "slack://" + ch.apiProvider.Workspace() + "/users",
 			MIMEType: "text/csv",
 			Text:     string(csvBytes),
 		},
@@ -155,40 +223,50 @@ func (ch *ConversationsHandler) UsersResource(ctx context.Context, request mcp.R
 func (ch *ConversationsHandler) ConversationsAddMessageHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	ch.logger.Debug("ConversationsAddMessageHandler called", zap.Any("params", request.Params))
 
-	params, err := ch.parseParamsToolAddMessage(request)
+	params, err := ch.parseParamsToolAddMessage(ctx, request)
 	if err != nil {
 		ch.logger.Error("Failed to parse add-message params", zap.Error(err))
 		return nil, err
 	}
 
-	var options []slack.MsgOption
-	if params.threadTs != "" {
-		options = append(options, slack.MsgOptionTS(params.threadTs))
+	if ch.outbox != nil {
+		return ch.enqueueMessage(params)
 	}
 
-	switch params.contentType {
-	case "text/plain":
-		options = append(options, slack.MsgOptionDisableMarkdown())
-		options = append(options, slack.MsgOptionText(params.text, false))
-	case "text/markdown":
-		blocks, err := slackGoUtil.ConvertMarkdownTextToBlocks(params.text)
-		if err != nil {
-			ch.logger.Warn("Markdown parsing error", zap.Error(err))
-			options = append(options, slack.MsgOptionDisableMarkdown())
-			options = append(options, slack.MsgOptionText(params.text, false))
-		} else {
-			options = append(options, slack.MsgOptionBlocks(blocks...))
-		}
-	default:
-		return nil, errors.New("content_type must be either 'text/plain' or 'text/markdown'")
+	respChannel, respTimestamp, warnings, err := ch.postMessage(ctx, params)
+	if err != nil {
+		return nil, err
 	}
 
-	unfurlOpt := os.Getenv("SLACK_MCP_ADD_MESSAGE_UNFURLING")
-	if text.IsUnfurlingEnabled(params.text, unfurlOpt, ch.logger) {
-		options = append(options, slack.MsgOptionEnableLinkUnfurl())
-	} else {
-		options = append(options, slack.MsgOptionDisableLinkUnfurl())
-		options = append(options, slack.MsgOptionDisableMediaUnfurl())
+	// fetch the single message we just posted
+	historyParams := slack.GetConversationHistoryParameters{
+		ChannelID: respChannel,
+		Limit:     1,
+		Oldest:    respTimestamp,
+		Latest:    respTimestamp,
+		Inclusive: true,
+	}
+	history, err := ch.apiProvider.Slack().GetConversationHistoryContext(ctx, &historyParams)
+	if err != nil {
+		ch.logger.Error("GetConversationHistoryContext failed", zap.Error(err))
+		return nil, err
+	}
+	ch.logger.Debug("Fetched conversation history", zap.Int("message_count", len(history.Messages)))
+
+	messages := ch.convertMessagesFromHistory(history.Messages, historyParams.ChannelID, false)
+	result, err := marshalMessagesToCSV(messages)
+	if err != nil {
+		return nil, err
+	}
+	return withWarnings(result, warnings), nil
+}
+
+// postMessage posts a message right away and marks the conversation as read
+// up to it when SLACK_MCP_ADD_MESSAGE_MARK is set.
+func (ch *ConversationsHandler) postMessage(ctx context.Context, params *addMessageParams) (string, string, []string, error) {
+	options, warnings, err := ch.messageOptions(params)
+	if err != nil {
+		return "", "", nil, err
 	}
 
 	ch.logger.Debug("Posting Slack message",
@@ -197,48 +275,81 @@ func (ch *ConversationsHandler) ConversationsAddMessageHandler(ctx context.Conte
 		zap.String("content_type", params.contentType),
 	)
 	respChannel, respTimestamp, err := ch.apiProvider.Slack().PostMessageContext(ctx, params.channel, options...)
+	audit.Write(ctx, "chat.postMessage", params.channel, respTimestamp, params.text, err)
 	if err != nil {
 		ch.logger.Error("Slack PostMessageContext failed", zap.Error(err))
-		return nil, err
+		return "", "", nil, err
 	}
 
-	toolConfig := os.Getenv("SLACK_MCP_ADD_MESSAGE_MARK")
-	if toolConfig == "1" || toolConfig == "true" || toolConfig == "yes" {
+	if ch.config.Get().AddMessageMark {
 		err := ch.apiProvider.Slack().MarkConversationContext(ctx, params.channel, respTimestamp)
 		if err != nil {
 			ch.logger.Error("Slack MarkConversationContext failed", zap.Error(err))
-			return nil, err
+			return "", "", nil, err
 		}
 	}
 
-	// fetch the single message we just posted
-	historyParams := slack.GetConversationHistoryParameters{
-		ChannelID: respChannel,
-		Limit:     1,
-		Oldest:    respTimestamp,
-		Latest:    respTimestamp,
-		Inclusive: true,
+	return respChannel, respTimestamp, warnings, nil
+}
+
+// messageOptions builds the chat.postMessage options shared by posting and
+// scheduling: thread, payload in the requested content type and unfurling.
+func (ch *ConversationsHandler) messageOptions(params *addMessageParams) ([]slack.MsgOption, []string, error) {
+	msgText := params.text
+	warnings := params.warnings
+
+	var options []slack.MsgOption
+	if params.threadTs != "" {
+		options = append(options, slack.MsgOptionTS(params.threadTs))
 	}
-	history, err := ch.apiProvider.Slack().GetConversationHistoryContext(ctx, &historyParams)
-	if err != nil {
-		ch.logger.Error("GetConversationHistoryContext failed", zap.Error(err))
-		return nil, err
+
+	switch params.contentType {
+	case "text/plain":
+		options = append(options, slack.MsgOptionDisableMarkdown())
+		options = append(options, slack.MsgOptionText(msgText, false))
+	case "text/markdown":
+		conv := text.MarkdownToBlocks(msgText, ch.mentionLookup())
+		for _, w := range conv.Warnings {
+			ch.logger.Warn("Markdown conversion warning", zap.String("warning", w))
+		}
+		warnings = append(warnings, conv.Warnings...)
+		options = append(options, slack.MsgOptionText(conv.Text, false))
+		if len(conv.Blocks) > 0 {
+			options = append(options, slack.MsgOptionBlocks(conv.Blocks...))
+		}
+	default:
+		return nil, nil, errors.New("content_type must be either 'text/plain' or 'text/markdown'")
 	}
-	ch.logger.Debug("Fetched conversation history", zap.Int("message_count", len(history.Messages)))
 
-	messages := ch.convertMessagesFromHistory(history.Messages, historyParams.ChannelID, false)
-	return marshalMessagesToCSV(messages)
+	if text.IsUnfurlingEnabled(msgText, ch.config.Get().AddMessageUnfurling, ch.logger) {
+		options = append(options, slack.MsgOptionEnableLinkUnfurl())
+	} else {
+		options = append(options, slack.MsgOptionDisableLinkUnfurl())
+		options = append(options, slack.MsgOptionDisableMediaUnfurl())
+	}
+
+	return options, warnings, nil
+}
+
+// withWarnings appends content filter and conversion warnings to a tool
+// result as extra content.
+func withWarnings(result *mcp.CallToolResult, warnings []string) *mcp.CallToolResult {
+	if len(warnings) > 0 {
+		result.Content = append(result.Content, mcp.NewTextContent("Warnings:\n- "+strings.Join(warnings, "\n- ")))
+	}
+	return result
 }
 
 // ConversationsHistoryHandler streams conversation history as CSV
 func (ch *ConversationsHandler) ConversationsHistoryHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	ch.logger.Debug("ConversationsHistoryHandler called", zap.Any("params", request.Params))
 
-	params, err := ch.parseParamsToolConversations(request)
+	params, err := ch.parseParamsToolConversations(ctx, request)
 	if err != nil {
 		ch.logger.Error("Failed to parse history params", zap.Error(err))
 		return nil, err
 	}
+	audit.SetChannel(ctx, params.channel)
 	ch.logger.Debug("History params parsed",
 		zap.String("channel", params.channel),
 		zap.Int("limit", params.limit),
@@ -264,6 +375,9 @@ func (ch *ConversationsHandler) ConversationsHistoryHandler(ctx context.Context,
 	ch.logger.Debug("Fetched conversation history", zap.Int("message_count", len(history.Messages)))
 
 	messages := ch.convertMessagesFromHistory(history.Messages, params.channel, params.activity)
+	if params.reactionUsers {
+		ch.setReactionUsers(messages, reactionsByTs(history.Messages))
+	}
 
 	if len(messages) > 0 && history.HasMore {
 		messages[len(messages)-1].Cursor = history.ResponseMetaData.NextCursor
@@ -275,11 +389,12 @@ func (ch *ConversationsHandler) ConversationsHistoryHandler(ctx context.Context,
 func (ch *ConversationsHandler) ConversationsRepliesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	ch.logger.Debug("ConversationsRepliesHandler called", zap.Any("params", request.Params))
 
-	params, err := ch.parseParamsToolConversations(request)
+	params, err := ch.parseParamsToolConversations(ctx, request)
 	if err != nil {
 		ch.logger.Error("Failed to parse replies params", zap.Error(err))
 		return nil, err
 	}
+	audit.SetChannel(ctx, params.channel)
 	threadTs := request.GetString("thread_ts", "")
 	if threadTs == "" {
 		ch.logger.Error("thread_ts not provided for replies", zap.String("thread_ts", threadTs))
@@ -303,6 +418,9 @@ func (ch *ConversationsHandler) ConversationsRepliesHandler(ctx context.Context,
 	ch.logger.Debug("Fetched conversation replies", zap.Int("count", len(replies)))
 
 	messages := ch.convertMessagesFromHistory(replies, params.channel, params.activity)
+	if params.reactionUsers {
+		ch.setReactionUsers(messages, reactionsByTs(replies))
+	}
 	if len(messages) > 0 && hasMore {
 		messages[len(messages)-1].Cursor = nextCursor
 	}
@@ -312,6 +430,25 @@ func (ch *ConversationsHandler) ConversationsRepliesHandler(ctx context.Context,
 func (ch *ConversationsHandler) ConversationsSearchHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	ch.logger.Debug("ConversationsSearchHandler called", zap.Any("params", request.Params))
 
+	if channel, ts, threadTs, err := text.ParsePermalink(request.GetString("search_query", "")); err == nil {
+		ch.logger.Debug("Search query is a message permalink", zap.String("channel", channel), zap.String("ts", ts))
+		if err := checkReadable(ctx, ch.apiProvider, channel); err != nil {
+			ch.logger.Warn("Channel hidden by read policy", zap.String("channel", channel))
+			return nil, err
+		}
+
+		window, err := ch.fetchMessageWindow(ctx, messageRef{channel: channel, ts: ts, threadTs: threadTs}, 0, 0, false, maxThreadMessages)
+		if err != nil {
+			ch.logger.Error("Failed to fetch message by permalink", zap.Error(err))
+			return nil, err
+		}
+		messages := ch.convertMessagesFromHistory(window, channel, true)
+		if request.GetBool("include_reaction_users", false) {
+			ch.setReactionUsers(messages, reactionsByTs(window))
+		}
+		return marshalMessagesToCSV(messages)
+	}
+
 	params, err := ch.parseParamsToolSearch(request)
 	if err != nil {
 		ch.logger.Error("Failed to parse search params", zap.Error(err))
@@ -332,21 +469,49 @@ func (ch *ConversationsHandler) ConversationsSearchHandler(ctx context.Context,
 		return nil, err
 	}
 	ch.logger.Debug("Search completed", zap.Int("matches", len(messagesRes.Matches)))
+	messagesRes.Matches = filterReadableMatches(ch.apiProvider, messagesRes.Matches)
+
+	var nextCursor string
+	if messagesRes.Pagination.Page < messagesRes.Pagination.PageCount {
+		nextCursor = base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("page:%d", messagesRes.Pagination.Page+1)))
+	}
+
+	if params.contextMessages > 0 || params.contextThread {
+		grouped, err := ch.expandSearchContext(ctx, messagesRes.Matches, params)
+		if err != nil {
+			ch.logger.Error("Failed to expand search context", zap.Error(err))
+			return nil, err
+		}
+		if len(grouped) > 0 {
+			grouped[len(grouped)-1].Cursor = nextCursor
+		}
+		csvBytes, err := gocsv.MarshalBytes(&grouped)
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(string(csvBytes)), nil
+	}
 
 	messages := ch.convertMessagesFromSearch(messagesRes.Matches)
-	if len(messages) > 0 && messagesRes.Pagination.Page < messagesRes.Pagination.PageCount {
-		nextCursor := fmt.Sprintf("page:%d", messagesRes.Pagination.Page+1)
-		messages[len(messages)-1].Cursor = base64.StdEncoding.EncodeToString([]byte(nextCursor))
+	if params.reactionUsers {
+		reactions, err := ch.fetchSearchReactions(ctx, messagesRes.Matches)
+		if err != nil {
+			ch.logger.Error("Failed to fetch reactions of search matches", zap.Error(err))
+			return nil, err
+		}
+		ch.setReactionUsers(messages, reactions)
+	}
+	if len(messages) > 0 {
+		messages[len(messages)-1].Cursor = nextCursor
 	}
 	return marshalMessagesToCSV(messages)
 }
 
-func isChannelAllowed(channel string) bool {
-	config := os.Getenv("SLACK_MCP_ADD_MESSAGE_TOOL")
-	if config == "" || config == "true" || config == "1" {
+func isChannelAllowed(toolConfig, channel string) bool {
+	if toolConfig == "" || toolConfig == "true" || toolConfig == "1" {
 		return true
 	}
-	items := strings.Split(config, ",")
+	items := strings.Split(toolConfig, ",")
 	isNegated := strings.HasPrefix(strings.TrimSpace(items[0]), "!")
 	for _, item := range items {
 		item = strings.TrimSpace(item)
@@ -365,6 +530,11 @@ func isChannelAllowed(channel string) bool {
 
 func (ch *ConversationsHandler) convertMessagesFromHistory(slackMessages []slack.Message, channel string, includeActivity bool) []Message {
 	usersMap := ch.apiProvider.ProvideUsersMap()
+	loc := ch.apiProvider.Location()
+	now := time.Now()
+	workspaceURL := ch.workspaceURL()
+	resolver := ch.textResolver()
+	rd := ch.apiProvider.Redactor()
 	var messages []Message
 	warn := false
 
@@ -383,30 +553,35 @@ func (ch *ConversationsHandler) convertMessagesFromHistory(slackMessages []slack
 			warn = true
 		}
 
-		timestamp, err := text.TimestampToIsoRFC3339(msg.Timestamp)
+		msgTime, err := text.TimestampToTime(msg.Timestamp)
 		if err != nil {
 			ch.logger.Error("Failed to convert timestamp to RFC3339", zap.Error(err))
 			continue
 		}
 
-		msgText := msg.Text + text.AttachmentsTo2CSV(msg.Text, msg.Attachments)
-
-		var reactionParts []string
-		for _, r := range msg.Reactions {
-			reactionParts = append(reactionParts, fmt.Sprintf("%s:%d", r.Name, r.Count))
+		var edited string
+		if msg.Edited != nil && msg.Edited.Timestamp != "" {
+			edited, err = text.TimestampToIsoRFC3339In(msg.Edited.Timestamp, loc)
+			if err != nil {
+				ch.logger.Warn("Failed to convert edited timestamp to RFC3339", zap.Error(err))
+			}
 		}
-		reactionsString := strings.Join(reactionParts, "|")
+
+		msgText := ch.renderText(msg.Text, msg.Blocks, msg.Attachments, resolver)
 
 		messages = append(messages, Message{
-			MsgID:     msg.Timestamp,
-			UserID:    msg.User,
-			UserName:  userName,
-			RealName:  realName,
-			Text:      text.ProcessText(msgText),
-			Channel:   channel,
-			ThreadTs:  msg.ThreadTimestamp,
-			Time:      timestamp,
-			Reactions: reactionsString,
+			MsgID:        msg.Timestamp,
+			UserID:       msg.User,
+			UserName:     rd.UserName(msg.User, userName),
+			RealName:     rd.RealName(msg.User, realName),
+			Text:         rd.Text(msgText),
+			Channel:      channel,
+			ThreadTs:     msg.ThreadTimestamp,
+			Time:         msgTime.In(loc).Format(time.RFC3339),
+			TimeRelative: text.HumanizeTimeSince(msgTime, now),
+			Edited:       edited,
+			Reactions:    formatReactions(msg.Reactions, resolver, nil, rd),
+			Permalink:    text.Permalink(workspaceURL, channel, msg.Timestamp, msg.ThreadTimestamp),
 		})
 	}
 
@@ -423,6 +598,11 @@ func (ch *ConversationsHandler) convertMessagesFromHistory(slackMessages []slack
 
 func (ch *ConversationsHandler) convertMessagesFromSearch(slackMessages []slack.SearchMessage) []Message {
 	usersMap := ch.apiProvider.ProvideUsersMap()
+	loc := ch.apiProvider.Location()
+	now := time.Now()
+	workspaceURL := ch.workspaceURL()
+	resolver := ch.textResolver()
+	rd := ch.apiProvider.Redactor()
 	var messages []Message
 	warn := false
 
@@ -437,24 +617,31 @@ func (ch *ConversationsHandler) convertMessagesFromSearch(slackMessages []slack.
 
 		threadTs, _ := extractThreadTS(msg.Permalink)
 
-		timestamp, err := text.TimestampToIsoRFC3339(msg.Timestamp)
+		msgTime, err := text.TimestampToTime(msg.Timestamp)
 		if err != nil {
 			ch.logger.Error("Failed to convert timestamp to RFC3339", zap.Error(err))
 			continue
 		}
 
-		msgText := msg.Text + text.AttachmentsTo2CSV(msg.Text, msg.Attachments)
+		msgText := ch.renderText(msg.Text, msg.Blocks, msg.Attachments, resolver)
+
+		permalink := msg.Permalink
+		if permalink == "" {
+			permalink = text.Permalink(workspaceURL, msg.Channel.ID, msg.Timestamp, threadTs)
+		}
 
 		messages = append(messages, Message{
-			MsgID:     msg.Timestamp,
-			UserID:    msg.User,
-			UserName:  userName,
-			RealName:  realName,
-			Text:      text.ProcessText(msgText),
-			Channel:   fmt.Sprintf("#%s", msg.Channel.Name),
-			ThreadTs:  threadTs,
-			Time:      timestamp,
-			Reactions: "",
+			MsgID:        msg.Timestamp,
+			UserID:       msg.User,
+			UserName:     rd.UserName(msg.User, userName),
+			RealName:     rd.RealName(msg.User, realName),
+			Text:         rd.Text(msgText),
+			Channel:      searchChannelName(rd, usersMap.UsersInv, msg.Channel),
+			ThreadTs:     threadTs,
+			Time:         msgTime.In(loc).Format(time.RFC3339),
+			TimeRelative: text.HumanizeTimeSince(msgTime, now),
+			Reactions:    "",
+			Permalink:    permalink,
 		})
 	}
 
@@ -469,7 +656,7 @@ func (ch *ConversationsHandler) convertMessagesFromSearch(slackMessages []slack.
 	return messages
 }
 
-func (ch *ConversationsHandler) parseParamsToolConversations(request mcp.CallToolRequest) (*conversationParams, error) {
+func (ch *ConversationsHandler) parseParamsToolConversations(ctx context.Context, request mcp.CallToolRequest) (*conversationParams, error) {
 	channel := request.GetString("channel_id", "")
 	if channel == "" {
 		ch.logger.Error("channel_id missing in conversations params")
@@ -500,6 +687,30 @@ func (ch *ConversationsHandler) parseParamsToolConversations(request mcp.CallToo
 		}
 	}
 
+	channel, err = ch.resolveChannelID(channel)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkReadable(ctx, ch.apiProvider, channel); err != nil {
+		ch.logger.Warn("Channel hidden by read policy", zap.String("channel", channel))
+		return nil, err
+	}
+
+	return &conversationParams{
+		channel:  channel,
+		limit:    paramLimit,
+		oldest:   paramOldest,
+		latest:   paramLatest,
+		cursor:   cursor,
+		activity: activity,
+
+		reactionUsers: request.GetBool("include_reaction_users", false),
+	}, nil
+}
+
+// resolveChannelID maps #channel and @user_dm names to channel IDs using the
+// channels cache, IDs are returned as is.
+func (ch *ConversationsHandler) resolveChannelID(channel string) (string, error) {
 	if strings.HasPrefix(channel, "#") || strings.HasPrefix(channel, "@") {
 		if ready, err := ch.apiProvider.IsReady(); !ready {
 			if errors.Is(err, provider.ErrUsersNotReady) {
@@ -514,56 +725,97 @@ func (ch *ConversationsHandler) parseParamsToolConversations(request mcp.CallToo
 					zap.Error(err),
 				)
 			}
-			return nil, fmt.Errorf("channel %q not found in empty cache", channel)
+			return "", fmt.Errorf("channel %q not found in empty cache", channel)
 		}
 		channelsMaps := ch.apiProvider.ProvideChannelsMaps()
 		chn, ok := channelsMaps.ChannelsInv[channel]
 		if !ok {
 			ch.logger.Error("Channel not found in synced cache", zap.String("channel", channel))
-			return nil, fmt.Errorf("channel %q not found in synced cache. Try to remove old cache file and restart MCP Server", channel)
+			return "", fmt.Errorf("channel %q not found in synced cache. Try to remove old cache file and restart MCP Server", channel)
 		}
 		channel = channelsMaps.Channels[chn].ID
 	}
 
-	return &conversationParams{
-		channel:  channel,
-		limit:    paramLimit,
-		oldest:   paramOldest,
-		latest:   paramLatest,
-		cursor:   cursor,
-		activity: activity,
-	}, nil
+	return channel, nil
 }
 
-func (ch *ConversationsHandler) parseParamsToolAddMessage(request mcp.CallToolRequest) (*addMessageParams, error) {
-	toolConfig := os.Getenv("SLACK_MCP_ADD_MESSAGE_TOOL")
-	if toolConfig == "" {
-		ch.logger.Error("Add-message tool disabled by default")
-		return nil, errors.New(
-			"by default, the conversations_add_message tool is disabled to guard Slack workspaces against accidental spamming." +
-				"To enable it, set the SLACK_MCP_ADD_MESSAGE_TOOL environment variable to true, 1, or comma separated list of channels" +
-				"to limit where the MCP can post messages, e.g. 'SLACK_MCP_ADD_MESSAGE_TOOL=C1234567890,D0987654321', 'SLACK_MCP_ADD_MESSAGE_TOOL=!C1234567890'" +
-				"to enable all except one or 'SLACK_MCP_ADD_MESSAGE_TOOL=true' for all channels and DMs",
-		)
+// renderText turns message text, blocks and attachments into the Text column.
+// The legacy TextRenderer brings back the old whitelist based filter.
+func (ch *ConversationsHandler) renderText(msgText string, blocks slack.Blocks, attachments []slack.Attachment, resolver text.Resolver) string {
+	if ch.config.Get().TextRenderer == text.RendererLegacy {
+		return text.ProcessText(msgText + text.AttachmentsTo2CSV(msgText, attachments))
 	}
 
-	channel := request.GetString("channel_id", "")
-	if channel == "" {
-		ch.logger.Error("channel_id missing in add-message params")
-		return nil, errors.New("channel_id must be a string")
-	}
-	if strings.HasPrefix(channel, "#") || strings.HasPrefix(channel, "@") {
-		channelsMaps := ch.apiProvider.ProvideChannelsMaps()
-		chn, ok := channelsMaps.ChannelsInv[channel]
-		if !ok {
-			ch.logger.Error("Channel not found", zap.String("channel", channel))
-			return nil, fmt.Errorf("channel %q not found", channel)
+	rendered := text.MrkdwnToMarkdown(msgText, resolver)
+	if text.IsTruncatedText(msgText) {
+		if fromBlocks := text.BlocksToMarkdown(blocks, resolver); fromBlocks != "" {
+			rendered = fromBlocks
 		}
-		channel = channelsMaps.Channels[chn].ID
 	}
-	if !isChannelAllowed(channel) {
-		ch.logger.Warn("Add-message tool not allowed for channel", zap.String("channel", channel), zap.String("policy", toolConfig))
-		return nil, fmt.Errorf("conversations_add_message tool is not allowed for channel %q, applied policy: %s", channel, toolConfig)
+
+	return rendered + text.AttachmentsTo2CSV(rendered, attachments)
+}
+
+// textResolver resolves user and channel mentions and custom emoji through
+// the caches.
+func (ch *ConversationsHandler) textResolver() text.Resolver {
+	users := ch.apiProvider.ProvideUsersMap().Users
+	channels := ch.apiProvider.ProvideChannelsMaps().Channels
+	rd := ch.apiProvider.Redactor()
+
+	return text.Resolver{
+		User: func(id string) string {
+			if u, ok := users[id]; ok {
+				return rd.UserName(id, u.Name)
+			}
+			return ""
+		},
+		Channel: func(id string) string {
+			if c, ok := channels[id]; ok {
+				return c.Name
+			}
+			return ""
+		},
+		Emoji: ch.apiProvider.EmojiUnicode,
+	}
+}
+
+// mentionLookup resolves @username and #channel references in outgoing
+// Markdown to IDs through the caches.
+func (ch *ConversationsHandler) mentionLookup() text.Mentions {
+	usersInv := ch.apiProvider.ProvideUsersMap().UsersInv
+	channelsMaps := ch.apiProvider.ProvideChannelsMaps()
+
+	return text.Mentions{
+		User: func(name string) string {
+			if id, ok := usersInv[name]; ok {
+				return id
+			}
+			return usersInv[strings.ToLower(name)]
+		},
+		Channel: func(name string) string {
+			if id, ok := channelsMaps.ChannelsInv["#"+name]; ok {
+				return channelsMaps.Channels[id].ID
+			}
+			return ""
+		},
+	}
+}
+
+// workspaceURL returns the workspace base URL used to build permalinks.
+func (ch *ConversationsHandler) workspaceURL() string {
+	ar, err := ch.apiProvider.Slack().AuthTest()
+	if err != nil {
+		ch.logger.Warn("Slack AuthTest failed, permalinks will be empty", zap.Error(err))
+		return ""
+	}
+	return ar.URL
+}
+
+func (ch *ConversationsHandler) parseParamsToolAddMessage(ctx context.Context, request mcp.CallToolRequest) (*addMessageParams, error) {
+	channel, err := ch.writableChannel(ctx, request.GetString("channel_id", ""))
+	if err != nil {
+		return nil, err
 	}
 
 	threadTs := request.GetString("thread_ts", "")
@@ -584,12 +836,57 @@ func (ch *ConversationsHandler) parseParamsToolAddMessage(request mcp.CallToolRe
 		return nil, errors.New("content_type must be either 'text/plain' or 'text/markdown'")
 	}
 
-	return &addMessageParams{
+	params := &addMessageParams{
 		channel:     channel,
 		threadTs:    threadTs,
 		text:        msgText,
 		contentType: contentType,
-	}, nil
+	}
+	params.text, params.warnings, err = ch.filterText(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return params, nil
+}
+
+// writableChannel resolves a #channel or @user reference and checks it
+// against the SLACK_MCP_ADD_MESSAGE_TOOL policy shared by all writing tools.
+func (ch *ConversationsHandler) writableChannel(ctx context.Context, channel string) (string, error) {
+	toolConfig := ch.config.Get().AddMessageTool
+	if toolConfig == "" {
+		ch.logger.Error("Add-message tool disabled by default")
+		return "", errors.New(
+			"by default, the conversations_add_message tool is disabled to guard Slack workspaces against accidental spamming." +
+				"To enable it, set the SLACK_MCP_ADD_MESSAGE_TOOL environment variable to true, 1, or comma separated list of channels" +
+				"to limit where the MCP can post messages, e.g. 'SLACK_MCP_ADD_MESSAGE_TOOL=C1234567890,D0987654321', 'SLACK_MCP_ADD_MESSAGE_TOOL=!C1234567890'" +
+				"to enable all except one or 'SLACK_MCP_ADD_MESSAGE_TOOL=true' for all channels and DMs",
+		)
+	}
+
+	if channel == "" {
+		ch.logger.Error("channel_id missing in add-message params")
+		return "", errors.New("channel_id must be a string")
+	}
+	if strings.HasPrefix(channel, "#") || strings.HasPrefix(channel, "@") {
+		channelsMaps := ch.apiProvider.ProvideChannelsMaps()
+		chn, ok := channelsMaps.ChannelsInv[channel]
+		if !ok {
+			ch.logger.Error("Channel not found", zap.String("channel", channel))
+			return "", fmt.Errorf("channel %q not found", channel)
+		}
+		channel = channelsMaps.Channels[chn].ID
+	}
+	if err := checkReadable(ctx, ch.apiProvider, channel); err != nil {
+		ch.logger.Warn("Channel hidden by read policy", zap.String("channel", channel))
+		return "", err
+	}
+	if !isChannelAllowed(toolConfig, channel) {
+		ch.logger.Warn("Add-message tool not allowed for channel", zap.String("channel", channel), zap.String("policy", toolConfig))
+		return "", fmt.Errorf("conversations_add_message tool is not allowed for channel %q, applied policy: %s", channel, toolConfig)
+	}
+
+	return channel, nil
 }
 
 func (ch *ConversationsHandler) parseParamsToolSearch(req mcp.CallToolRequest) (*searchParams, error) {
@@ -646,6 +943,9 @@ func (ch *ConversationsHandler) parseParamsToolSearch(req mcp.CallToolRequest) (
 	}
 
 	finalQuery := buildQuery(freeText, filters)
+	if exclusions := searchExclusions(ch.apiProvider); len(exclusions) > 0 {
+		finalQuery = strings.TrimSpace(finalQuery + " " + strings.Join(exclusions, " "))
+	}
 	limit := req.GetInt("limit", 100)
 	cursor := req.GetString("cursor", "")
 
@@ -673,15 +973,33 @@ func (ch *ConversationsHandler) parseParamsToolSearch(req mcp.CallToolRequest) (
 		page = 1
 	}
 
+	contextMessages := req.GetInt("context_messages", 0)
+	if contextMessages < 0 || contextMessages > maxSearchContextMessages {
+		ch.logger.Error("Invalid context_messages", zap.Int("context_messages", contextMessages))
+		return nil, fmt.Errorf("context_messages must be an integer between 0 and %d", maxSearchContextMessages)
+	}
+	contextBudget := req.GetInt("context_budget", defaultSearchContextBudget)
+	if contextBudget < 1 || contextBudget > maxSearchContextBudget {
+		ch.logger.Error("Invalid context_budget", zap.Int("context_budget", contextBudget))
+		return nil, fmt.Errorf("context_budget must be an integer between 1 and %d", maxSearchContextBudget)
+	}
+
 	ch.logger.Debug("Search parameters built",
 		zap.String("query", finalQuery),
 		zap.Int("limit", limit),
 		zap.Int("page", page),
+		zap.Int("context_messages", contextMessages),
 	)
 	return &searchParams{
 		query: finalQuery,
 		limit: limit,
 		page:  page,
+
+		contextMessages: contextMessages,
+		contextThread:   req.GetBool("context_thread", false),
+		contextBudget:   contextBudget,
+
+		reactionUsers: req.GetBool("include_reaction_users", false),
 	}, nil
 }
 
@@ -916,6 +1234,141 @@ func parseFlexibleDate(dateStr string) (time.Time, string, error) {
 	return time.Time{}, "", fmt.Errorf("unable to parse date: %s", dateStr)
 }
 
+// parseFlexibleDateTime parses a point in time for scheduling, interpreted in
+// loc: RFC3339 and Unix timestamps, "in 2 hours", "9am", "tomorrow 14:30",
+// "next monday at 9am" and any date parseFlexibleDate accepts, optionally
+// followed by a time. A date without a time means 09:00, a time without a
+// date its next occurrence.
+func parseFlexibleDateTime(expr string, now time.Time, loc *time.Location) (time.Time, error) {
+	expr = strings.TrimSpace(expr)
+	lower := strings.ToLower(expr)
+	now = now.In(loc)
+
+	if t, err := time.Parse(time.RFC3339, expr); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02T15:04:05"} {
+		if t, err := time.ParseInLocation(layout, expr, loc); err == nil {
+			return t, nil
+		}
+	}
+	if regexp.MustCompile(`^\d{10}$`).MatchString(expr) {
+		sec, _ := strconv.ParseInt(expr, 10, 64)
+		return time.Unix(sec, 0).In(loc), nil
+	}
+
+	relative := regexp.MustCompile(`^in\s+(\d+)\s*(minutes?|mins?|m|hours?|h|days?|d|weeks?|w)$`)
+	if m := relative.FindStringSubmatch(lower); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		switch m[2][0] {
+		case 'm':
+			return now.Add(time.Duration(n) * time.Minute), nil
+		case 'h':
+			return now.Add(time.Duration(n) * time.Hour), nil
+		case 'd':
+			return now.AddDate(0, 0, n), nil
+		default:
+			return now.AddDate(0, 0, 7*n), nil
+		}
+	}
+
+	datePart, hour, minute, hasTime, err := splitTimeOfDay(lower)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !hasTime {
+		hour, minute = 9, 0
+	}
+	at := func(d time.Time) time.Time {
+		return time.Date(d.Year(), d.Month(), d.Day(), hour, minute, 0, 0, loc)
+	}
+
+	weekdays := map[string]time.Weekday{
+		"sunday": time.Sunday, "sun": time.Sunday,
+		"monday": time.Monday, "mon": time.Monday,
+		"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+		"wednesday": time.Wednesday, "wed": time.Wednesday,
+		"thursday": time.Thursday, "thu": time.Thursday, "thurs": time.Thursday,
+		"friday": time.Friday, "fri": time.Friday,
+		"saturday": time.Saturday, "sat": time.Saturday,
+	}
+
+	switch datePart {
+	case "":
+		t := at(now)
+		if !t.After(now) {
+			t = at(now.AddDate(0, 0, 1))
+		}
+		return t, nil
+	case "today":
+		return at(now), nil
+	case "tomorrow":
+		return at(now.AddDate(0, 0, 1)), nil
+	}
+
+	next := strings.HasPrefix(datePart, "next ")
+	if wd, ok := weekdays[strings.TrimPrefix(strings.TrimPrefix(datePart, "next "), "on ")]; ok {
+		days := (int(wd) - int(now.Weekday()) + 7) % 7
+		t := at(now.AddDate(0, 0, days))
+		if next && days == 0 || !t.After(now) {
+			t = t.AddDate(0, 0, 7)
+		}
+		return t, nil
+	}
+
+	d, _, err := parseFlexibleDate(datePart)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unable to parse date and time: %s", expr)
+	}
+	return at(d), nil
+}
+
+// splitTimeOfDay cuts a trailing time of day such as "9am", "at 14:30" or
+// "noon" off a date and time expression.
+func splitTimeOfDay(expr string) (datePart string, hour, minute int, ok bool, err error) {
+	for word, h := range map[string]int{"noon": 12, "midnight": 0} {
+		if rest, found := strings.CutSuffix(expr, word); found {
+			return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(rest), " at")), h, 0, true, nil
+		}
+	}
+
+	re := regexp.MustCompile(`(?:^|\s)(?:at\s+)?(\d{1,2})(?::(\d{2}))?\s*(am|pm)?$`)
+	m := re.FindStringSubmatchIndex(expr)
+	if m == nil {
+		return expr, 0, 0, false, nil
+	}
+	hourStr := expr[m[2]:m[3]]
+	var minStr, ampm string
+	if m[4] != -1 {
+		minStr = expr[m[4]:m[5]]
+	}
+	if m[6] != -1 {
+		ampm = expr[m[6]:m[7]]
+	}
+	// a bare number is a day or a year, not a time
+	if minStr == "" && ampm == "" && !strings.Contains(expr[m[0]:m[1]], "at") {
+		return expr, 0, 0, false, nil
+	}
+
+	hour, _ = strconv.Atoi(hourStr)
+	if minStr != "" {
+		minute, _ = strconv.Atoi(minStr)
+	}
+	switch {
+	case ampm != "" && (hour < 1 || hour > 12):
+		return "", 0, 0, false, fmt.Errorf("invalid hour in time: %s", expr)
+	case ampm == "pm" && hour != 12:
+		hour += 12
+	case ampm == "am" && hour == 12:
+		hour = 0
+	}
+	if hour > 23 || minute > 59 {
+		return "", 0, 0, false, fmt.Errorf("invalid time: %s", expr)
+	}
+
+	return strings.TrimSpace(expr[:m[0]]), hour, minute, true, nil
+}
+
 func buildDateFilters(before, after, on, during string) (map[string]string, error) {
 	out := make(map[string]string)
 	if on != "" {
@@ -405,6 +405,54 @@ func TestUnitParseFlexibleDate(t *testing.T) {
 	}
 }
 
+func TestUnitParseFlexibleDateTime(t *testing.T) {
+	loc := time.FixedZone("CEST", 2*60*60)
+	// Wednesday
+	now := time.Date(2025, 7, 16, 10, 0, 0, 0, loc)
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "RFC3339", input: "2025-07-20T08:30:00Z", want: "2025-07-20T08:30:00Z"},
+		{name: "date and time", input: "2025-07-20 14:30", want: "2025-07-20T14:30:00+02:00"},
+		{name: "unix timestamp", input: "1753000000", want: "2025-07-20T10:26:40+02:00"},
+		{name: "in minutes", input: "in 30 minutes", want: "2025-07-16T10:30:00+02:00"},
+		{name: "in hours", input: "in 2 hours", want: "2025-07-16T12:00:00+02:00"},
+		{name: "in days", input: "in 3 days", want: "2025-07-19T10:00:00+02:00"},
+		{name: "in a week", input: "in 1 week", want: "2025-07-23T10:00:00+02:00"},
+		{name: "time later today", input: "3pm", want: "2025-07-16T15:00:00+02:00"},
+		{name: "time passed today", input: "9am", want: "2025-07-17T09:00:00+02:00"},
+		{name: "24h time", input: "at 14:30", want: "2025-07-16T14:30:00+02:00"},
+		{name: "noon", input: "noon", want: "2025-07-16T12:00:00+02:00"},
+		{name: "tomorrow", input: "tomorrow", want: "2025-07-17T09:00:00+02:00"},
+		{name: "tomorrow with time", input: "Tomorrow 14:30", want: "2025-07-17T14:30:00+02:00"},
+		{name: "tomorrow at noon", input: "tomorrow at noon", want: "2025-07-17T12:00:00+02:00"},
+		{name: "weekday", input: "friday 9am", want: "2025-07-18T09:00:00+02:00"},
+		{name: "same weekday", input: "wednesday at 8", want: "2025-07-23T08:00:00+02:00"},
+		{name: "next weekday", input: "next monday at 10:15", want: "2025-07-21T10:15:00+02:00"},
+		{name: "month and day", input: "July 20 2025 5pm", want: "2025-07-20T17:00:00+02:00"},
+		{name: "date only", input: "2025-08-01", want: "2025-08-01T09:00:00+02:00"},
+		{name: "invalid hour", input: "tomorrow 13pm", wantErr: true},
+		{name: "invalid minute", input: "tomorrow 10:75", wantErr: true},
+		{name: "garbage", input: "whenever", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseFlexibleDateTime(tt.input, now, loc)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.Format(time.RFC3339))
+		})
+	}
+}
+
 func TestUnitBuildDateFiltersUnit(t *testing.T) {
 	tests := []struct {
 		name    string
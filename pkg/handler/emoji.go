@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"github.com/gocarina/gocsv"
+	"github.com/korotovsky/slack-mcp-server/pkg/provider"
+	"github.com/mark3labs/mcp-go/mcp"
+	"go.uber.org/zap"
+)
+
+type Emoji struct {
+	Name     string `json:"name"`
+	Unicode  string `json:"unicode"`
+	AliasFor string `json:"aliasFor"`
+	URL      string `json:"url"`
+	Cursor   string `json:"cursor"`
+}
+
+type EmojiHandler struct {
+	apiProvider *provider.ApiProvider
+	logger      *zap.Logger
+}
+
+func NewEmojiHandler(apiProvider *provider.ApiProvider, logger *zap.Logger) *EmojiHandler {
+	return &EmojiHandler{
+		apiProvider: apiProvider,
+		logger:      logger,
+	}
+}
+
+// EmojiListHandler lists the workspace custom emoji as CSV
+func (eh *EmojiHandler) EmojiListHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	eh.logger.Debug("EmojiListHandler called", zap.Any("params", request.Params))
+
+	query := strings.ToLower(strings.Trim(strings.TrimSpace(request.GetString("query", "")), ":"))
+	cursor := request.GetString("cursor", "")
+	limit := request.GetInt("limit", 100)
+	if limit < 1 || limit > 1000 {
+		eh.logger.Error("Invalid limit", zap.Int("limit", limit))
+		return nil, errors.New("limit must be an integer between 1 and 1000")
+	}
+
+	var matched []provider.Emoji
+	for _, e := range eh.apiProvider.ProvideEmoji() {
+		if query == "" || strings.Contains(e.Name, query) || strings.Contains(e.AliasFor, query) {
+			matched = append(matched, e)
+		}
+	}
+
+	// names are sorted, the cursor is the last name returned
+	start := 0
+	if cursor != "" {
+		decoded, err := base64.StdEncoding.DecodeString(cursor)
+		if err != nil {
+			eh.logger.Error("Invalid cursor", zap.String("cursor", cursor), zap.Error(err))
+			return nil, errors.New("invalid cursor")
+		}
+		for start < len(matched) && matched[start].Name <= string(decoded) {
+			start++
+		}
+	}
+	end := min(start+limit, len(matched))
+
+	var list []Emoji
+	for _, e := range matched[start:end] {
+		list = append(list, Emoji{
+			Name:     ":" + e.Name + ":",
+			Unicode:  e.Unicode,
+			AliasFor: e.AliasFor,
+			URL:      e.URL,
+		})
+	}
+	if len(list) > 0 && end < len(matched) {
+		list[len(list)-1].Cursor = base64.StdEncoding.EncodeToString([]byte(matched[end-1].Name))
+	}
+
+	eh.logger.Debug("Listed emoji",
+		zap.Int("matched", len(matched)),
+		zap.Int("returned", len(list)),
+	)
+
+	csvBytes, err := gocsv.MarshalBytes(&list)
+	if err != nil {
+		eh.logger.Error("Failed to marshal emoji to CSV", zap.Error(err))
+		return nil, err
+	}
+
+	return mcp.NewToolResultText(string(csvBytes)), nil
+}
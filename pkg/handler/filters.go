@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
+	"github.com/korotovsky/slack-mcp-server/pkg/filter"
+	"go.uber.org/zap"
+)
+
+// newContentFilters builds the outgoing content filter chain from
+// SLACK_MCP_CONTENT_FILTERS, SLACK_MCP_CONTENT_FILTERS_CHANNELS,
+// SLACK_MCP_CONTENT_MAX_LENGTH and the SLACK_MCP_CONTENT_DENY_LIST file.
+func newContentFilters(cfg *config.Config, logger *zap.Logger) *filter.Chain {
+	filterConfig := filter.Config{
+		Actions:   cfg.ContentFilters,
+		Overrides: cfg.ContentFiltersChannels,
+		MaxLength: cfg.ContentMaxLength,
+	}
+
+	if path := cfg.ContentDenyList; path != "" {
+		patterns, err := readDenyList(path)
+		if err != nil {
+			logger.Fatal("Failed to read SLACK_MCP_CONTENT_DENY_LIST",
+				zap.String("path", path),
+				zap.Error(err),
+			)
+		}
+		filterConfig.DenyPatterns = patterns
+	}
+
+	chain, err := filter.New(filterConfig)
+	if err != nil {
+		logger.Fatal("Invalid content filter configuration", zap.Error(err))
+	}
+	return chain
+}
+
+// readDenyList reads one regular expression per line, blank lines and lines
+// starting with # are skipped.
+func readDenyList(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns, scanner.Err()
+}
+
+// filterText runs the content filters on an outgoing message, it returns the
+// text to post and the warnings of the rules that matched.
+func (ch *ConversationsHandler) filterText(params *addMessageParams) (string, []string, error) {
+	if ch.filters == nil || !ch.filters.Enabled() {
+		return params.text, nil, nil
+	}
+
+	var channelName string
+	if c, ok := ch.apiProvider.ProvideChannelsMaps().Channels[params.channel]; ok {
+		channelName = c.Name
+	}
+
+	res, err := ch.filters.Apply(params.channel, channelName, params.text)
+	if err != nil {
+		ch.logger.Warn("Message blocked by content filter",
+			zap.String("channel", params.channel),
+			zap.Error(err),
+		)
+		return "", nil, err
+	}
+	for _, w := range res.Warnings {
+		ch.logger.Warn("Content filter warning", zap.String("channel", params.channel), zap.String("warning", w))
+	}
+	return res.Text, res.Warnings, nil
+}
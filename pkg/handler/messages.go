@@ -0,0 +1,376 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/audit"
+	"github.com/korotovsky/slack-mcp-server/pkg/provider"
+	"github.com/korotovsky/slack-mcp-server/pkg/text"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+const (
+	maxMessagesGetRefs    = 20
+	maxMessagesGetContext = 100
+	maxThreadMessages     = 1000
+)
+
+type messageRef struct {
+	channel  string
+	ts       string
+	threadTs string
+}
+
+type messagesGetParams struct {
+	refs          []messageRef
+	before        int
+	after         int
+	includeThread bool
+	activity      bool
+}
+
+// MessagesGetHandler fetches messages by permalink or channel+ts pair, with optional surrounding context, as CSV
+func (ch *ConversationsHandler) MessagesGetHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("MessagesGetHandler called", zap.Any("params", request.Params))
+
+	params, err := ch.parseParamsToolMessagesGet(request)
+	if err != nil {
+		ch.logger.Error("Failed to parse messages_get params", zap.Error(err))
+		return nil, err
+	}
+	for _, ref := range params.refs {
+		if err := checkReadable(ctx, ch.apiProvider, ref.channel); err != nil {
+			ch.logger.Warn("Channel hidden by read policy", zap.String("channel", ref.channel))
+			return nil, err
+		}
+	}
+	if len(params.refs) == 1 {
+		audit.SetChannel(ctx, params.refs[0].channel)
+	}
+
+	var messages []Message
+	seen := make(map[string]struct{})
+	for _, ref := range params.refs {
+		window, err := ch.fetchMessageWindow(ctx, ref, params.before, params.after, params.includeThread, maxThreadMessages)
+		if err != nil {
+			ch.logger.Error("Failed to fetch message",
+				zap.String("channel", ref.channel),
+				zap.String("ts", ref.ts),
+				zap.Error(err),
+			)
+			return nil, err
+		}
+
+		for _, m := range ch.convertMessagesFromHistory(window, ref.channel, params.activity) {
+			key := m.Channel + "/" + m.MsgID
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			messages = append(messages, m)
+		}
+	}
+
+	ch.logger.Debug("Fetched messages", zap.Int("count", len(messages)))
+	return marshalMessagesToCSV(messages)
+}
+
+// fetchMessageWindow returns the referenced message with up to before/after
+// neighbours and optionally its thread, ordered oldest first. Replies take
+// their neighbours from the thread, top-level messages from the channel. At
+// most threadLimit messages of the thread are fetched.
+func (ch *ConversationsHandler) fetchMessageWindow(ctx context.Context, ref messageRef, before, after int, includeThread bool, threadLimit int) ([]slack.Message, error) {
+	target, err := ch.fetchMessage(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	window := []slack.Message{*target}
+	threadTs := target.ThreadTimestamp
+	isReply := threadTs != "" && threadTs != target.Timestamp
+
+	var thread []slack.Message
+	if isReply && (before > 0 || after > 0) || includeThread && threadTs != "" {
+		thread, err = ch.fetchThread(ctx, ref.channel, threadTs, threadLimit)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if includeThread {
+		window = append(window, thread...)
+	}
+
+	if isReply {
+		for i, m := range thread {
+			if m.Timestamp != target.Timestamp {
+				continue
+			}
+			window = append(window, thread[max(0, i-before):i]...)
+			window = append(window, thread[i+1:min(len(thread), i+1+after)]...)
+			break
+		}
+	} else {
+		if before > 0 {
+			if err := ch.waitFetch(ctx); err != nil {
+				return nil, err
+			}
+			history, err := ch.apiProvider.Slack().GetConversationHistoryContext(ctx, &slack.GetConversationHistoryParameters{
+				ChannelID: ref.channel,
+				Latest:    target.Timestamp,
+				Limit:     before,
+				Inclusive: false,
+			})
+			if err != nil {
+				return nil, err
+			}
+			window = append(window, history.Messages...)
+		}
+		if after > 0 {
+			later, err := ch.fetchMessagesAfter(ctx, ch.apiProvider.Slack(), ref.channel, target.Timestamp, after)
+			if err != nil {
+				return nil, err
+			}
+			window = append(window, later...)
+		}
+	}
+
+	return uniqueMessages(window), nil
+}
+
+// maxAfterPages caps the conversations.history calls made to find the
+// messages after a target, the window is partial when it is hit.
+const maxAfterPages = 6
+
+// fetchMessagesAfter returns up to n channel messages posted right after ts,
+// oldest first. conversations.history returns the newest messages first, so
+// it is asked for the messages of a time span after ts: the span shrinks
+// while it holds more than a page and grows while it holds fewer than n.
+func (ch *ConversationsHandler) fetchMessagesAfter(ctx context.Context, api provider.SlackAPI, channel, ts string, n int) ([]slack.Message, error) {
+	oldest, err := text.TimestampToTime(ts)
+	if err != nil {
+		return nil, err
+	}
+
+	span := time.Hour
+	var later []slack.Message
+	for range maxAfterPages {
+		latest := oldest.Add(span)
+		if err := ch.waitFetch(ctx); err != nil {
+			return nil, err
+		}
+		history, err := api.GetConversationHistoryContext(ctx, &slack.GetConversationHistoryParameters{
+			ChannelID: channel,
+			Oldest:    formatTs(oldest),
+			Latest:    formatTs(latest),
+			Limit:     200,
+			Inclusive: false,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if history.HasMore {
+			span /= 8
+			continue
+		}
+
+		later = append(later, history.Messages...)
+		if len(later) >= n || latest.After(time.Now()) {
+			break
+		}
+		// latest is left out of the span, the next one starts right before
+		oldest, span = latest.Add(-time.Microsecond), span*4
+	}
+	sortMessagesByTs(later)
+	return later[:min(len(later), n)], nil
+}
+
+// formatTs formats t as a Slack timestamp such as 1700000000.123456.
+func formatTs(t time.Time) string {
+	return fmt.Sprintf("%d.%06d", t.Unix(), t.Nanosecond()/1000)
+}
+
+// fetchMessage looks the message up in the channel history first and falls
+// back to the thread, as replies are not part of conversations.history.
+func (ch *ConversationsHandler) fetchMessage(ctx context.Context, ref messageRef) (*slack.Message, error) {
+	if err := ch.waitFetch(ctx); err != nil {
+		return nil, err
+	}
+	history, err := ch.apiProvider.Slack().GetConversationHistoryContext(ctx, &slack.GetConversationHistoryParameters{
+		ChannelID: ref.channel,
+		Oldest:    ref.ts,
+		Latest:    ref.ts,
+		Limit:     1,
+		Inclusive: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range history.Messages {
+		if m.Timestamp == ref.ts {
+			return &m, nil
+		}
+	}
+
+	parent := ref.threadTs
+	if parent == "" {
+		parent = ref.ts
+	}
+	if err := ch.waitFetch(ctx); err != nil {
+		return nil, err
+	}
+	replies, _, _, err := ch.apiProvider.Slack().GetConversationRepliesContext(ctx, &slack.GetConversationRepliesParameters{
+		ChannelID: ref.channel,
+		Timestamp: parent,
+		Oldest:    ref.ts,
+		Latest:    ref.ts,
+		Limit:     1,
+		Inclusive: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range replies {
+		if m.Timestamp == ref.ts {
+			return &m, nil
+		}
+	}
+
+	return nil, fmt.Errorf("message %s not found in channel %s", ref.ts, ref.channel)
+}
+
+// fetchThread returns the parent message and its replies, capped at limit.
+func (ch *ConversationsHandler) fetchThread(ctx context.Context, channel, threadTs string, limit int) ([]slack.Message, error) {
+	params := slack.GetConversationRepliesParameters{
+		ChannelID: channel,
+		Timestamp: threadTs,
+		Limit:     min(200, limit),
+	}
+
+	var thread []slack.Message
+	for {
+		if err := ch.waitFetch(ctx); err != nil {
+			return nil, err
+		}
+		replies, hasMore, nextCursor, err := ch.apiProvider.Slack().GetConversationRepliesContext(ctx, &params)
+		if err != nil {
+			return nil, err
+		}
+		thread = append(thread, replies...)
+		if !hasMore || nextCursor == "" || len(thread) >= limit {
+			break
+		}
+		params.Cursor = nextCursor
+	}
+
+	if len(thread) > limit {
+		thread = thread[:limit]
+	}
+	return thread, nil
+}
+
+func (ch *ConversationsHandler) parseParamsToolMessagesGet(request mcp.CallToolRequest) (*messagesGetParams, error) {
+	raw := request.GetString("messages", "")
+	if strings.TrimSpace(raw) == "" {
+		return nil, errors.New("messages must be a non-empty string")
+	}
+
+	var refs []messageRef
+	for _, item := range strings.FieldsFunc(raw, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\n' || r == '\t'
+	}) {
+		ref, err := ch.parseMessageRef(item)
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, ref)
+	}
+	if len(refs) > maxMessagesGetRefs {
+		return nil, fmt.Errorf("too many messages requested: %d, maximum is %d", len(refs), maxMessagesGetRefs)
+	}
+
+	before := request.GetInt("context_before", 0)
+	after := request.GetInt("context_after", 0)
+	if before < 0 || before > maxMessagesGetContext || after < 0 || after > maxMessagesGetContext {
+		return nil, fmt.Errorf("context_before and context_after must be integers between 0 and %d", maxMessagesGetContext)
+	}
+
+	return &messagesGetParams{
+		refs:          refs,
+		before:        before,
+		after:         after,
+		includeThread: request.GetBool("include_thread", false),
+		activity:      request.GetBool("include_activity_messages", false),
+	}, nil
+}
+
+// parseMessageRef accepts a Slack permalink or a channel_id:ts pair where the
+// channel may also be given by its #name or @name.
+func (ch *ConversationsHandler) parseMessageRef(raw string) (messageRef, error) {
+	if strings.HasPrefix(raw, "http://") || strings.HasPrefix(raw, "https://") {
+		channel, ts, threadTs, err := text.ParsePermalink(raw)
+		if err != nil {
+			return messageRef{}, err
+		}
+		return messageRef{channel: channel, ts: ts, threadTs: threadTs}, nil
+	}
+
+	idx := strings.LastIndex(raw, ":")
+	if idx <= 0 {
+		return messageRef{}, fmt.Errorf("invalid message reference %q, expected a permalink or channel_id:ts", raw)
+	}
+	channel, ts := raw[:idx], raw[idx+1:]
+	if !strings.Contains(ts, ".") {
+		return messageRef{}, fmt.Errorf("invalid message reference %q, ts must be in format 1234567890.123456", raw)
+	}
+
+	channel, err := ch.resolveChannelID(channel)
+	if err != nil {
+		return messageRef{}, err
+	}
+
+	return messageRef{channel: channel, ts: ts}, nil
+}
+
+// waitFetch blocks until the next context fetch is allowed by the rate limiter
+// and charges it to the search context budget of ctx, if any.
+func (ch *ConversationsHandler) waitFetch(ctx context.Context) error {
+	if err := chargeFetch(ctx); err != nil {
+		return err
+	}
+	if ch.fetchLimiter == nil {
+		return nil
+	}
+	if err := ch.fetchLimiter.Wait(ctx); err != nil {
+		ch.logger.Error("Rate limiter wait failed", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func sortMessagesByTs(messages []slack.Message) {
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].Timestamp < messages[j].Timestamp
+	})
+}
+
+// uniqueMessages orders messages oldest first and drops repeated timestamps.
+func uniqueMessages(messages []slack.Message) []slack.Message {
+	sortMessagesByTs(messages)
+
+	out := messages[:0]
+	for _, m := range messages {
+		if len(out) > 0 && out[len(out)-1].Timestamp == m.Timestamp {
+			continue
+		}
+		out = append(out, m)
+	}
+	return out
+}
@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/provider"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestUnitParseMessageRef(t *testing.T) {
+	ch := &ConversationsHandler{logger: zap.NewNop()}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    messageRef
+		wantErr bool
+	}{
+		{
+			name:  "permalink",
+			input: "https://example.slack.com/archives/C1234567890/p1234567890123456",
+			want:  messageRef{channel: "C1234567890", ts: "1234567890.123456"},
+		},
+		{
+			name:  "thread reply permalink",
+			input: "https://example.slack.com/archives/C1234567890/p1234567891000100?thread_ts=1234567890.123456&cid=C1234567890",
+			want:  messageRef{channel: "C1234567890", ts: "1234567891.000100", threadTs: "1234567890.123456"},
+		},
+		{
+			name:  "channel and ts pair",
+			input: "C1234567890:1234567890.123456",
+			want:  messageRef{channel: "C1234567890", ts: "1234567890.123456"},
+		},
+		{name: "missing ts", input: "C1234567890", wantErr: true},
+		{name: "ts without fraction", input: "C1234567890:1234567890", wantErr: true},
+		{name: "broken permalink", input: "https://example.slack.com/archives/C1234567890", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ch.parseMessageRef(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestUnitUniqueMessages(t *testing.T) {
+	in := []slack.Message{
+		{Msg: slack.Msg{Timestamp: "1700000003.000000"}},
+		{Msg: slack.Msg{Timestamp: "1700000001.000000"}},
+		{Msg: slack.Msg{Timestamp: "1700000003.000000"}},
+		{Msg: slack.Msg{Timestamp: "1700000002.000000"}},
+		{Msg: slack.Msg{Timestamp: "1700000001.000000"}},
+	}
+
+	var got []string
+	for _, m := range uniqueMessages(in) {
+		got = append(got, m.Timestamp)
+	}
+
+	assert.Equal(t, []string{"1700000001.000000", "1700000002.000000", "1700000003.000000"}, got)
+}
+
+// historyAPI serves conversations.history like Slack: newest first between
+// the exclusive oldest and latest, in pages of at most params.Limit messages.
+type historyAPI struct {
+	provider.SlackAPI
+	messages []slack.Message // oldest first
+	calls    int
+}
+
+func (h *historyAPI) GetConversationHistoryContext(_ context.Context, params *slack.GetConversationHistoryParameters) (*slack.GetConversationHistoryResponse, error) {
+	h.calls++
+	var matching []slack.Message
+	for i := len(h.messages) - 1; i >= 0; i-- {
+		if ts := h.messages[i].Timestamp; ts > params.Oldest && (params.Latest == "" || ts < params.Latest) {
+			matching = append(matching, h.messages[i])
+		}
+	}
+	skip := 0
+	if params.Cursor != "" {
+		skip, _ = strconv.Atoi(params.Cursor)
+	}
+	end := min(len(matching), skip+params.Limit)
+	res := &slack.GetConversationHistoryResponse{Messages: matching[skip:end], HasMore: end < len(matching)}
+	if res.HasMore {
+		res.ResponseMetaData.NextCursor = strconv.Itoa(end)
+	}
+	return res, nil
+}
+
+func TestUnitFetchMessagesAfter(t *testing.T) {
+	api := &historyAPI{}
+	for i := range 450 {
+		api.messages = append(api.messages, slack.Message{Msg: slack.Msg{Timestamp: fmt.Sprintf("1700000%03d.000000", i)}})
+	}
+	ch := &ConversationsHandler{logger: zap.NewNop()}
+
+	got, err := ch.fetchMessagesAfter(context.Background(), api, "C1", "1700000010.000000", 3)
+	require.NoError(t, err)
+	var ts []string
+	for _, m := range got {
+		ts = append(ts, m.Timestamp)
+	}
+	assert.Equal(t, []string{"1700000011.000000", "1700000012.000000", "1700000013.000000"}, ts, "the messages right after the target, not the latest ones")
+	assert.Equal(t, 3, api.calls, "the span after the target shrinks to a page")
+
+	got, err = ch.fetchMessagesAfter(context.Background(), api, "C1", "1700000448.000000", 3)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "1700000449.000000", got[0].Timestamp)
+
+	// quiet channels: the span grows until it holds n messages
+	api.messages, api.calls = nil, 0
+	for i := range 5 {
+		api.messages = append(api.messages, slack.Message{Msg: slack.Msg{Timestamp: formatTs(time.Unix(1700000000, 0).Add(time.Duration(i) * 3 * time.Hour))}})
+	}
+	got, err = ch.fetchMessagesAfter(context.Background(), api, "C1", "1700000000.000000", 2)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, api.messages[1].Timestamp, got[0].Timestamp)
+	assert.Equal(t, api.messages[2].Timestamp, got[1].Timestamp)
+
+	// busy channels: the calls are capped, the window is partial
+	api.messages, api.calls = nil, 0
+	for i := range 2000 {
+		api.messages = append(api.messages, slack.Message{Msg: slack.Msg{Timestamp: fmt.Sprintf("1700000000.%06d", i)}})
+	}
+	_, err = ch.fetchMessagesAfter(context.Background(), api, "C1", "1700000000.000000", 3)
+	require.NoError(t, err)
+	assert.Equal(t, maxAfterPages, api.calls)
+}
+
+func TestUnitFetchBudget(t *testing.T) {
+	api := &historyAPI{}
+	for i := range 2000 {
+		api.messages = append(api.messages, slack.Message{Msg: slack.Msg{Timestamp: fmt.Sprintf("1700000000.%06d", i)}})
+	}
+	ch := &ConversationsHandler{logger: zap.NewNop()}
+	budget := &fetchBudget{left: 2}
+
+	_, err := ch.fetchMessagesAfter(withFetchBudget(context.Background(), budget), api, "C1", "1700000000.000000", 3)
+	assert.ErrorIs(t, err, errContextBudgetSpent)
+	assert.Equal(t, 2, api.calls, "every call is charged to the budget")
+	assert.Zero(t, budget.left)
+
+	_, err = ch.fetchMessagesAfter(context.Background(), api, "C1", "1700000000.000000", 3)
+	assert.NoError(t, err, "fetches outside of a search context are not charged")
+}
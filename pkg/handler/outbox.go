@@ -0,0 +1,240 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gocarina/gocsv"
+	"github.com/korotovsky/slack-mcp-server/pkg/audit"
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
+	"github.com/korotovsky/slack-mcp-server/pkg/outbox"
+	"github.com/korotovsky/slack-mcp-server/pkg/text"
+	"github.com/mark3labs/mcp-go/mcp"
+	"go.uber.org/zap"
+)
+
+var errOutboxDisabled = errors.New("the outbox is disabled, set SLACK_MCP_OUTBOX_DELAY to a delay such as '30s' to queue messages before they are posted")
+
+// OutboxMessage is a row of the outbox tools.
+type OutboxMessage struct {
+	ID           string `json:"outboxID"`
+	Workspace    string `json:"workspace,omitempty"`
+	Channel      string `json:"channelID"`
+	ThreadTs     string `json:"ThreadTs"`
+	Text         string `json:"text"`
+	SendAt       string `json:"sendAt"`
+	SendRelative string `json:"sendAtRelative"`
+	Status       string `json:"status"`
+	Error        string `json:"error,omitempty"`
+	MsgID        string `json:"msgID,omitempty"`
+}
+
+// newOutbox opens the outbox when SLACK_MCP_OUTBOX_DELAY is set.
+func newOutbox(cfg *config.Config, logger *zap.Logger) *outbox.Outbox {
+	if cfg.OutboxDelay <= 0 {
+		return nil
+	}
+
+	path := cfg.OutboxFile
+	o, err := outbox.New(path, cfg.OutboxDelay, logger)
+	if err != nil {
+		logger.Fatal("Failed to open outbox",
+			zap.String("outbox_file", path),
+			zap.Error(err),
+		)
+	}
+	logger.Info("Outbox enabled, messages are posted after a delay",
+		zap.String("context", "console"),
+		zap.Duration("delay", cfg.OutboxDelay),
+		zap.String("outbox_file", path),
+	)
+	return o
+}
+
+// RunOutbox posts queued messages once their delay has passed, until ctx is
+// done. It returns at once when the outbox is disabled.
+func (ch *ConversationsHandler) RunOutbox(ctx context.Context) {
+	if ch.outbox == nil {
+		return
+	}
+	ch.outbox.Run(audit.NewContext(ctx, ch.auditor), ch.sendOutboxItem)
+}
+
+func (ch *ConversationsHandler) enqueueMessage(params *addMessageParams) (*mcp.CallToolResult, error) {
+	// convert now, so conversion warnings are reported while the post can
+	// still be cancelled
+	_, warnings, err := ch.messageOptions(params)
+	if err != nil {
+		return nil, err
+	}
+
+	var workspace string
+	if len(ch.peers) > 1 {
+		workspace = ch.apiProvider.Workspace()
+	}
+	item, err := ch.outbox.Enqueue(outbox.Item{
+		Workspace:   workspace,
+		Channel:     params.channel,
+		ThreadTs:    params.threadTs,
+		Text:        params.text,
+		ContentType: params.contentType,
+	}, time.Now())
+	if err != nil {
+		ch.logger.Error("Failed to queue message", zap.Error(err))
+		return nil, err
+	}
+
+	ch.logger.Debug("Queued Slack message",
+		zap.String("id", item.ID),
+		zap.String("channel", item.Channel),
+		zap.Time("send_at", item.SendAt),
+	)
+
+	result, err := ch.marshalOutboxToCSV([]outbox.Item{item}, nil)
+	if err != nil {
+		return nil, err
+	}
+	result.Content = append(result.Content, mcp.NewTextContent(fmt.Sprintf(
+		"Message queued, it will be posted in %s unless cancelled with outbox_cancel using id %s.",
+		ch.outbox.Delay(), item.ID,
+	)))
+	return withWarnings(result, warnings), nil
+}
+
+// sendOutboxItem posts a queued message with the handler of its workspace.
+func (ch *ConversationsHandler) sendOutboxItem(ctx context.Context, item outbox.Item) (string, error) {
+	peer, err := ch.peer(item.Workspace)
+	if err != nil {
+		return "", err
+	}
+	return peer.postOutboxItem(ctx, item)
+}
+
+// postOutboxItem posts a queued message, the channel policy and content
+// filters are checked again as they may have changed since it was queued.
+func (ch *ConversationsHandler) postOutboxItem(ctx context.Context, item outbox.Item) (string, error) {
+	toolConfig := ch.config.Get().AddMessageTool
+	if toolConfig == "" || !isChannelAllowed(toolConfig, item.Channel) {
+		return "", fmt.Errorf("posting to channel %q is no longer allowed", item.Channel)
+	}
+
+	params := &addMessageParams{
+		channel:     item.Channel,
+		threadTs:    item.ThreadTs,
+		text:        item.Text,
+		contentType: item.ContentType,
+	}
+	text, _, err := ch.filterText(params)
+	if err != nil {
+		return "", err
+	}
+	params.text = text
+
+	_, ts, _, err := ch.postMessage(ctx, params)
+	return ts, err
+}
+
+// OutboxListHandler lists the queued messages as CSV
+func (ch *ConversationsHandler) OutboxListHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("OutboxListHandler called", zap.Any("params", request.Params))
+
+	if ch.outbox == nil {
+		return nil, errOutboxDisabled
+	}
+
+	return ch.marshalOutboxToCSV(ch.outbox.List(), nil)
+}
+
+// OutboxCancelHandler drops a queued message before it is posted
+func (ch *ConversationsHandler) OutboxCancelHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("OutboxCancelHandler called", zap.Any("params", request.Params))
+
+	if ch.outbox == nil {
+		return nil, errOutboxDisabled
+	}
+
+	id := strings.TrimSpace(request.GetString("id", ""))
+	if id == "" {
+		return nil, errors.New("id must be the outbox ID of a queued message")
+	}
+
+	item, err := ch.outbox.Cancel(id)
+	if err != nil {
+		ch.logger.Error("Failed to cancel queued message", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Queued message %s to %s cancelled, it will not be posted", item.ID, item.Channel)), nil
+}
+
+// OutboxFlushHandler posts queued messages right away and returns the outcome as CSV
+func (ch *ConversationsHandler) OutboxFlushHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("OutboxFlushHandler called", zap.Any("params", request.Params))
+
+	if ch.outbox == nil {
+		return nil, errOutboxDisabled
+	}
+
+	var ids []string
+	for _, id := range strings.Split(request.GetString("ids", ""), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+
+	results, err := ch.outbox.Flush(ctx, ch.sendOutboxItem, ids...)
+	if err != nil {
+		ch.logger.Error("Failed to flush outbox", zap.Error(err))
+		return nil, err
+	}
+
+	items := make([]outbox.Item, 0, len(results))
+	sent := make(map[string]string, len(results))
+	for _, r := range results {
+		items = append(items, r.Item)
+		if r.Err == nil {
+			sent[r.Item.ID] = r.Ts
+		}
+	}
+	return ch.marshalOutboxToCSV(items, sent)
+}
+
+// marshalOutboxToCSV renders outbox items, sent maps IDs of delivered
+// messages to their Slack timestamp.
+func (ch *ConversationsHandler) marshalOutboxToCSV(items []outbox.Item, sent map[string]string) (*mcp.CallToolResult, error) {
+	now := time.Now()
+
+	rows := make([]OutboxMessage, 0, len(items))
+	for _, it := range items {
+		loc := ch.apiProvider.Location()
+		if peer, err := ch.peer(it.Workspace); err == nil {
+			loc = peer.apiProvider.Location()
+		}
+		row := OutboxMessage{
+			ID:           it.ID,
+			Workspace:    it.Workspace,
+			Channel:      it.Channel,
+			ThreadTs:     it.ThreadTs,
+			Text:         it.Text,
+			SendAt:       it.SendAt.In(loc).Format(time.RFC3339),
+			SendRelative: text.HumanizeTimeSince(it.SendAt, now),
+			Status:       it.Status,
+			Error:        it.Error,
+		}
+		if ts, ok := sent[it.ID]; ok {
+			row.Status = "sent"
+			row.MsgID = ts
+		}
+		rows = append(rows, row)
+	}
+
+	csvBytes, err := gocsv.MarshalBytes(&rows)
+	if err != nil {
+		ch.logger.Error("Failed to marshal outbox to CSV", zap.Error(err))
+		return nil, err
+	}
+	return mcp.NewToolResultText(string(csvBytes)), nil
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/gocarina/gocsv"
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
+	"github.com/korotovsky/slack-mcp-server/pkg/limiter"
+	"github.com/mark3labs/mcp-go/mcp"
+	"go.uber.org/zap"
+)
+
+// QuotaStatus is a row of the quota_status tool.
+type QuotaStatus struct {
+	Scope     string `json:"scope"`
+	Key       string `json:"key"`
+	Limit     string `json:"limit"`
+	Remaining int    `json:"remaining"`
+	FullIn    string `json:"fullIn"`
+}
+
+type QuotaHandler struct {
+	quota  *limiter.Quota
+	logger *zap.Logger
+}
+
+// NewQuotaHandler sets up the write quotas of SLACK_MCP_WRITE_QUOTA_GLOBAL,
+// SLACK_MCP_WRITE_QUOTA_CHANNEL, SLACK_MCP_WRITE_QUOTA_THREAD and
+// SLACK_MCP_WRITE_DUPLICATE_WINDOW.
+func NewQuotaHandler(cfg *config.Config, logger *zap.Logger) *QuotaHandler {
+	quotaConfig := limiter.QuotaConfig{DuplicateWindow: cfg.WriteDuplicateWindow}
+	for _, q := range []struct {
+		env  string
+		raw  string
+		rate *limiter.QuotaRate
+	}{
+		{"SLACK_MCP_WRITE_QUOTA_GLOBAL", cfg.WriteQuotaGlobal, &quotaConfig.Global},
+		{"SLACK_MCP_WRITE_QUOTA_CHANNEL", cfg.WriteQuotaChannel, &quotaConfig.Channel},
+		{"SLACK_MCP_WRITE_QUOTA_THREAD", cfg.WriteQuotaThread, &quotaConfig.Thread},
+	} {
+		r, err := limiter.ParseQuotaRate(q.raw)
+		if err != nil {
+			logger.Fatal("Invalid "+q.env, zap.Error(err))
+		}
+		*q.rate = r
+	}
+
+	return &QuotaHandler{
+		quota:  limiter.NewQuota(quotaConfig),
+		logger: logger,
+	}
+}
+
+func (qh *QuotaHandler) Quota() *limiter.Quota {
+	return qh.quota
+}
+
+// Fresh returns a handler with the same quotas, none of them used, for a
+// tenant of a multi-tenant server.
+func (qh *QuotaHandler) Fresh() *QuotaHandler {
+	return &QuotaHandler{
+		quota:  limiter.NewQuota(qh.quota.Config()),
+		logger: qh.logger,
+	}
+}
+
+// QuotaStatusHandler returns the configured write quotas and the buckets in use as CSV
+func (qh *QuotaHandler) QuotaStatusHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	qh.logger.Debug("QuotaStatusHandler called", zap.Any("params", request.Params))
+
+	config := qh.quota.Config()
+	if !config.Enabled() {
+		return mcp.NewToolResultText("No write quotas are configured, set SLACK_MCP_WRITE_QUOTA_GLOBAL, SLACK_MCP_WRITE_QUOTA_CHANNEL or SLACK_MCP_WRITE_QUOTA_THREAD to enable them."), nil
+	}
+
+	var rows []QuotaStatus
+	for _, s := range qh.quota.Status(time.Now()) {
+		rows = append(rows, QuotaStatus{
+			Scope:     s.Scope,
+			Key:       s.Key,
+			Limit:     s.Limit,
+			Remaining: s.Remaining,
+			FullIn:    s.FullIn.String(),
+		})
+	}
+	// scopes without a bucket in use are untouched, report them as full
+	for _, s := range []struct {
+		scope string
+		rate  limiter.QuotaRate
+	}{
+		{limiter.ScopeChannel, config.Channel},
+		{limiter.ScopeThread, config.Thread},
+	} {
+		if s.rate.N > 0 {
+			rows = append(rows, QuotaStatus{Scope: s.scope, Key: "*", Limit: s.rate.String(), Remaining: s.rate.N, FullIn: "0s"})
+		}
+	}
+	if config.DuplicateWindow > 0 {
+		rows = append(rows, QuotaStatus{Scope: limiter.ScopeDuplicate, Key: "*", Limit: config.DuplicateWindow.String()})
+	}
+
+	csvBytes, err := gocsv.MarshalBytes(&rows)
+	if err != nil {
+		qh.logger.Error("Failed to marshal quota status to CSV", zap.Error(err))
+		return nil, err
+	}
+	return mcp.NewToolResultText(string(csvBytes)), nil
+}
@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gocarina/gocsv"
+	"github.com/korotovsky/slack-mcp-server/pkg/audit"
+	"github.com/korotovsky/slack-mcp-server/pkg/redact"
+	"github.com/korotovsky/slack-mcp-server/pkg/text"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// Reaction is a row of the reactions_get tool, one per reacting user.
+type Reaction struct {
+	MsgID    string `json:"msgID"`
+	Channel  string `json:"channelID"`
+	Emoji    string `json:"emoji"`
+	Name     string `json:"name"`
+	Count    int    `json:"count"`
+	UserID   string `json:"userID"`
+	UserName string `json:"userUser"`
+	RealName string `json:"realName"`
+}
+
+// ReactionsGetHandler returns every reaction of a single message with the users who added it, as CSV
+func (ch *ConversationsHandler) ReactionsGetHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("ReactionsGetHandler called", zap.Any("params", request.Params))
+
+	raw := strings.TrimSpace(request.GetString("message", ""))
+	if raw == "" {
+		ch.logger.Error("message missing in reactions_get params")
+		return nil, errors.New("message must be a permalink or a channel_id:ts pair")
+	}
+	ref, err := ch.parseMessageRef(raw)
+	if err != nil {
+		ch.logger.Error("Invalid message reference", zap.String("message", raw), zap.Error(err))
+		return nil, err
+	}
+	if err := checkReadable(ctx, ch.apiProvider, ref.channel); err != nil {
+		ch.logger.Warn("Channel hidden by read policy", zap.String("channel", ref.channel))
+		return nil, err
+	}
+	audit.SetChannel(ctx, ref.channel)
+
+	reactions, err := ch.fetchReactions(ctx, ref.channel, ref.ts)
+	if err != nil {
+		ch.logger.Error("Slack GetReactionsContext failed",
+			zap.String("channel", ref.channel),
+			zap.String("ts", ref.ts),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	usersMap := ch.apiProvider.ProvideUsersMap()
+	resolver := ch.textResolver()
+	rd := ch.apiProvider.Redactor()
+
+	var rows []Reaction
+	for _, r := range reactions {
+		for _, userID := range r.Users {
+			userName, realName, _ := getUserInfo(userID, usersMap.Users)
+			rows = append(rows, Reaction{
+				MsgID:    ref.ts,
+				Channel:  ref.channel,
+				Emoji:    text.RenderEmoji(r.Name, resolver),
+				Name:     r.Name,
+				Count:    r.Count,
+				UserID:   userID,
+				UserName: rd.UserName(userID, userName),
+				RealName: rd.RealName(userID, realName),
+			})
+		}
+	}
+
+	ch.logger.Debug("Fetched reactions", zap.Int("reactions", len(reactions)), zap.Int("rows", len(rows)))
+
+	csvBytes, err := gocsv.MarshalBytes(&rows)
+	if err != nil {
+		ch.logger.Error("Failed to marshal reactions to CSV", zap.Error(err))
+		return nil, err
+	}
+
+	return mcp.NewToolResultText(string(csvBytes)), nil
+}
+
+// fetchReactions returns the reactions of a message with the full list of users.
+func (ch *ConversationsHandler) fetchReactions(ctx context.Context, channel, ts string) ([]slack.ItemReaction, error) {
+	if err := ch.waitFetch(ctx); err != nil {
+		return nil, err
+	}
+	return ch.apiProvider.Slack().GetReactionsContext(ctx, slack.NewRefToMessage(channel, ts), slack.GetReactionsParameters{Full: true})
+}
+
+// formatReactions renders the Reactions column, e.g. "✅ 2|:shipit: 1", and
+// with users "✅ 2 (alice, bob)". Users Slack left out of a long list are
+// counted as "+N".
+func formatReactions(reactions []slack.ItemReaction, resolver text.Resolver, users map[string]slack.User, rd *redact.Redactor) string {
+	var parts []string
+	for _, r := range reactions {
+		part := fmt.Sprintf("%s %d", text.RenderEmoji(r.Name, resolver), r.Count)
+		if users != nil && len(r.Users) > 0 {
+			names := make([]string, 0, len(r.Users)+1)
+			for _, id := range r.Users {
+				name, _, ok := getUserInfo(id, users)
+				if !ok {
+					name = id
+				}
+				name = rd.UserName(id, name)
+				names = append(names, name)
+			}
+			if missing := r.Count - len(r.Users); missing > 0 {
+				names = append(names, fmt.Sprintf("+%d", missing))
+			}
+			part += " (" + strings.Join(names, ", ") + ")"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, "|")
+}
+
+// setReactionUsers rewrites the Reactions column of the rows with the users
+// who reacted, reactions are looked up by message ts.
+func (ch *ConversationsHandler) setReactionUsers(messages []Message, reactions map[string][]slack.ItemReaction) {
+	users := ch.apiProvider.ProvideUsersMap().Users
+	resolver := ch.textResolver()
+	rd := ch.apiProvider.Redactor()
+	for i := range messages {
+		if r, ok := reactions[messages[i].MsgID]; ok {
+			messages[i].Reactions = formatReactions(r, resolver, users, rd)
+		}
+	}
+}
+
+func reactionsByTs(messages []slack.Message) map[string][]slack.ItemReaction {
+	res := make(map[string][]slack.ItemReaction, len(messages))
+	for _, m := range messages {
+		if len(m.Reactions) > 0 {
+			res[m.Timestamp] = m.Reactions
+		}
+	}
+	return res
+}
+
+// fetchSearchReactions looks the reactions of search matches up one by one,
+// as search.messages does not return them.
+func (ch *ConversationsHandler) fetchSearchReactions(ctx context.Context, matches []slack.SearchMessage) (map[string][]slack.ItemReaction, error) {
+	res := make(map[string][]slack.ItemReaction, len(matches))
+	for _, m := range matches {
+		reactions, err := ch.fetchReactions(ctx, m.Channel.ID, m.Timestamp)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			ch.logger.Warn("Failed to fetch reactions of search match",
+				zap.String("channel", m.Channel.ID),
+				zap.String("ts", m.Timestamp),
+				zap.Error(err),
+			)
+			continue
+		}
+		if len(reactions) > 0 {
+			res[m.Timestamp] = reactions
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/text"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnitFormatReactions(t *testing.T) {
+	reactions := []slack.ItemReaction{
+		{Name: "white_check_mark", Count: 3, Users: []string{"U1", "U2"}},
+		{Name: "shipit", Count: 1, Users: []string{"U3"}},
+	}
+	users := map[string]slack.User{
+		"U1": {ID: "U1", Name: "alice"},
+		"U2": {ID: "U2", Name: "bob"},
+	}
+
+	assert.Equal(t, "✅ 3|:shipit: 1", formatReactions(reactions, text.Resolver{}, nil, nil))
+	assert.Equal(t, "✅ 3 (alice, bob, +1)|:shipit: 1 (U3)", formatReactions(reactions, text.Resolver{}, users, nil))
+	assert.Equal(t, "", formatReactions(nil, text.Resolver{}, users, nil))
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"strings"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/provider"
+	"github.com/korotovsky/slack-mcp-server/pkg/redact"
+	"github.com/slack-go/slack"
+)
+
+// redactChannel applies the redaction rules to a cached channel: topic and
+// purpose are text, DM names are made of user names and DM purposes of real
+// names.
+func redactChannel(rd *redact.Redactor, usersInv map[string]string, c provider.Channel) provider.Channel {
+	if rd == nil {
+		return c
+	}
+
+	c.Topic = rd.Text(c.Topic)
+	c.Purpose = rd.Text(c.Purpose)
+
+	switch {
+	case c.IsIM:
+		handle := strings.TrimPrefix(c.Name, "@")
+		userID := usersInv[handle]
+		c.Name = "@" + rd.UserName(userID, handle)
+		if realName, ok := strings.CutPrefix(c.Purpose, "DM with "); ok {
+			c.Purpose = "DM with " + rd.RealName(userID, realName)
+		}
+	case c.IsMpIM:
+		// mpdm-alice--bob--carol-1
+		name := strings.TrimPrefix(c.Name, "@mpdm-")
+		if i := strings.LastIndex(name, "-"); i > 0 {
+			name = name[:i]
+		}
+		handles := strings.Split(name, "--")
+		for i, h := range handles {
+			handles[i] = rd.UserName(usersInv[h], h)
+		}
+		c.Name = "@mpdm-" + strings.Join(handles, "--")
+		if rd.RedactsNames() {
+			c.Purpose = "Group DM"
+		}
+	}
+
+	return c
+}
+
+// redactChannelName applies the redaction rules to the name of a channel
+// that is not cached, such as a search result: DM and group DM names, which
+// start with @, are made of user names.
+func redactChannelName(rd *redact.Redactor, usersInv map[string]string, name string, isIM, isMpIM bool) string {
+	return redactChannel(rd, usersInv, provider.Channel{Name: name, IsIM: isIM, IsMpIM: isMpIM}).Name
+}
+
+// searchChannelName is the redacted name of the conversation of a search
+// match, named like the channels cache names it.
+func searchChannelName(rd *redact.Redactor, usersInv map[string]string, c slack.CtxChannel) string {
+	name := searchAccessChannel(c).Name
+	return redactChannelName(rd, usersInv, name, strings.HasPrefix(c.ID, "D"), c.IsMPIM)
+}
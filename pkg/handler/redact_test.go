@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/redact"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitSearchChannelName(t *testing.T) {
+	rd, err := redact.New(redact.Config{Rules: "user_name=redact"})
+	require.NoError(t, err)
+	usersInv := map[string]string{"alice": "U1", "bob": "U2"}
+
+	channel := func(id, name string, mpim bool) slack.CtxChannel {
+		return slack.CtxChannel{ID: id, Name: name, IsMPIM: mpim}
+	}
+	assert.Equal(t, "@mpdm-[REDACTED]--[REDACTED]", searchChannelName(rd, usersInv, channel("G1", "mpdm-alice--bob-1", true)))
+	assert.Equal(t, "@[REDACTED]", searchChannelName(rd, usersInv, channel("D1", "alice", false)))
+	assert.Equal(t, "#general", searchChannelName(rd, usersInv, channel("C1", "general", false)))
+
+	assert.Equal(t, "@mpdm-alice--bob-1", searchChannelName(nil, usersInv, channel("G1", "mpdm-alice--bob-1", true)), "names are kept without redaction")
+	assert.Equal(t, "@mpdm-[REDACTED]--[REDACTED]", redactChannelName(rd, usersInv, "@mpdm-alice--bob-1", false, true))
+}
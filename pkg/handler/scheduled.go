@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocarina/gocsv"
+	"github.com/korotovsky/slack-mcp-server/pkg/audit"
+	"github.com/korotovsky/slack-mcp-server/pkg/text"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// Slack rejects chat.scheduleMessage more than 120 days ahead.
+const maxScheduleAhead = 120 * 24 * time.Hour
+
+// ScheduledMessage is a row of the scheduled message tools.
+type ScheduledMessage struct {
+	ID           string `json:"scheduledMessageID"`
+	Channel      string `json:"channelID"`
+	PostAt       string `json:"postAt"`
+	PostRelative string `json:"postAtRelative"`
+	Text         string `json:"text"`
+	Cursor       string `json:"cursor"`
+}
+
+// MessagesScheduleHandler schedules a message with chat.scheduleMessage and returns it as CSV
+func (ch *ConversationsHandler) MessagesScheduleHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("MessagesScheduleHandler called", zap.Any("params", request.Params))
+
+	params, err := ch.parseParamsToolAddMessage(ctx, request)
+	if err != nil {
+		ch.logger.Error("Failed to parse schedule params", zap.Error(err))
+		return nil, err
+	}
+
+	postAtExpr := strings.TrimSpace(request.GetString("post_at", ""))
+	if postAtExpr == "" {
+		ch.logger.Error("post_at missing in schedule params")
+		return nil, errors.New("post_at must be a time, e.g. 'tomorrow 9am', 'in 2 hours' or '2025-07-01 14:30'")
+	}
+	now := time.Now()
+	postAt, err := parseFlexibleDateTime(postAtExpr, now, ch.apiProvider.Location())
+	if err != nil {
+		ch.logger.Error("Invalid post_at", zap.String("post_at", postAtExpr), zap.Error(err))
+		return nil, err
+	}
+	if !postAt.After(now) {
+		return nil, fmt.Errorf("post_at %q resolves to %s, which is not in the future", postAtExpr, postAt.Format(time.RFC3339))
+	}
+	if postAt.Sub(now) > maxScheduleAhead {
+		return nil, fmt.Errorf("post_at %q resolves to %s, Slack only schedules messages up to 120 days ahead", postAtExpr, postAt.Format(time.RFC3339))
+	}
+
+	options, warnings, err := ch.messageOptions(params)
+	if err != nil {
+		return nil, err
+	}
+
+	ch.logger.Debug("Scheduling Slack message",
+		zap.String("channel", params.channel),
+		zap.String("thread_ts", params.threadTs),
+		zap.Time("post_at", postAt),
+	)
+	respChannel, scheduledID, err := ch.apiProvider.Slack().ScheduleMessageContext(ctx, params.channel, strconv.FormatInt(postAt.Unix(), 10), options...)
+	audit.Write(ctx, "chat.scheduleMessage", params.channel, scheduledID, params.text, err)
+	if err != nil {
+		ch.logger.Error("Slack ScheduleMessageContext failed", zap.Error(err))
+		return nil, err
+	}
+
+	rows := []ScheduledMessage{{
+		ID:           scheduledID,
+		Channel:      respChannel,
+		PostAt:       postAt.In(ch.apiProvider.Location()).Format(time.RFC3339),
+		PostRelative: text.HumanizeTimeSince(postAt, now),
+		Text:         params.text,
+	}}
+	result, err := marshalScheduledToCSV(rows)
+	if err != nil {
+		return nil, err
+	}
+	return withWarnings(result, warnings), nil
+}
+
+// MessagesScheduledListHandler lists pending scheduled messages as CSV
+func (ch *ConversationsHandler) MessagesScheduledListHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("MessagesScheduledListHandler called", zap.Any("params", request.Params))
+
+	channel := request.GetString("channel_id", "")
+	if channel != "" {
+		id, err := ch.resolveChannelID(channel)
+		if err != nil {
+			ch.logger.Error("Channel not found", zap.String("channel", channel), zap.Error(err))
+			return nil, err
+		}
+		if err := checkReadable(ctx, ch.apiProvider, id); err != nil {
+			return nil, err
+		}
+		channel = id
+	}
+
+	limit := request.GetInt("limit", 100)
+	if limit < 1 || limit > 1000 {
+		return nil, errors.New("limit must be between 1 and 1000")
+	}
+
+	params := &slack.GetScheduledMessagesParameters{
+		Channel: channel,
+		Cursor:  request.GetString("cursor", ""),
+		Limit:   limit,
+	}
+	scheduled, nextCursor, err := ch.apiProvider.Slack().GetScheduledMessagesContext(ctx, params)
+	if err != nil {
+		ch.logger.Error("Slack GetScheduledMessagesContext failed", zap.Error(err))
+		return nil, err
+	}
+
+	loc := ch.apiProvider.Location()
+	now := time.Now()
+	resolver := ch.textResolver()
+	rows := make([]ScheduledMessage, 0, len(scheduled))
+	for _, m := range scheduled {
+		if !ch.apiProvider.ReadableChannel(ctx, m.Channel) {
+			continue
+		}
+		postAt := time.Unix(int64(m.PostAt), 0)
+		rows = append(rows, ScheduledMessage{
+			ID:           m.ID,
+			Channel:      m.Channel,
+			PostAt:       postAt.In(loc).Format(time.RFC3339),
+			PostRelative: text.HumanizeTimeSince(postAt, now),
+			Text:         ch.renderText(m.Text, slack.Blocks{}, nil, resolver),
+		})
+	}
+	if len(rows) > 0 && nextCursor != "" {
+		rows[len(rows)-1].Cursor = nextCursor
+	}
+
+	ch.logger.Debug("Fetched scheduled messages", zap.Int("count", len(rows)), zap.String("next_cursor", nextCursor))
+
+	return marshalScheduledToCSV(rows)
+}
+
+// MessagesScheduleCancelHandler deletes a pending scheduled message
+func (ch *ConversationsHandler) MessagesScheduleCancelHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("MessagesScheduleCancelHandler called", zap.Any("params", request.Params))
+
+	channel, err := ch.writableChannel(ctx, request.GetString("channel_id", ""))
+	if err != nil {
+		return nil, err
+	}
+
+	scheduledID := strings.TrimSpace(request.GetString("scheduled_message_id", ""))
+	if scheduledID == "" {
+		ch.logger.Error("scheduled_message_id missing in cancel params")
+		return nil, errors.New("scheduled_message_id must be a string, e.g. Q1298393284")
+	}
+
+	_, err = ch.apiProvider.Slack().DeleteScheduledMessageContext(ctx, &slack.DeleteScheduledMessageParameters{
+		Channel:            channel,
+		ScheduledMessageID: scheduledID,
+	})
+	audit.Write(ctx, "chat.deleteScheduledMessage", channel, scheduledID, "", err)
+	if err != nil {
+		ch.logger.Error("Slack DeleteScheduledMessageContext failed",
+			zap.String("channel", channel),
+			zap.String("scheduled_message_id", scheduledID),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Scheduled message %s in %s cancelled", scheduledID, channel)), nil
+}
+
+func marshalScheduledToCSV(rows []ScheduledMessage) (*mcp.CallToolResult, error) {
+	csvBytes, err := gocsv.MarshalBytes(&rows)
+	if err != nil {
+		return nil, err
+	}
+	return mcp.NewToolResultText(string(csvBytes)), nil
+}
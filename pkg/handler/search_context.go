@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+const (
+	maxSearchContextMessages   = 20
+	defaultSearchContextBudget = 200
+	maxSearchContextBudget     = 1000
+)
+
+// SearchContextMessage is a row of a search expanded with context, Match
+// tells the messages that matched the query apart from their surroundings.
+type SearchContextMessage struct {
+	Match bool `json:"match"`
+	Message
+}
+
+// errContextBudgetSpent stops the fetches of a search context once its
+// budget is spent.
+var errContextBudgetSpent = errors.New("search context budget spent")
+
+// fetchBudget is the budget of a search context, every Slack call and every
+// context message kept costs one unit.
+type fetchBudget struct {
+	left int
+}
+
+type fetchBudgetKey struct{}
+
+func withFetchBudget(ctx context.Context, b *fetchBudget) context.Context {
+	return context.WithValue(ctx, fetchBudgetKey{}, b)
+}
+
+// chargeFetch charges a Slack call to the budget of ctx, if any.
+func chargeFetch(ctx context.Context) error {
+	b, ok := ctx.Value(fetchBudgetKey{}).(*fetchBudget)
+	if !ok {
+		return nil
+	}
+	if b.left <= 0 {
+		return errContextBudgetSpent
+	}
+	b.left--
+	return nil
+}
+
+type searchContextGroup struct {
+	messages []slack.Message
+	seen     map[string]struct{}
+	matches  map[string]struct{}
+}
+
+// expandSearchContext fetches the neighbours or the thread of every match,
+// drops messages already covered by an earlier window and groups the rows by
+// conversation in order of first appearance. The Slack calls and the context
+// messages are charged to the budget, once it is spent the remaining matches
+// are returned on their own.
+func (ch *ConversationsHandler) expandSearchContext(ctx context.Context, matches []slack.SearchMessage, params *searchParams) ([]SearchContextMessage, error) {
+	var order []string
+	groups := make(map[string]*searchContextGroup)
+	budget := &fetchBudget{left: params.contextBudget}
+	fetchCtx := withFetchBudget(ctx, budget)
+
+	for _, match := range matches {
+		channel := match.Channel.ID
+		g, ok := groups[channel]
+		if !ok {
+			g = &searchContextGroup{
+				seen:    make(map[string]struct{}),
+				matches: make(map[string]struct{}),
+			}
+			groups[channel] = g
+			order = append(order, channel)
+		}
+		g.matches[match.Timestamp] = struct{}{}
+
+		if _, ok := g.seen[match.Timestamp]; ok {
+			continue
+		}
+
+		threadTs, _ := extractThreadTS(match.Permalink)
+
+		var window []slack.Message
+		if budget.left > 0 {
+			// the fetches are bounded by the budget left, the thread
+			// holds the match too
+			ref := messageRef{channel: channel, ts: match.Timestamp, threadTs: threadTs}
+			n := min(params.contextMessages, budget.left)
+			w, err := ch.fetchMessageWindow(fetchCtx, ref, n, n, params.contextThread, budget.left+1)
+			switch {
+			case ctx.Err() != nil:
+				return nil, ctx.Err()
+			case errors.Is(err, errContextBudgetSpent):
+				ch.logger.Debug("Search context budget spent, returning bare match",
+					zap.String("channel", channel),
+					zap.String("ts", match.Timestamp),
+				)
+			case err != nil:
+				ch.logger.Warn("Failed to fetch search match context, returning bare match",
+					zap.String("channel", channel),
+					zap.String("ts", match.Timestamp),
+					zap.Error(err),
+				)
+			}
+			window = w
+		}
+		if len(window) == 0 {
+			window = []slack.Message{searchMatchToMessage(match, threadTs)}
+		}
+
+		for _, m := range window {
+			if _, ok := g.seen[m.Timestamp]; ok {
+				continue
+			}
+			if m.Timestamp != match.Timestamp {
+				if budget.left <= 0 {
+					continue
+				}
+				budget.left--
+			}
+			g.seen[m.Timestamp] = struct{}{}
+			g.messages = append(g.messages, m)
+		}
+	}
+
+	ch.logger.Debug("Expanded search context",
+		zap.Int("matches", len(matches)),
+		zap.Int("conversations", len(order)),
+		zap.Int("budget_left", budget.left),
+	)
+
+	var rows []SearchContextMessage
+	for _, channel := range order {
+		g := groups[channel]
+		sortMessagesByTs(g.messages)
+		messages := ch.convertMessagesFromHistory(g.messages, channel, false)
+		if params.reactionUsers {
+			ch.setReactionUsers(messages, reactionsByTs(g.messages))
+		}
+		for _, m := range messages {
+			_, isMatch := g.matches[m.MsgID]
+			rows = append(rows, SearchContextMessage{Match: isMatch, Message: m})
+		}
+	}
+
+	return rows, nil
+}
+
+func searchMatchToMessage(match slack.SearchMessage, threadTs string) slack.Message {
+	return slack.Message{
+		Msg: slack.Msg{
+			Timestamp:       match.Timestamp,
+			ThreadTimestamp: threadTs,
+			User:            match.User,
+			Username:        match.Username,
+			Text:            match.Text,
+			Attachments:     match.Attachments,
+			Blocks:          match.Blocks,
+		},
+	}
+}
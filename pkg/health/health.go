@@ -0,0 +1,176 @@
+// Package health serves the liveness and readiness endpoints for
+// orchestrators such as Kubernetes.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/provider"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultAuthInterval = 5 * time.Minute
+	authTimeout         = 10 * time.Second
+)
+
+// optionalCaches do not make the server unready when their refresh fails.
+var optionalCaches = map[string]bool{"emoji": true}
+
+// Source is what the checks need from the API provider.
+type Source interface {
+	IsReady() (bool, error)
+	CheckAuth(ctx context.Context) error
+	SyncStatuses() []provider.SyncStatus
+}
+
+// Check is the result of one readiness check.
+type Check struct {
+	OK        bool       `json:"ok"`
+	Error     string     `json:"error,omitempty"`
+	CheckedAt *time.Time `json:"checked_at,omitempty"`
+}
+
+// Report is the body of /readyz.
+type Report struct {
+	Status string                `json:"status"`
+	Checks map[string]Check      `json:"checks"`
+	Syncs  []provider.SyncStatus `json:"syncs,omitempty"`
+}
+
+// Checker validates the Slack token periodically and answers the health
+// endpoints.
+type Checker struct {
+	source   Source
+	interval time.Duration
+	logger   *zap.Logger
+
+	mu       sync.RWMutex
+	auth     Check
+	authAt   time.Time
+	draining bool
+}
+
+func NewChecker(source Source, interval time.Duration, logger *zap.Logger) *Checker {
+	if interval <= 0 {
+		interval = DefaultAuthInterval
+	}
+	return &Checker{
+		source:   source,
+		interval: interval,
+		logger:   logger,
+		auth:     Check{Error: "not checked yet"},
+	}
+}
+
+// Run checks the Slack token right away and then every interval until ctx
+// is done.
+func (c *Checker) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		c.checkAuth(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Checker) checkAuth(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, authTimeout)
+	defer cancel()
+
+	err := c.source.CheckAuth(ctx)
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.authAt = now
+	c.auth = Check{OK: err == nil}
+	if err != nil {
+		c.auth.Error = err.Error()
+		c.logger.Warn("Slack auth check failed", zap.Error(err))
+	}
+}
+
+// Drain makes the server unready, so that no new traffic is routed to it
+// while it shuts down.
+func (c *Checker) Drain() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.draining = true
+}
+
+// Report runs the readiness checks.
+func (c *Checker) Report() Report {
+	r := Report{Status: "ready", Checks: make(map[string]Check)}
+
+	caches := Check{OK: true}
+	if ready, err := c.source.IsReady(); !ready {
+		caches = Check{Error: "caches are not ready"}
+		if err != nil {
+			caches.Error = err.Error()
+		}
+	}
+	r.Checks["caches"] = caches
+
+	c.mu.RLock()
+	auth := c.auth
+	if !c.authAt.IsZero() {
+		at := c.authAt
+		auth.CheckedAt = &at
+	}
+	draining := c.draining
+	c.mu.RUnlock()
+	r.Checks["slack_auth"] = auth
+
+	if draining {
+		r.Checks["shutdown"] = Check{Error: "server is shutting down"}
+	}
+
+	sync := Check{OK: true}
+	r.Syncs = c.source.SyncStatuses()
+	for _, s := range r.Syncs {
+		if s.Error != "" && !optionalCaches[s.Cache] {
+			sync = Check{Error: s.Cache + ": " + s.Error}
+			break
+		}
+	}
+	r.Checks["sync"] = sync
+
+	for _, check := range r.Checks {
+		if !check.OK {
+			r.Status = "not_ready"
+		}
+	}
+	return r
+}
+
+// Healthz answers 200 as long as the process serves HTTP.
+func (c *Checker) Healthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Readyz answers 200 when the server is ready and 503 otherwise, with the
+// Report as JSON either way.
+func (c *Checker) Readyz(w http.ResponseWriter, r *http.Request) {
+	report := c.Report()
+	code := http.StatusOK
+	if report.Status != "ready" {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, report)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,87 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type fakeSource struct {
+	ready   bool
+	authErr error
+	syncs   []provider.SyncStatus
+}
+
+func (f *fakeSource) IsReady() (bool, error) {
+	if !f.ready {
+		return false, errors.New("users cache is not ready yet")
+	}
+	return true, nil
+}
+
+func (f *fakeSource) CheckAuth(ctx context.Context) error {
+	return f.authErr
+}
+
+func (f *fakeSource) SyncStatuses() []provider.SyncStatus {
+	return f.syncs
+}
+
+func readyz(t *testing.T, c *Checker) (int, Report) {
+	rec := httptest.NewRecorder()
+	c.Readyz(rec, httptest.NewRequest("GET", "/readyz", nil))
+	var report Report
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&report))
+	return rec.Code, report
+}
+
+func TestUnitReadyz(t *testing.T) {
+	src := &fakeSource{}
+	c := NewChecker(src, time.Minute, zap.NewNop())
+
+	code, report := readyz(t, c)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "users cache is not ready yet", report.Checks["caches"].Error)
+	assert.Equal(t, "not checked yet", report.Checks["slack_auth"].Error)
+
+	src.ready = true
+	src.syncs = []provider.SyncStatus{
+		{Cache: "channels", At: time.Now()},
+		{Cache: "emoji", At: time.Now(), Error: "ratelimited"},
+	}
+	c.checkAuth(context.Background())
+
+	code, report = readyz(t, c)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ready", report.Status)
+	assert.NotNil(t, report.Checks["slack_auth"].CheckedAt)
+	assert.Len(t, report.Syncs, 2)
+
+	src.authErr = errors.New("invalid_auth")
+	src.syncs[0].Error = "fatal_error"
+	c.checkAuth(context.Background())
+
+	code, report = readyz(t, c)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "not_ready", report.Status)
+	assert.Equal(t, "invalid_auth", report.Checks["slack_auth"].Error)
+	assert.Equal(t, "channels: fatal_error", report.Checks["sync"].Error)
+	assert.True(t, report.Checks["caches"].OK)
+}
+
+func TestUnitHealthz(t *testing.T) {
+	c := NewChecker(&fakeSource{}, 0, zap.NewNop())
+	rec := httptest.NewRecorder()
+	c.Healthz(rec, httptest.NewRequest("GET", "/healthz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, rec.Body.String())
+}
@@ -23,4 +23,7 @@ var (
 	Tier2boost = tier{t: 300 * time.Millisecond, b: 5}
 	Tier3      = tier{t: 1200 * time.Millisecond, b: 4}
 	// tier4      = tier{t: 60 * time.Millisecond, b: 5}
+
+	// chat.postMessage is special, about one message per second per channel
+	Post = tier{t: 1 * time.Second, b: 1}
 )
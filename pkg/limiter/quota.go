@@ -0,0 +1,326 @@
+package limiter
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	ScopeGlobal    = "global"
+	ScopeChannel   = "channel"
+	ScopeThread    = "thread"
+	ScopeDuplicate = "duplicate"
+)
+
+// QuotaRate is a number of writes allowed per period, e.g. 30/h.
+type QuotaRate struct {
+	N      int
+	Period time.Duration
+
+	spec string
+}
+
+func (r QuotaRate) String() string {
+	if r.spec != "" {
+		return r.spec
+	}
+	return fmt.Sprintf("%d/%s", r.N, r.Period)
+}
+
+// ParseQuotaRate parses "N/period" where period is a Go duration or a bare
+// unit, e.g. "30/h", "10/5m" or "100/d". An empty string means no quota.
+func ParseQuotaRate(s string) (QuotaRate, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return QuotaRate{}, nil
+	}
+
+	n, period, ok := strings.Cut(s, "/")
+	if !ok {
+		return QuotaRate{}, fmt.Errorf("invalid quota %q, expected N/period e.g. 30/h", s)
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(n))
+	if err != nil || count < 1 {
+		return QuotaRate{}, fmt.Errorf("invalid quota %q, N must be a positive integer", s)
+	}
+
+	period = strings.TrimSpace(period)
+	if period != "" && (period[0] < '0' || period[0] > '9') {
+		period = "1" + period
+	}
+	var d time.Duration
+	if days, found := strings.CutSuffix(period, "d"); found {
+		nd, convErr := strconv.Atoi(days)
+		d, err = time.Duration(nd)*24*time.Hour, convErr
+	} else {
+		d, err = time.ParseDuration(period)
+	}
+	if err != nil || d <= 0 {
+		return QuotaRate{}, fmt.Errorf("invalid quota %q, period must be a duration e.g. h, 5m or 1d", s)
+	}
+
+	return QuotaRate{N: count, Period: d, spec: s}, nil
+}
+
+// QuotaConfig configures the write quotas, zero rates disable a scope.
+type QuotaConfig struct {
+	Global  QuotaRate
+	Channel QuotaRate
+	Thread  QuotaRate
+	// DuplicateWindow rejects the same text posted to the same conversation
+	// again within the window.
+	DuplicateWindow time.Duration
+}
+
+// Enabled reports whether any quota is configured.
+func (c QuotaConfig) Enabled() bool {
+	return c.Global.N > 0 || c.Channel.N > 0 || c.Thread.N > 0 || c.DuplicateWindow > 0
+}
+
+// QuotaError is returned when a write exceeds a quota.
+type QuotaError struct {
+	Scope      string  `json:"scope"`
+	Key        string  `json:"key,omitempty"`
+	Limit      string  `json:"limit,omitempty"`
+	RetryAfter float64 `json:"retry_after_seconds"`
+}
+
+func (e *QuotaError) Error() string {
+	retry := time.Duration(e.RetryAfter * float64(time.Second)).Round(time.Second)
+	if e.Scope == ScopeDuplicate {
+		return fmt.Sprintf("write quota exceeded: the same message was already posted to %s, retry after %s if it is intended", e.Key, retry)
+	}
+	target := e.Scope
+	if e.Key != "" {
+		target += " " + e.Key
+	}
+	return fmt.Sprintf("write quota exceeded for %s (%s), retry after %s", target, e.Limit, retry)
+}
+
+// QuotaStatus describes the state of a quota bucket.
+type QuotaStatus struct {
+	Scope     string
+	Key       string
+	Limit     string
+	Remaining int
+	// FullIn is the time until the bucket is full again.
+	FullIn time.Duration
+}
+
+// Quota enforces write quotas with token buckets per conversation, per
+// thread and globally.
+type Quota struct {
+	mu     sync.Mutex
+	config QuotaConfig
+
+	global   *rate.Limiter
+	channels map[string]*rate.Limiter
+	threads  map[string]*rate.Limiter
+	recent   map[[sha256.Size]byte]time.Time
+	// pending counts the reserved writes of a bucket, they are charged on
+	// commit.
+	pending map[*rate.Limiter]int
+}
+
+func NewQuota(config QuotaConfig) *Quota {
+	q := &Quota{
+		config:   config,
+		channels: make(map[string]*rate.Limiter),
+		threads:  make(map[string]*rate.Limiter),
+		recent:   make(map[[sha256.Size]byte]time.Time),
+		pending:  make(map[*rate.Limiter]int),
+	}
+	if config.Global.N > 0 {
+		q.global = newBucket(config.Global)
+	}
+	return q
+}
+
+func (q *Quota) Config() QuotaConfig {
+	return q.config
+}
+
+// Allow charges a write to channel, and to threadTs when it is a reply. No
+// bucket is charged when any of them is exhausted or the text is a duplicate.
+func (q *Quota) Allow(channel, threadTs, text string, now time.Time) error {
+	r, err := q.Reserve(channel, threadTs, text, now)
+	if err != nil {
+		return err
+	}
+	r.Commit(now)
+	return nil
+}
+
+// Reservation holds a token of each quota bucket of a write that is not done
+// yet. Commit charges the buckets, Cancel releases them.
+type Reservation struct {
+	q       *Quota
+	digest  *[sha256.Size]byte
+	buckets []*rate.Limiter
+}
+
+// Reserve checks a write like Allow and holds its tokens, so that concurrent
+// writes cannot exceed the quotas. Nothing is charged nor remembered for the
+// duplicate check until the reservation is committed.
+func (q *Quota) Reserve(channel, threadTs, text string, now time.Time) (*Reservation, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.pruneLocked(now)
+
+	res := &Reservation{q: q}
+	if q.config.DuplicateWindow > 0 {
+		digest := sha256.Sum256([]byte(channel + "\x00" + threadTs + "\x00" + strings.TrimSpace(text)))
+		if at, ok := q.recent[digest]; ok {
+			return nil, &QuotaError{
+				Scope:      ScopeDuplicate,
+				Key:        channel,
+				RetryAfter: at.Add(q.config.DuplicateWindow).Sub(now).Seconds(),
+			}
+		}
+		res.digest = &digest
+	}
+
+	type charge struct {
+		scope, key string
+		rate       QuotaRate
+		bucket     *rate.Limiter
+	}
+	var charges []charge
+	if q.global != nil {
+		charges = append(charges, charge{ScopeGlobal, "", q.config.Global, q.global})
+	}
+	if q.config.Channel.N > 0 {
+		charges = append(charges, charge{ScopeChannel, channel, q.config.Channel, bucketFor(q.channels, channel, q.config.Channel)})
+	}
+	if q.config.Thread.N > 0 && threadTs != "" {
+		key := channel + ":" + threadTs
+		charges = append(charges, charge{ScopeThread, key, q.config.Thread, bucketFor(q.threads, key, q.config.Thread)})
+	}
+
+	for _, c := range charges {
+		if tokens := c.bucket.TokensAt(now) - float64(q.pending[c.bucket]); tokens < 1 {
+			return nil, &QuotaError{
+				Scope:      c.scope,
+				Key:        c.key,
+				Limit:      c.rate.String(),
+				RetryAfter: math.Ceil((1-tokens)/float64(c.bucket.Limit())*10) / 10,
+			}
+		}
+	}
+	for _, c := range charges {
+		q.pending[c.bucket]++
+		res.buckets = append(res.buckets, c.bucket)
+	}
+	return res, nil
+}
+
+// Commit charges the reserved write and remembers it for the duplicate
+// check.
+func (r *Reservation) Commit(now time.Time) {
+	r.q.mu.Lock()
+	defer r.q.mu.Unlock()
+	r.releaseLocked()
+	for _, b := range r.buckets {
+		b.AllowN(now, 1)
+	}
+	if r.digest != nil {
+		r.q.recent[*r.digest] = now
+	}
+}
+
+// Cancel releases the reserved tokens when the write did not happen.
+func (r *Reservation) Cancel() {
+	r.q.mu.Lock()
+	defer r.q.mu.Unlock()
+	r.releaseLocked()
+}
+
+func (r *Reservation) releaseLocked() {
+	for _, b := range r.buckets {
+		if r.q.pending[b]--; r.q.pending[b] <= 0 {
+			delete(r.q.pending, b)
+		}
+	}
+}
+
+// Status returns the buckets in use, global first.
+func (q *Quota) Status(now time.Time) []QuotaStatus {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.pruneLocked(now)
+
+	var res []QuotaStatus
+	if q.global != nil {
+		res = append(res, bucketStatus(ScopeGlobal, "", q.config.Global, q.global, now))
+	}
+	for _, scope := range []struct {
+		name    string
+		rate    QuotaRate
+		buckets map[string]*rate.Limiter
+	}{
+		{ScopeChannel, q.config.Channel, q.channels},
+		{ScopeThread, q.config.Thread, q.threads},
+	} {
+		keys := make([]string, 0, len(scope.buckets))
+		for k := range scope.buckets {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			res = append(res, bucketStatus(scope.name, k, scope.rate, scope.buckets[k], now))
+		}
+	}
+	return res
+}
+
+// pruneLocked forgets full buckets and expired duplicates, a new bucket
+// starts full anyway.
+func (q *Quota) pruneLocked(now time.Time) {
+	for _, buckets := range []map[string]*rate.Limiter{q.channels, q.threads} {
+		for k, b := range buckets {
+			if b.TokensAt(now) >= float64(b.Burst()) && q.pending[b] == 0 {
+				delete(buckets, k)
+			}
+		}
+	}
+	for k, at := range q.recent {
+		if now.Sub(at) >= q.config.DuplicateWindow {
+			delete(q.recent, k)
+		}
+	}
+}
+
+func newBucket(r QuotaRate) *rate.Limiter {
+	return rate.NewLimiter(rate.Every(r.Period/time.Duration(r.N)), r.N)
+}
+
+func bucketFor(buckets map[string]*rate.Limiter, key string, r QuotaRate) *rate.Limiter {
+	b, ok := buckets[key]
+	if !ok {
+		b = newBucket(r)
+		buckets[key] = b
+	}
+	return b
+}
+
+func bucketStatus(scope, key string, r QuotaRate, b *rate.Limiter, now time.Time) QuotaStatus {
+	tokens := b.TokensAt(now)
+	missing := float64(b.Burst()) - tokens
+	return QuotaStatus{
+		Scope:     scope,
+		Key:       key,
+		Limit:     r.String(),
+		Remaining: int(math.Floor(tokens)),
+		FullIn:    time.Duration(missing / float64(b.Limit()) * float64(time.Second)).Round(time.Second),
+	}
+}
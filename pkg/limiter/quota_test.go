@@ -0,0 +1,126 @@
+package limiter
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitParseQuotaRate(t *testing.T) {
+	tests := []struct {
+		in      string
+		n       int
+		period  time.Duration
+		wantErr bool
+	}{
+		{in: "", n: 0},
+		{in: "30/h", n: 30, period: time.Hour},
+		{in: "10/5m", n: 10, period: 5 * time.Minute},
+		{in: "100/d", n: 100, period: 24 * time.Hour},
+		{in: "3/2d", n: 3, period: 48 * time.Hour},
+		{in: "30", wantErr: true},
+		{in: "0/h", wantErr: true},
+		{in: "5/fortnight", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			r, err := ParseQuotaRate(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.n, r.N)
+			assert.Equal(t, tt.period, r.Period)
+		})
+	}
+}
+
+func TestUnitQuotaAllow(t *testing.T) {
+	channel, _ := ParseQuotaRate("2/m")
+	thread, _ := ParseQuotaRate("1/m")
+	global, _ := ParseQuotaRate("4/h")
+	q := NewQuota(QuotaConfig{Global: global, Channel: channel, Thread: thread})
+	now := time.Date(2025, 7, 16, 10, 0, 0, 0, time.UTC)
+
+	require.NoError(t, q.Allow("C1", "", "a", now))
+	require.NoError(t, q.Allow("C1", "1.1", "b", now))
+
+	var qe *QuotaError
+	err := q.Allow("C1", "", "c", now)
+	require.True(t, errors.As(err, &qe))
+	assert.Equal(t, ScopeChannel, qe.Scope)
+	assert.Equal(t, "C1", qe.Key)
+	assert.Equal(t, "2/m", qe.Limit)
+	assert.InDelta(t, 30, qe.RetryAfter, 0.1)
+
+	err = q.Allow("C2", "1.1", "d", now)
+	require.NoError(t, err)
+	err = q.Allow("C2", "1.1", "e", now)
+	require.True(t, errors.As(err, &qe))
+	assert.Equal(t, ScopeThread, qe.Scope)
+	assert.Equal(t, "C2:1.1", qe.Key)
+
+	// the rejected calls charged nothing, 3 of 4 global writes are used
+	require.NoError(t, q.Allow("C3", "", "f", now))
+	err = q.Allow("C4", "", "g", now)
+	require.True(t, errors.As(err, &qe))
+	assert.Equal(t, ScopeGlobal, qe.Scope)
+
+	status := q.Status(now)
+	require.NotEmpty(t, status)
+	assert.Equal(t, ScopeGlobal, status[0].Scope)
+	assert.Equal(t, 0, status[0].Remaining)
+	assert.Equal(t, time.Hour, status[0].FullIn)
+
+	// buckets refill, full ones are forgotten
+	later := now.Add(time.Hour)
+	require.NoError(t, q.Allow("C1", "", "h", later))
+	assert.Len(t, q.Status(later), 2)
+}
+
+func TestUnitQuotaDuplicates(t *testing.T) {
+	q := NewQuota(QuotaConfig{DuplicateWindow: 10 * time.Minute})
+	now := time.Date(2025, 7, 16, 10, 0, 0, 0, time.UTC)
+
+	require.NoError(t, q.Allow("C1", "", "deploy done", now))
+	require.NoError(t, q.Allow("C2", "", "deploy done", now))
+	require.NoError(t, q.Allow("C1", "1.1", "deploy done", now))
+
+	var qe *QuotaError
+	err := q.Allow("C1", "", " deploy done\n", now.Add(time.Minute))
+	require.True(t, errors.As(err, &qe))
+	assert.Equal(t, ScopeDuplicate, qe.Scope)
+	assert.InDelta(t, 540, qe.RetryAfter, 0.1)
+
+	require.NoError(t, q.Allow("C1", "", "deploy done", now.Add(10*time.Minute)))
+}
+
+func TestUnitQuotaReserve(t *testing.T) {
+	channel, _ := ParseQuotaRate("1/h")
+	q := NewQuota(QuotaConfig{Channel: channel, DuplicateWindow: time.Hour})
+	now := time.Date(2025, 7, 16, 10, 0, 0, 0, time.UTC)
+
+	r, err := q.Reserve("C1", "", "a", now)
+	require.NoError(t, err)
+	_, err = q.Reserve("C1", "", "b", now)
+	assert.Error(t, err, "a pending write holds its token")
+
+	r.Cancel()
+	r, err = q.Reserve("C1", "", "a", now)
+	require.NoError(t, err, "a cancelled write charged nothing")
+	_, err = q.Reserve("C2", "", "a", now)
+	require.NoError(t, err, "the text is remembered on commit only")
+
+	r.Commit(now)
+	var qe *QuotaError
+	_, err = q.Reserve("C1", "", "a", now)
+	require.True(t, errors.As(err, &qe))
+	assert.Equal(t, ScopeDuplicate, qe.Scope)
+	_, err = q.Reserve("C1", "", "b", now)
+	require.True(t, errors.As(err, &qe))
+	assert.Equal(t, ScopeChannel, qe.Scope)
+}
@@ -0,0 +1,150 @@
+// Package metrics exposes Prometheus metrics of tool calls, Slack API calls,
+// rate limiting and the caches.
+package metrics
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "slack_mcp"
+
+var (
+	registry = prometheus.NewRegistry()
+
+	toolCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tool_calls_total",
+		Help:      "Tool calls by tool and status (ok or error).",
+	}, []string{"tool", "status"})
+
+	toolDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "tool_call_duration_seconds",
+		Help:      "Duration of tool calls by tool.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"tool"})
+
+	slackRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "slack_api_requests_total",
+		Help:      "Slack API requests by method and HTTP status, status is 'error' when no response was received.",
+	}, []string{"method", "status"})
+
+	slackDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "slack_api_request_duration_seconds",
+		Help:      "Duration of Slack API requests by method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	rateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "slack_rate_limited_total",
+		Help:      "Responses with HTTP 429 from Slack by client.",
+	}, []string{"client"})
+
+	rateLimitWait = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "slack_rate_limit_wait_seconds_total",
+		Help:      "Time spent waiting for Slack's Retry-After by client.",
+	}, []string{"client"})
+
+	tenants = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "tenants",
+		Help:      "Tenants with a Slack client in multi-tenant mode.",
+	})
+
+	tenantEvictions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tenant_evictions_total",
+		Help:      "Tenants dropped in multi-tenant mode by reason (idle or capacity).",
+	}, []string{"reason"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		toolCalls,
+		toolDuration,
+		slackRequests,
+		slackDuration,
+		rateLimited,
+		rateLimitWait,
+		tenants,
+		tenantEvictions,
+	)
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+}
+
+// ObserveToolCall records a finished tool call.
+func ObserveToolCall(tool string, d time.Duration, failed bool) {
+	status := "ok"
+	if failed {
+		status = "error"
+	}
+	toolCalls.WithLabelValues(tool, status).Inc()
+	toolDuration.WithLabelValues(tool).Observe(d.Seconds())
+}
+
+// ObserveSlackRequest records a Slack API request, status is 0 when no
+// response was received.
+func ObserveSlackRequest(path string, status int, d time.Duration) {
+	method := MethodFromPath(path)
+	label := "error"
+	if status > 0 {
+		label = strconv.Itoa(status)
+	}
+	slackRequests.WithLabelValues(method, label).Inc()
+	slackDuration.WithLabelValues(method).Observe(d.Seconds())
+}
+
+// ObserveRateLimit records a 429 from Slack and the Retry-After waited for,
+// zero when the client gave up instead of waiting.
+func ObserveRateLimit(client string, wait time.Duration) {
+	rateLimited.WithLabelValues(client).Inc()
+	if wait > 0 {
+		rateLimitWait.WithLabelValues(client).Add(wait.Seconds())
+	}
+}
+
+// SetTenants records the number of tenants with a Slack client.
+func SetTenants(n int) {
+	tenants.Set(float64(n))
+}
+
+// ObserveTenantEviction records a tenant dropped for reason.
+func ObserveTenantEviction(reason string) {
+	tenantEvictions.WithLabelValues(reason).Inc()
+}
+
+var idSegmentRe = regexp.MustCompile(`^[A-Z][A-Z0-9]{8,}$`)
+
+// MethodFromPath turns a request path into a method label: Web API paths
+// give the method name, e.g. conversations.history, other paths drop the
+// team and user IDs in them to keep the number of labels small.
+func MethodFromPath(path string) string {
+	if _, method, ok := strings.Cut(path, "/api/"); ok {
+		return method
+	}
+	var parts []string
+	for _, p := range strings.Split(strings.Trim(path, "/"), "/") {
+		if p != "" && !idSegmentRe.MatchString(p) {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitMethodFromPath(t *testing.T) {
+	assert.Equal(t, "conversations.history", MethodFromPath("/api/conversations.history"))
+	assert.Equal(t, "cache/users/list", MethodFromPath("/cache/T0123456789/users/list"))
+	assert.Equal(t, "cache/users/info", MethodFromPath("/cache/E0123456789/T0123456789/users/info"))
+	assert.Equal(t, "", MethodFromPath("/"))
+}
+
+type fakeSource struct{}
+
+func (fakeSource) IsReady() (bool, error) {
+	return true, nil
+}
+
+func (fakeSource) CacheStats() []CacheStat {
+	return []CacheStat{
+		{Name: "users", Size: 42, Ready: true, UpdatedAt: time.Now().Add(-time.Minute)},
+		{Name: "emoji", Size: 0},
+	}
+}
+
+func TestUnitHandler(t *testing.T) {
+	require.NoError(t, RegisterSource(fakeSource{}))
+
+	ObserveToolCall("channels_list", 120*time.Millisecond, false)
+	ObserveToolCall("channels_list", time.Second, true)
+	ObserveSlackRequest("/api/conversations.history", 200, 50*time.Millisecond)
+	ObserveSlackRequest("/api/search.messages", 0, time.Second)
+	ObserveRateLimit("edge", 3*time.Second)
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	out := string(body)
+
+	assert.Contains(t, out, `slack_mcp_tool_calls_total{status="ok",tool="channels_list"} 1`)
+	assert.Contains(t, out, `slack_mcp_tool_calls_total{status="error",tool="channels_list"} 1`)
+	assert.Contains(t, out, `slack_mcp_tool_call_duration_seconds_count{tool="channels_list"} 2`)
+	assert.Contains(t, out, `slack_mcp_slack_api_requests_total{method="conversations.history",status="200"} 1`)
+	assert.Contains(t, out, `slack_mcp_slack_api_requests_total{method="search.messages",status="error"} 1`)
+	assert.Contains(t, out, `slack_mcp_slack_rate_limited_total{client="edge"} 1`)
+	assert.Contains(t, out, `slack_mcp_slack_rate_limit_wait_seconds_total{client="edge"} 3`)
+	assert.Contains(t, out, `slack_mcp_ready 1`)
+	assert.Contains(t, out, `slack_mcp_cache_entries{cache="users"} 42`)
+	assert.Contains(t, out, `slack_mcp_cache_ready{cache="emoji"} 0`)
+	assert.Contains(t, out, `slack_mcp_cache_age_seconds{cache="users"}`)
+	assert.NotContains(t, out, `slack_mcp_cache_age_seconds{cache="emoji"}`)
+}
+
+func TestUnitWorkspaceSource(t *testing.T) {
+	// a registry of its own, the global one has the unlabelled source
+	reg := prometheus.NewPedanticRegistry()
+	require.NoError(t, reg.Register(&sourceCollector{source: fakeSource{}, descs: newSourceDescs(prometheus.Labels{"workspace": "acme"})}))
+	require.NoError(t, reg.Register(&sourceCollector{source: fakeSource{}, descs: newSourceDescs(prometheus.Labels{"workspace": "beta"})}))
+
+	count, err := testutil.GatherAndCount(reg, "slack_mcp_cache_entries")
+	require.NoError(t, err)
+	assert.Equal(t, 4, count)
+
+	err = testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP slack_mcp_ready Whether the users and channels caches are loaded and the server is ready.
+# TYPE slack_mcp_ready gauge
+slack_mcp_ready{workspace="acme"} 1
+slack_mcp_ready{workspace="beta"} 1
+`), "slack_mcp_ready")
+	assert.NoError(t, err)
+}
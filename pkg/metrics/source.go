@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// CacheStat describes one of the provider caches.
+type CacheStat struct {
+	Name      string
+	Size      int
+	Ready     bool
+	UpdatedAt time.Time
+}
+
+// Source is what the metrics need from the API provider.
+type Source interface {
+	IsReady() (bool, error)
+	CacheStats() []CacheStat
+}
+
+type sourceDescs struct {
+	ready, cacheReady, cacheEntries, cacheAge *prometheus.Desc
+}
+
+func newSourceDescs(labels prometheus.Labels) sourceDescs {
+	return sourceDescs{
+		ready: prometheus.NewDesc(namespace+"_ready",
+			"Whether the users and channels caches are loaded and the server is ready.", nil, labels),
+		cacheReady: prometheus.NewDesc(namespace+"_cache_ready",
+			"Whether a cache is loaded.", []string{"cache"}, labels),
+		cacheEntries: prometheus.NewDesc(namespace+"_cache_entries",
+			"Number of entries in a cache.", []string{"cache"}, labels),
+		cacheAge: prometheus.NewDesc(namespace+"_cache_age_seconds",
+			"Time since a cache was last fetched from Slack.", []string{"cache"}, labels),
+	}
+}
+
+type sourceCollector struct {
+	source Source
+	descs  sourceDescs
+}
+
+// RegisterSource adds the readiness and cache metrics of source.
+func RegisterSource(source Source) error {
+	return registry.Register(&sourceCollector{source: source, descs: newSourceDescs(nil)})
+}
+
+// RegisterWorkspaceSource adds the readiness and cache metrics of the source
+// of a workspace, labelled with its name. A server with several workspaces
+// registers each of them this way.
+func RegisterWorkspaceSource(workspace string, source Source) error {
+	return registry.Register(&sourceCollector{
+		source: source,
+		descs:  newSourceDescs(prometheus.Labels{"workspace": workspace}),
+	})
+}
+
+func (c *sourceCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.descs.ready
+	ch <- c.descs.cacheReady
+	ch <- c.descs.cacheEntries
+	ch <- c.descs.cacheAge
+}
+
+func (c *sourceCollector) Collect(ch chan<- prometheus.Metric) {
+	ready, _ := c.source.IsReady()
+	ch <- prometheus.MustNewConstMetric(c.descs.ready, prometheus.GaugeValue, boolValue(ready))
+
+	now := time.Now()
+	for _, s := range c.source.CacheStats() {
+		ch <- prometheus.MustNewConstMetric(c.descs.cacheReady, prometheus.GaugeValue, boolValue(s.Ready), s.Name)
+		ch <- prometheus.MustNewConstMetric(c.descs.cacheEntries, prometheus.GaugeValue, float64(s.Size), s.Name)
+		if !s.UpdatedAt.IsZero() {
+			ch <- prometheus.MustNewConstMetric(c.descs.cacheAge, prometheus.GaugeValue, now.Sub(s.UpdatedAt).Seconds(), s.Name)
+		}
+	}
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
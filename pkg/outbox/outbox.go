@@ -0,0 +1,334 @@
+// Package outbox queues outgoing messages for a delay, so a post can still be
+// cancelled before it reaches Slack. The queue is kept in a JSON file to
+// survive restarts.
+package outbox
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/limiter"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+const (
+	StatusPending = "pending"
+	StatusSending = "sending"
+	StatusFailed  = "failed"
+)
+
+// ErrNotFound is returned for IDs that are not queued, e.g. already sent.
+var ErrNotFound = errors.New("message not found in outbox, it may have been sent already")
+
+// Item is a queued message.
+type Item struct {
+	ID string `json:"id"`
+	// Workspace is the workspace to post to, empty when the server serves a
+	// single one.
+	Workspace   string    `json:"workspace,omitempty"`
+	Channel     string    `json:"channel"`
+	ThreadTs    string    `json:"thread_ts,omitempty"`
+	Text        string    `json:"text"`
+	ContentType string    `json:"content_type"`
+	CreatedAt   time.Time `json:"created_at"`
+	SendAt      time.Time `json:"send_at"`
+	Status      string    `json:"status"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// SendFunc delivers a message, it returns the timestamp of the posted message.
+type SendFunc func(ctx context.Context, item Item) (string, error)
+
+// Result is the outcome of a delivery attempt.
+type Result struct {
+	Item Item
+	Ts   string
+	Err  error
+}
+
+// Outbox is a persistent queue of delayed messages.
+type Outbox struct {
+	mu    sync.Mutex
+	items map[string]*Item
+	path  string
+	delay time.Duration
+
+	limiter *rate.Limiter
+	logger  *zap.Logger
+}
+
+// New loads the outbox stored at path. Messages that were being sent when
+// the server stopped are marked as failed, as it is unknown whether Slack
+// received them.
+func New(path string, delay time.Duration, logger *zap.Logger) (*Outbox, error) {
+	o := &Outbox{
+		items:   make(map[string]*Item),
+		path:    path,
+		delay:   delay,
+		limiter: limiter.Post.Limiter(),
+		logger:  logger,
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read outbox file: %w", err)
+	}
+	if len(data) > 0 {
+		var items []*Item
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal outbox file %s: %w", path, err)
+		}
+		for _, it := range items {
+			if it.Status == StatusSending {
+				it.Status = StatusFailed
+				it.Error = "interrupted while sending, check the channel before retrying"
+			}
+			o.items[it.ID] = it
+		}
+		logger.Info("Loaded outbox from file",
+			zap.String("outbox_file", path),
+			zap.Int("count", len(items)))
+	}
+
+	return o, nil
+}
+
+// Delay is the time messages wait in the outbox before they are sent.
+func (o *Outbox) Delay() time.Duration {
+	return o.delay
+}
+
+// Enqueue queues a message to be sent after the outbox delay.
+func (o *Outbox) Enqueue(item Item, now time.Time) (Item, error) {
+	id, err := newID()
+	if err != nil {
+		return Item{}, err
+	}
+	item.ID = id
+	item.CreatedAt = now
+	item.SendAt = now.Add(o.delay)
+	item.Status = StatusPending
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.items[id] = &item
+	if err := o.saveLocked(); err != nil {
+		delete(o.items, id)
+		return Item{}, err
+	}
+	return item, nil
+}
+
+// List returns the queued messages ordered by send time.
+func (o *Outbox) List() []Item {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	items := make([]Item, 0, len(o.items))
+	for _, it := range o.items {
+		items = append(items, *it)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].SendAt.Equal(items[j].SendAt) {
+			return items[i].ID < items[j].ID
+		}
+		return items[i].SendAt.Before(items[j].SendAt)
+	})
+	return items
+}
+
+// Cancel drops a message that has not been sent yet.
+func (o *Outbox) Cancel(id string) (Item, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	it, ok := o.items[id]
+	if !ok {
+		return Item{}, ErrNotFound
+	}
+	if it.Status == StatusSending {
+		return Item{}, fmt.Errorf("message %s is being sent and can no longer be cancelled", id)
+	}
+	delete(o.items, id)
+	if err := o.saveLocked(); err != nil {
+		o.items[id] = it
+		return Item{}, err
+	}
+	return *it, nil
+}
+
+// Flush sends the given messages right away, or every pending and failed
+// message when no IDs are given.
+func (o *Outbox) Flush(ctx context.Context, send SendFunc, ids ...string) ([]Result, error) {
+	o.mu.Lock()
+	if len(ids) == 0 {
+		for id, it := range o.items {
+			if it.Status != StatusSending {
+				ids = append(ids, id)
+			}
+		}
+	}
+	for _, id := range ids {
+		if _, ok := o.items[id]; !ok {
+			o.mu.Unlock()
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+	}
+	claimed := o.claimLocked(func(it *Item) bool {
+		for _, id := range ids {
+			if it.ID == id {
+				return it.Status != StatusSending
+			}
+		}
+		return false
+	})
+	o.mu.Unlock()
+
+	return o.deliver(ctx, send, claimed), nil
+}
+
+// Run sends due messages until ctx is done.
+func (o *Outbox) Run(ctx context.Context, send SendFunc) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			o.mu.Lock()
+			due := o.claimLocked(func(it *Item) bool {
+				return it.Status == StatusPending && !it.SendAt.After(now)
+			})
+			o.mu.Unlock()
+
+			if len(due) > 0 {
+				o.deliver(ctx, send, due)
+			}
+		}
+	}
+}
+
+// claimLocked marks the matching messages as being sent, so that neither a
+// flush nor the worker sends them twice.
+func (o *Outbox) claimLocked(match func(*Item) bool) []Item {
+	var claimed []Item
+	for _, it := range o.items {
+		if match(it) {
+			it.Status = StatusSending
+			it.Error = ""
+			claimed = append(claimed, *it)
+		}
+	}
+	if len(claimed) == 0 {
+		return nil
+	}
+	sort.Slice(claimed, func(i, j int) bool {
+		return claimed[i].SendAt.Before(claimed[j].SendAt)
+	})
+	if err := o.saveLocked(); err != nil {
+		o.logger.Error("Failed to persist outbox", zap.Error(err))
+	}
+	return claimed
+}
+
+func (o *Outbox) deliver(ctx context.Context, send SendFunc, items []Item) []Result {
+	results := make([]Result, 0, len(items))
+	for _, it := range items {
+		if err := o.limiter.Wait(ctx); err != nil {
+			// not sent yet, e.g. on shutdown, keep it for the next run
+			o.mu.Lock()
+			if stored, ok := o.items[it.ID]; ok {
+				stored.Status = StatusPending
+				it = *stored
+			}
+			if err := o.saveLocked(); err != nil {
+				o.logger.Error("Failed to persist outbox", zap.Error(err))
+			}
+			o.mu.Unlock()
+			results = append(results, Result{Item: it, Err: err})
+			continue
+		}
+		// a post that has started is completed even when ctx is cancelled,
+		// as it is unknown whether Slack got it otherwise
+		ts, err := send(context.WithoutCancel(ctx), it)
+
+		o.mu.Lock()
+		if err != nil {
+			o.logger.Error("Failed to send outbox message",
+				zap.String("id", it.ID),
+				zap.String("channel", it.Channel),
+				zap.Error(err),
+			)
+			if stored, ok := o.items[it.ID]; ok {
+				stored.Status = StatusFailed
+				stored.Error = err.Error()
+				it = *stored
+			}
+		} else {
+			o.logger.Debug("Sent outbox message",
+				zap.String("id", it.ID),
+				zap.String("channel", it.Channel),
+				zap.String("ts", ts),
+			)
+			delete(o.items, it.ID)
+		}
+		if err := o.saveLocked(); err != nil {
+			o.logger.Error("Failed to persist outbox", zap.Error(err))
+		}
+		o.mu.Unlock()
+
+		results = append(results, Result{Item: it, Ts: ts, Err: err})
+	}
+	return results
+}
+
+// saveLocked writes the outbox through a temporary file, so a crash never
+// leaves a truncated queue behind.
+func (o *Outbox) saveLocked() error {
+	items := make([]*Item, 0, len(o.items))
+	for _, it := range o.items {
+		items = append(items, it)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+
+	data, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal outbox: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(o.path), filepath.Base(o.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write outbox file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write outbox file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write outbox file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), o.path); err != nil {
+		return fmt.Errorf("failed to write outbox file: %w", err)
+	}
+	return nil
+}
+
+func newID() (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate outbox id: %w", err)
+	}
+	return "OB" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,113 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestUnitOutboxPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.json")
+	now := time.Date(2025, 7, 16, 10, 0, 0, 0, time.UTC)
+
+	o, err := New(path, 30*time.Second, zap.NewNop())
+	require.NoError(t, err)
+
+	first, err := o.Enqueue(Item{Channel: "C1", Text: "first", ContentType: "text/plain"}, now)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, first.Status)
+	assert.Equal(t, now.Add(30*time.Second), first.SendAt)
+
+	second, err := o.Enqueue(Item{Channel: "C2", Text: "second", ContentType: "text/plain"}, now.Add(time.Second))
+	require.NoError(t, err)
+
+	_, err = o.Cancel(first.ID)
+	require.NoError(t, err)
+	_, err = o.Cancel(first.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	reloaded, err := New(path, 30*time.Second, zap.NewNop())
+	require.NoError(t, err)
+	items := reloaded.List()
+	require.Len(t, items, 1)
+	assert.Equal(t, second.ID, items[0].ID)
+	assert.Equal(t, "second", items[0].Text)
+}
+
+func TestUnitOutboxInterruptedSend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.json")
+	data := `[{"id":"OB1","channel":"C1","text":"hi","content_type":"text/plain","status":"sending"}]`
+	require.NoError(t, os.WriteFile(path, []byte(data), 0644))
+
+	o, err := New(path, time.Second, zap.NewNop())
+	require.NoError(t, err)
+
+	items := o.List()
+	require.Len(t, items, 1)
+	assert.Equal(t, StatusFailed, items[0].Status)
+	assert.NotEmpty(t, items[0].Error)
+}
+
+func TestUnitOutboxFlush(t *testing.T) {
+	o, err := New(filepath.Join(t.TempDir(), "outbox.json"), time.Hour, zap.NewNop())
+	require.NoError(t, err)
+
+	now := time.Now()
+	ok, err := o.Enqueue(Item{Channel: "C1", Text: "ok"}, now)
+	require.NoError(t, err)
+	bad, err := o.Enqueue(Item{Channel: "C2", Text: "bad"}, now)
+	require.NoError(t, err)
+
+	var sent []string
+	send := func(_ context.Context, it Item) (string, error) {
+		if it.Channel == "C2" {
+			return "", errors.New("channel_not_found")
+		}
+		sent = append(sent, it.ID)
+		return "1752660000.000100", nil
+	}
+
+	_, err = o.Flush(context.Background(), send, "OBmissing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	results, err := o.Flush(context.Background(), send)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, []string{ok.ID}, sent)
+
+	items := o.List()
+	require.Len(t, items, 1)
+	assert.Equal(t, bad.ID, items[0].ID)
+	assert.Equal(t, StatusFailed, items[0].Status)
+	assert.Equal(t, "channel_not_found", items[0].Error)
+}
+
+func TestUnitOutboxFlushCancelled(t *testing.T) {
+	o, err := New(filepath.Join(t.TempDir(), "outbox.json"), time.Hour, zap.NewNop())
+	require.NoError(t, err)
+	item, err := o.Enqueue(Item{Channel: "C1", Text: "later"}, time.Now())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err := o.Flush(ctx, func(context.Context, Item) (string, error) {
+		t.Fatal("nothing is sent once ctx is cancelled")
+		return "", nil
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.ErrorIs(t, results[0].Err, context.Canceled)
+
+	// kept for the next start instead of failed
+	items := o.List()
+	require.Len(t, items, 1)
+	assert.Equal(t, item.ID, items[0].ID)
+	assert.Equal(t, StatusPending, items[0].Status)
+}
@@ -2,14 +2,26 @@ package provider
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/korotovsky/slack-mcp-server/pkg/access"
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
 	"github.com/korotovsky/slack-mcp-server/pkg/limiter"
+	"github.com/korotovsky/slack-mcp-server/pkg/metrics"
 	"github.com/korotovsky/slack-mcp-server/pkg/provider/edge"
+	"github.com/korotovsky/slack-mcp-server/pkg/redact"
+	"github.com/korotovsky/slack-mcp-server/pkg/text"
 	"github.com/korotovsky/slack-mcp-server/pkg/transport"
 	"github.com/rusq/slackdump/v3/auth"
 	"github.com/slack-go/slack"
@@ -56,6 +68,9 @@ type SlackAPI interface {
 	GetUsersContext(ctx context.Context, options ...slack.GetUsersOption) ([]slack.User, error)
 	GetUsersInfo(users ...string) (*[]slack.User, error)
 	PostMessageContext(ctx context.Context, channel string, options ...slack.MsgOption) (string, string, error)
+	ScheduleMessageContext(ctx context.Context, channelID, postAt string, options ...slack.MsgOption) (string, string, error)
+	GetScheduledMessagesContext(ctx context.Context, params *slack.GetScheduledMessagesParameters) ([]slack.ScheduledMessage, string, error)
+	DeleteScheduledMessageContext(ctx context.Context, params *slack.DeleteScheduledMessageParameters) (bool, error)
 	MarkConversationContext(ctx context.Context, channel, ts string) error
 
 	// Used to get messages
@@ -66,6 +81,18 @@ type SlackAPI interface {
 	// Used to get channels list from both Slack and Enterprise Grid versions
 	GetConversationsContext(ctx context.Context, params *slack.GetConversationsParameters) ([]slack.Channel, string, error)
 
+	// Used to check uncached channels against the read policy
+	GetConversationInfoContext(ctx context.Context, input *slack.GetConversationInfoInput) (*slack.Channel, error)
+
+	// Used to get reactions with the complete list of reacting users
+	GetReactionsContext(ctx context.Context, item slack.ItemRef, params slack.GetReactionsParameters) ([]slack.ItemReaction, error)
+
+	// Used to resolve custom emoji
+	GetEmojiContext(ctx context.Context) (map[string]string, error)
+
+	// Used to discover channels, archived and non-member ones included
+	SearchChannelsContext(ctx context.Context, query, cursor string, limit int) ([]slack.Channel, string, error)
+
 	// Edge API methods
 	ClientUserBoot(ctx context.Context) (*edge.ClientUserBootResponse, error)
 }
@@ -80,15 +107,33 @@ type MCPSlackClient struct {
 	isEnterprise bool
 	isOAuth      bool
 	teamEndpoint string
+
+	// listLimiter throttles the conversations.list calls of the channel
+	// search, it is the rate limiter of the provider's channels refresh
+	listLimiter *rate.Limiter
 }
 
 type ApiProvider struct {
 	transport string
+	demo      bool
 	client    SlackAPI
 	logger    *zap.Logger
 
+	// workspace names the workspace in resource URIs and tool arguments.
+	workspace string
+
 	rateLimiter *rate.Limiter
 
+	// location is the time zone used to render timestamps, nil means
+	// the authenticated user's time zone from the users cache.
+	location *time.Location
+
+	// redactor removes personal data from what is read, nil when disabled.
+	redactor *redact.Redactor
+	// readPolicy hides conversations from every read, it holds nil when
+	// disabled.
+	readPolicy atomic.Pointer[access.Policy]
+
 	users      map[string]slack.User
 	usersInv   map[string]string
 	usersCache string
@@ -98,10 +143,17 @@ type ApiProvider struct {
 	channelsInv   map[string]string
 	channelsCache string
 	channelsReady bool
+
+	emojiMu    sync.RWMutex
+	emoji      map[string]string
+	emojiCache string
+
+	syncMu sync.Mutex
+	syncs  map[string]SyncStatus
 }
 
-func NewMCPSlackClient(authProvider auth.Provider, logger *zap.Logger) (*MCPSlackClient, error) {
-	httpClient := transport.ProvideHTTPClient(authProvider.Cookies(), logger)
+func NewMCPSlackClient(cfg *config.Config, authProvider auth.Provider, logger *zap.Logger) (*MCPSlackClient, error) {
+	httpClient := transport.ProvideHTTPClient(cfg, authProvider.Cookies(), logger)
 
 	slackClient := slack.New(authProvider.SlackToken(),
 		slack.OptionHTTPClient(httpClient),
@@ -148,7 +200,8 @@ func NewMCPSlackClient(authProvider auth.Provider, logger *zap.Logger) (*MCPSlac
 }
 
 func (c *MCPSlackClient) AuthTest() (*slack.AuthTestResponse, error) {
-	if os.Getenv("SLACK_MCP_XOXP_TOKEN") == "demo" || (os.Getenv("SLACK_MCP_XOXC_TOKEN") == "demo" && os.Getenv("SLACK_MCP_XOXD_TOKEN") == "demo") {
+	// there is no client with demo credentials
+	if c == nil {
 		return &slack.AuthTestResponse{
 			URL:          "https://_.slack.com",
 			Team:         "Demo Team",
@@ -244,6 +297,10 @@ func (c *MCPSlackClient) GetConversationHistoryContext(ctx context.Context, para
 	return c.slackClient.GetConversationHistoryContext(ctx, params)
 }
 
+func (c *MCPSlackClient) GetConversationInfoContext(ctx context.Context, input *slack.GetConversationInfoInput) (*slack.Channel, error) {
+	return c.slackClient.GetConversationInfoContext(ctx, input)
+}
+
 func (c *MCPSlackClient) GetConversationRepliesContext(ctx context.Context, params *slack.GetConversationRepliesParameters) (msgs []slack.Message, hasMore bool, nextCursor string, err error) {
 	return c.slackClient.GetConversationRepliesContext(ctx, params)
 }
@@ -252,10 +309,173 @@ func (c *MCPSlackClient) SearchContext(ctx context.Context, query string, params
 	return c.slackClient.SearchContext(ctx, query, params)
 }
 
+func (c *MCPSlackClient) GetReactionsContext(ctx context.Context, item slack.ItemRef, params slack.GetReactionsParameters) ([]slack.ItemReaction, error) {
+	return c.slackClient.GetReactionsContext(ctx, item, params)
+}
+
+func (c *MCPSlackClient) GetEmojiContext(ctx context.Context) (map[string]string, error) {
+	return c.slackClient.GetEmojiContext(ctx)
+}
+
 func (c *MCPSlackClient) PostMessageContext(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
 	return c.slackClient.PostMessageContext(ctx, channelID, options...)
 }
 
+func (c *MCPSlackClient) ScheduleMessageContext(ctx context.Context, channelID, postAt string, options ...slack.MsgOption) (string, string, error) {
+	return c.slackClient.ScheduleMessageContext(ctx, channelID, postAt, options...)
+}
+
+func (c *MCPSlackClient) GetScheduledMessagesContext(ctx context.Context, params *slack.GetScheduledMessagesParameters) ([]slack.ScheduledMessage, string, error) {
+	return c.slackClient.GetScheduledMessagesContext(ctx, params)
+}
+
+func (c *MCPSlackClient) DeleteScheduledMessageContext(ctx context.Context, params *slack.DeleteScheduledMessageParameters) (bool, error) {
+	return c.slackClient.DeleteScheduledMessageContext(ctx, params)
+}
+
+// SearchChannelsContext searches channels by name, topic or purpose. Browser
+// tokens use the channel browser search, which also covers archived channels
+// and channels the user has not joined. OAuth tokens can't call it, so the
+// whole conversations.list is scanned and matched locally instead.
+func (c *MCPSlackClient) SearchChannelsContext(ctx context.Context, query, cursor string, limit int) ([]slack.Channel, string, error) {
+	if c.isOAuth {
+		return c.searchChannelsFallback(ctx, query, cursor, limit)
+	}
+
+	edgeChannels, next, err := c.edgeClient.SearchChannelsPage(ctx, query, cursor, limit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	channels := make([]slack.Channel, 0, len(edgeChannels))
+	for _, ec := range edgeChannels {
+		channels = append(channels, slack.Channel{
+			IsChannel: ec.IsChannel,
+			IsGeneral: ec.IsGeneral,
+			IsMember:  ec.IsMember,
+			GroupConversation: slack.GroupConversation{
+				Conversation: slack.Conversation{
+					ID:             ec.ID,
+					IsPrivate:      ec.IsPrivate,
+					NameNormalized: ec.NameNormalized,
+					IsShared:       ec.IsShared,
+					IsExtShared:    ec.IsExtShared,
+					IsOrgShared:    ec.IsOrgShared,
+					NumMembers:     ec.NumMembers,
+				},
+				Name:       ec.Name,
+				IsArchived: ec.IsArchived,
+				Topic: slack.Topic{
+					Value: ec.Topic.Value,
+				},
+				Purpose: slack.Purpose{
+					Value: ec.Purpose.Value,
+				},
+			},
+		})
+	}
+
+	return channels, next, nil
+}
+
+// maxSearchChannelsPages caps the conversations.list pages scanned by a
+// channel search without browser tokens, the search resumes from the cursor.
+const maxSearchChannelsPages = 5
+
+// searchChannelsFallback pages through conversations.list and keeps the
+// channels matching the query. The returned cursor is the conversations.list
+// cursor of the page being read plus the number of matches already returned
+// from it, so no match is lost when a page holds more than limit of them.
+// At most maxSearchChannelsPages pages are read per call.
+func (c *MCPSlackClient) searchChannelsFallback(ctx context.Context, query, cursor string, limit int) ([]slack.Channel, string, error) {
+	pageCursor, skip, err := decodeSearchCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	params := &slack.GetConversationsParameters{
+		Types:           []string{PubChanType, PrivateChanType},
+		Limit:           999,
+		ExcludeArchived: false,
+		Cursor:          pageCursor,
+	}
+	query = strings.ToLower(strings.TrimSpace(query))
+	lim := c.listLimiter
+	if lim == nil {
+		lim = limiter.Tier2.Limiter()
+	}
+
+	var found []slack.Channel
+	for page := 1; ; page++ {
+		if err := lim.Wait(ctx); err != nil {
+			return nil, "", err
+		}
+		channels, next, err := c.slackClient.GetConversationsContext(ctx, params)
+		if err != nil {
+			return nil, "", err
+		}
+
+		matched := 0
+		for _, channel := range channels {
+			if !channelMatches(channel, query) {
+				continue
+			}
+			matched++
+			if matched <= skip {
+				continue
+			}
+			found = append(found, channel)
+			if len(found) == limit {
+				return found, encodeSearchCursor(params.Cursor, matched), nil
+			}
+		}
+
+		if next == "" {
+			return found, "", nil
+		}
+		if page == maxSearchChannelsPages {
+			return found, encodeSearchCursor(next, 0), nil
+		}
+		params.Cursor = next
+		skip = 0
+	}
+}
+
+func channelMatches(channel slack.Channel, query string) bool {
+	if query == "" {
+		return true
+	}
+	for _, field := range []string{channel.Name, channel.Topic.Value, channel.Purpose.Value} {
+		if strings.Contains(strings.ToLower(field), query) {
+			return true
+		}
+	}
+	return false
+}
+
+func encodeSearchCursor(pageCursor string, skip int) string {
+	return base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%s", skip, pageCursor)))
+}
+
+func decodeSearchCursor(cursor string) (string, int, error) {
+	if cursor == "" {
+		return "", 0, nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid cursor: %v", err)
+	}
+	skip, pageCursor, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return "", 0, errors.New("invalid cursor")
+	}
+	n, err := strconv.Atoi(skip)
+	if err != nil || n < 0 {
+		return "", 0, errors.New("invalid cursor")
+	}
+	return pageCursor, n, nil
+}
+
 func (c *MCPSlackClient) ClientUserBoot(ctx context.Context) (*edge.ClientUserBootResponse, error) {
 	return c.edgeClient.ClientUserBoot(ctx)
 }
@@ -264,6 +484,12 @@ func (c *MCPSlackClient) IsEnterprise() bool {
 	return c.isEnterprise
 }
 
+// IsOAuth reports whether the client uses a User OAuth token rather than
+// browser session tokens.
+func (c *MCPSlackClient) IsOAuth() bool {
+	return c.isOAuth
+}
+
 func (c *MCPSlackClient) AuthResponse() *slack.AuthTestResponse {
 	return c.authResponse
 }
@@ -281,113 +507,133 @@ func (c *MCPSlackClient) Raw() struct {
 	}
 }
 
-func New(transport string, logger *zap.Logger) *ApiProvider {
-	var (
-		authProvider auth.ValueAuth
-		err          error
-	)
-
-	// Check for XOXP token first (User OAuth)
-	xoxpToken := os.Getenv("SLACK_MCP_XOXP_TOKEN")
-	if xoxpToken != "" {
-		authProvider, err = auth.NewValueAuth(xoxpToken, "")
-		if err != nil {
-			logger.Fatal("Failed to create auth provider with XOXP token", zap.Error(err))
-		}
+func New(cfg *config.Config, logger *zap.Logger) *ApiProvider {
+	ap, err := Open(cfg, logger)
+	if err != nil {
+		logger.Fatal("Failed to create API provider", zap.Error(err))
+	}
+	return ap
+}
 
-		return newWithXOXP(transport, authProvider, logger)
+// Open is New for tokens that are not part of the server configuration, it
+// returns an error instead of exiting when the tokens are rejected.
+func Open(cfg *config.Config, logger *zap.Logger) (*ApiProvider, error) {
+	authProvider, err := newAuthProvider(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create auth provider: %w", err)
 	}
 
-	// Fall back to XOXC/XOXD tokens (session-based)
-	xoxcToken := os.Getenv("SLACK_MCP_XOXC_TOKEN")
-	xoxdToken := os.Getenv("SLACK_MCP_XOXD_TOKEN")
+	return newWithAuth(cfg, authProvider, logger)
+}
 
-	if xoxcToken == "" || xoxdToken == "" {
-		logger.Fatal("Authentication required: Either SLACK_MCP_XOXP_TOKEN (User OAuth) or both SLACK_MCP_XOXC_TOKEN and SLACK_MCP_XOXD_TOKEN (session-based) environment variables must be provided")
+// Connect authenticates with the tokens of cfg and returns the Slack client,
+// without loading any cache.
+func Connect(cfg *config.Config, logger *zap.Logger) (*MCPSlackClient, error) {
+	authProvider, err := newAuthProvider(cfg)
+	if err != nil {
+		return nil, err
 	}
+	return NewMCPSlackClient(cfg, authProvider, logger)
+}
 
-	authProvider, err = auth.NewValueAuth(xoxcToken, xoxdToken)
-	if err != nil {
-		logger.Fatal("Failed to create auth provider with XOXC/XOXD tokens", zap.Error(err))
+func newAuthProvider(cfg *config.Config) (auth.ValueAuth, error) {
+	// Check for XOXP token first (User OAuth)
+	if cfg.XOXPToken != "" {
+		return auth.NewValueAuth(cfg.XOXPToken, "")
 	}
 
-	return newWithXOXC(transport, authProvider, logger)
+	// Fall back to XOXC/XOXD tokens (session-based)
+	if cfg.XOXCToken == "" || cfg.XOXDToken == "" {
+		return auth.ValueAuth{}, errors.New("authentication required: either SLACK_MCP_XOXP_TOKEN (User OAuth) or both SLACK_MCP_XOXC_TOKEN and SLACK_MCP_XOXD_TOKEN (session-based) must be provided")
+	}
+	return auth.NewValueAuth(cfg.XOXCToken, cfg.XOXDToken)
 }
 
-func newWithXOXP(transport string, authProvider auth.ValueAuth, logger *zap.Logger) *ApiProvider {
-	var (
-		client *MCPSlackClient
-		err    error
-	)
+// CacheFile is a cache kept on disk between runs.
+type CacheFile struct {
+	Name string
+	Path string
+}
 
-	usersCache := os.Getenv("SLACK_MCP_USERS_CACHE")
-	if usersCache == "" {
-		usersCache = ".users_cache.json"
+// CacheFiles returns the users, channels and emoji cache files of cfg. The
+// channels cache of browser session tokens has its own default, as it holds
+// more conversations than the one of OAuth tokens. The default files of a
+// workspace end in its name, e.g. .users_cache_acme.json.
+func CacheFiles(cfg *config.Config) []CacheFile {
+	suffix := ".json"
+	if cfg.WorkspaceName != "" {
+		suffix = "_" + cfg.WorkspaceName + ".json"
 	}
 
-	channelsCache := os.Getenv("SLACK_MCP_CHANNELS_CACHE")
-	if channelsCache == "" {
-		channelsCache = ".channels_cache.json"
+	users := cfg.UsersCache
+	if users == "" {
+		users = ".users_cache" + suffix
 	}
 
-	if os.Getenv("SLACK_MCP_XOXP_TOKEN") == "demo" || (os.Getenv("SLACK_MCP_XOXC_TOKEN") == "demo" && os.Getenv("SLACK_MCP_XOXD_TOKEN") == "demo") {
-		logger.Info("Demo credentials are set, skip.")
-	} else {
-		client, err = NewMCPSlackClient(authProvider, logger)
-		if err != nil {
-			logger.Fatal("Failed to create MCP Slack client", zap.Error(err))
+	channels := cfg.ChannelsCache
+	if channels == "" {
+		channels = ".channels_cache_v2" + suffix
+		if cfg.XOXPToken != "" {
+			channels = ".channels_cache" + suffix
 		}
 	}
 
-	return &ApiProvider{
-		transport: transport,
-		client:    client,
-		logger:    logger,
-
-		rateLimiter: limiter.Tier2.Limiter(),
-
-		users:      make(map[string]slack.User),
-		usersInv:   map[string]string{},
-		usersCache: usersCache,
+	emoji := cfg.EmojiCache
+	if emoji == "" {
+		emoji = ".emoji_cache" + suffix
+	}
 
-		channels:      make(map[string]Channel),
-		channelsInv:   map[string]string{},
-		channelsCache: channelsCache,
+	return []CacheFile{
+		{Name: "users", Path: users},
+		{Name: "channels", Path: channels},
+		{Name: "emoji", Path: emoji},
 	}
 }
 
-func newWithXOXC(transport string, authProvider auth.ValueAuth, logger *zap.Logger) *ApiProvider {
+func newWithAuth(cfg *config.Config, authProvider auth.ValueAuth, logger *zap.Logger) (*ApiProvider, error) {
 	var (
 		client *MCPSlackClient
 		err    error
 	)
 
-	usersCache := os.Getenv("SLACK_MCP_USERS_CACHE")
-	if usersCache == "" {
-		usersCache = ".users_cache.json"
-	}
-
-	channelsCache := os.Getenv("SLACK_MCP_CHANNELS_CACHE")
-	if channelsCache == "" {
-		channelsCache = ".channels_cache_v2.json"
-	}
+	caches := CacheFiles(cfg)
+	usersCache, channelsCache, emojiCache := caches[0].Path, caches[1].Path, caches[2].Path
 
-	if os.Getenv("SLACK_MCP_XOXP_TOKEN") == "demo" || (os.Getenv("SLACK_MCP_XOXC_TOKEN") == "demo" && os.Getenv("SLACK_MCP_XOXD_TOKEN") == "demo") {
+	if cfg.Demo() {
 		logger.Info("Demo credentials are set, skip.")
 	} else {
-		client, err = NewMCPSlackClient(authProvider, logger)
+		client, err = NewMCPSlackClient(cfg, authProvider, logger)
 		if err != nil {
-			logger.Fatal("Failed to create MCP Slack client", zap.Error(err))
+			return nil, fmt.Errorf("failed to create MCP Slack client: %w", err)
 		}
 	}
 
-	return &ApiProvider{
-		transport: transport,
+	workspace := cfg.WorkspaceName
+	if workspace == "" {
+		// the client keeps the answer of the auth.test it made when connecting
+		ar, err := client.AuthTest()
+		if err != nil {
+			return nil, fmt.Errorf("failed to authenticate with Slack: %w", err)
+		}
+		workspace, err = text.Workspace(ar.URL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse workspace from URL %q: %w", ar.URL, err)
+		}
+	}
+
+	ap := &ApiProvider{
+		transport: cfg.Transport,
+		demo:      cfg.Demo(),
 		client:    client,
 		logger:    logger,
 
+		workspace: workspace,
+
 		rateLimiter: limiter.Tier2.Limiter(),
 
+		location: loadLocation(cfg, logger),
+		redactor: loadRedactor(cfg, logger),
+
 		users:      make(map[string]slack.User),
 		usersInv:   map[string]string{},
 		usersCache: usersCache,
@@ -395,15 +641,21 @@ func newWithXOXC(transport string, authProvider auth.ValueAuth, logger *zap.Logg
 		channels:      make(map[string]Channel),
 		channelsInv:   map[string]string{},
 		channelsCache: channelsCache,
+
+		emoji:      map[string]string{},
+		emojiCache: emojiCache,
+	}
+	if client != nil {
+		client.listLimiter = ap.rateLimiter
+	}
+	if err := ap.ReloadReadPolicy(cfg); err != nil {
+		return nil, fmt.Errorf("invalid SLACK_MCP_READ_ALLOW or SLACK_MCP_READ_DENY: %w", err)
 	}
+	return ap, nil
 }
 
-func (ap *ApiProvider) RefreshUsers(ctx context.Context) error {
-	var (
-		list         []slack.User
-		usersCounter = 0
-		optionLimit  = slack.GetUsersOptionLimit(1000)
-	)
+func (ap *ApiProvider) RefreshUsers(ctx context.Context) (err error) {
+	defer func() { ap.recordSync("users", err) }()
 
 	if data, err := ioutil.ReadFile(ap.usersCache); err == nil {
 		var cachedUsers []slack.User
@@ -424,6 +676,23 @@ func (ap *ApiProvider) RefreshUsers(ctx context.Context) error {
 		}
 	}
 
+	return ap.fetchUsers(ctx)
+}
+
+// FetchUsers fetches the users from Slack and rewrites the users cache file,
+// ignoring its current content.
+func (ap *ApiProvider) FetchUsers(ctx context.Context) (err error) {
+	defer func() { ap.recordSync("users", err) }()
+	return ap.fetchUsers(ctx)
+}
+
+func (ap *ApiProvider) fetchUsers(ctx context.Context) error {
+	var (
+		list         []slack.User
+		usersCounter = 0
+		optionLimit  = slack.GetUsersOptionLimit(1000)
+	)
+
 	users, err := ap.client.GetUsersContext(ctx,
 		optionLimit,
 	)
@@ -457,7 +726,7 @@ func (ap *ApiProvider) RefreshUsers(ctx context.Context) error {
 	if data, err := json.MarshalIndent(list, "", "  "); err != nil {
 		ap.logger.Error("Failed to marshal users for cache", zap.Error(err))
 	} else {
-		if err := ioutil.WriteFile(ap.usersCache, data, 0644); err != nil {
+		if err := writeCacheFile(ap.usersCache, data); err != nil {
 			ap.logger.Error("Failed to write cache file",
 				zap.String("cache_file", ap.usersCache),
 				zap.Error(err))
@@ -473,7 +742,9 @@ func (ap *ApiProvider) RefreshUsers(ctx context.Context) error {
 	return nil
 }
 
-func (ap *ApiProvider) RefreshChannels(ctx context.Context) error {
+func (ap *ApiProvider) RefreshChannels(ctx context.Context) (err error) {
+	defer func() { ap.recordSync("channels", err) }()
+
 	if data, err := ioutil.ReadFile(ap.channelsCache); err == nil {
 		var cachedChannels []Channel
 		if err := json.Unmarshal(data, &cachedChannels); err != nil {
@@ -493,12 +764,27 @@ func (ap *ApiProvider) RefreshChannels(ctx context.Context) error {
 		}
 	}
 
+	return ap.fetchChannels(ctx)
+}
+
+// FetchChannels fetches the channels from Slack and rewrites the channels
+// cache file, ignoring its current content.
+func (ap *ApiProvider) FetchChannels(ctx context.Context) (err error) {
+	defer func() { ap.recordSync("channels", err) }()
+	return ap.fetchChannels(ctx)
+}
+
+func (ap *ApiProvider) fetchChannels(ctx context.Context) error {
 	channels := ap.GetChannels(ctx, AllChanTypes)
+	if err := ctx.Err(); err != nil {
+		// an interrupted fetch is incomplete, don't cache it
+		return err
+	}
 
 	if data, err := json.MarshalIndent(channels, "", "  "); err != nil {
 		ap.logger.Error("Failed to marshal channels for cache", zap.Error(err))
 	} else {
-		if err := ioutil.WriteFile(ap.channelsCache, data, 0644); err != nil {
+		if err := writeCacheFile(ap.channelsCache, data); err != nil {
 			ap.logger.Error("Failed to write cache file",
 				zap.String("cache_file", ap.channelsCache),
 				zap.Error(err))
@@ -669,6 +955,93 @@ func (ap *ApiProvider) IsReady() (bool, error) {
 	return true, nil
 }
 
+// CacheStats reports the size and age of the caches for metrics, the age is
+// taken from the cache files, as that is when the data was fetched.
+func (ap *ApiProvider) CacheStats() []metrics.CacheStat {
+	ap.emojiMu.RLock()
+	emojiCount := len(ap.emoji)
+	ap.emojiMu.RUnlock()
+
+	emojiUpdated := cacheFileTime(ap.emojiCache)
+	return []metrics.CacheStat{
+		{Name: "users", Size: len(ap.users), Ready: ap.usersReady, UpdatedAt: cacheFileTime(ap.usersCache)},
+		{Name: "channels", Size: len(ap.channels), Ready: ap.channelsReady, UpdatedAt: cacheFileTime(ap.channelsCache)},
+		{Name: "emoji", Size: emojiCount, Ready: !emojiUpdated.IsZero(), UpdatedAt: emojiUpdated},
+	}
+}
+
+// writeCacheFile replaces a cache file through a temporary file, so that a
+// shutdown or crash mid-write never leaves a truncated cache behind.
+func writeCacheFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func cacheFileTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// Location returns the time zone for rendering timestamps. It is either the
+// one set via SLACK_MCP_TIMEZONE or the authenticated user's Slack time zone,
+// falling back to UTC while the users cache is not ready.
+func (ap *ApiProvider) Location() *time.Location {
+	if ap.location != nil {
+		return ap.location
+	}
+
+	ar, err := ap.client.AuthTest()
+	if err != nil {
+		return time.UTC
+	}
+
+	u, ok := ap.users[ar.UserID]
+	if !ok || u.TZ == "" {
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(u.TZ)
+	if err != nil {
+		ap.logger.Warn("Failed to load user time zone, using UTC",
+			zap.String("tz", u.TZ),
+			zap.Error(err),
+		)
+		return time.UTC
+	}
+
+	return loc
+}
+
+// Demo reports whether the provider runs with the demo credentials, without
+// a Slack client.
+func (ap *ApiProvider) Demo() bool {
+	return ap.demo
+}
+
+// Workspace returns the name of the configured workspace, or the Slack
+// subdomain of the workspace when the server serves a single one.
+func (ap *ApiProvider) Workspace() string {
+	return ap.workspace
+}
+
 func (ap *ApiProvider) ServerTransport() string {
 	return ap.transport
 }
@@ -677,6 +1050,128 @@ func (ap *ApiProvider) Slack() SlackAPI {
 	return ap.client
 }
 
+func loadLocation(cfg *config.Config, logger *zap.Logger) *time.Location {
+	tz := cfg.Timezone
+	if tz == "" || tz == "user" {
+		return nil
+	}
+
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		logger.Fatal("Invalid SLACK_MCP_TIMEZONE, expected an IANA time zone name or 'user'",
+			zap.String("tz", tz),
+			zap.Error(err),
+		)
+	}
+
+	return loc
+}
+
+// Redactor returns the redaction rules for content read from Slack, nil when
+// SLACK_MCP_REDACT is not set.
+func (ap *ApiProvider) Redactor() *redact.Redactor {
+	return ap.redactor
+}
+
+// ReadPolicy returns the conversations the server may read from, nil when
+// neither SLACK_MCP_READ_ALLOW nor SLACK_MCP_READ_DENY is set.
+func (ap *ApiProvider) ReadPolicy() *access.Policy {
+	return ap.readPolicy.Load()
+}
+
+// ReloadReadPolicy replaces the read policy with the one of cfg, the current
+// one is kept when cfg is invalid.
+func (ap *ApiProvider) ReloadReadPolicy(cfg *config.Config) error {
+	p, err := access.New(cfg.ReadAllow, cfg.ReadDeny)
+	if err != nil {
+		return err
+	}
+	ap.readPolicy.Store(p)
+	return nil
+}
+
+// ReadableChannel looks a channel ID up in the channels cache and reports
+// whether the read policy allows it. Channels missing from the cache are
+// looked up with conversations.info, so that name, pattern and class rules
+// apply to them too. They are hidden when the lookup fails.
+func (ap *ApiProvider) ReadableChannel(ctx context.Context, id string) bool {
+	policy := ap.readPolicy.Load()
+	if policy == nil {
+		return true
+	}
+	c, ok := ap.channels[id]
+	if !ok {
+		if ap.demo {
+			return false
+		}
+		info, err := ap.client.GetConversationInfoContext(ctx, &slack.GetConversationInfoInput{ChannelID: id})
+		if err != nil {
+			ap.logger.Warn("Failed to look up uncached channel for the read policy",
+				zap.String("channel", id),
+				zap.Error(err),
+			)
+			return false
+		}
+		c = mapChannel(
+			info.ID,
+			info.Name,
+			info.NameNormalized,
+			info.Topic.Value,
+			info.Purpose.Value,
+			info.User,
+			info.Members,
+			info.NumMembers,
+			info.IsIM,
+			info.IsMpIM,
+			info.IsPrivate,
+			ap.users,
+		)
+	}
+	return policy.Allowed(AccessChannel(c))
+}
+
+// AccessChannel describes a cached channel to the read policy.
+func AccessChannel(c Channel) access.Channel {
+	return access.Channel{ID: c.ID, Name: c.Name, Class: access.ClassOf(c.IsPrivate, c.IsIM, c.IsMpIM)}
+}
+
+func loadRedactor(cfg *config.Config, logger *zap.Logger) *redact.Redactor {
+	rules := cfg.Redact
+	if rules == "" {
+		return nil
+	}
+
+	var patterns []string
+	if path := cfg.RedactPatterns; path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			logger.Fatal("Failed to read SLACK_MCP_REDACT_PATTERNS",
+				zap.String("path", path),
+				zap.Error(err),
+			)
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				patterns = append(patterns, line)
+			}
+		}
+	}
+
+	r, err := redact.New(redact.Config{
+		Rules:    rules,
+		Patterns: patterns,
+		Key:      cfg.RedactKey,
+	})
+	if err != nil {
+		logger.Fatal("Invalid SLACK_MCP_REDACT", zap.Error(err))
+	}
+	if !r.Enabled() {
+		return nil
+	}
+
+	return r
+}
+
 func mapChannel(
 	id, name, nameNormalized, topic, purpose, user string,
 	members []string,
@@ -0,0 +1,115 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/access"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+type infoAPI struct {
+	SlackAPI
+	channels map[string]slack.Channel
+	calls    int
+}
+
+func (a *infoAPI) GetConversationInfoContext(ctx context.Context, input *slack.GetConversationInfoInput) (*slack.Channel, error) {
+	a.calls++
+	c, ok := a.channels[input.ChannelID]
+	if !ok {
+		return nil, errors.New("channel_not_found")
+	}
+	return &c, nil
+}
+
+func TestUnitReadableChannel(t *testing.T) {
+	hr := slack.Channel{}
+	hr.ID, hr.Name, hr.NameNormalized = "C2", "hr-payroll", "hr-payroll"
+	secret := slack.Channel{}
+	secret.ID, secret.Name, secret.NameNormalized, secret.IsPrivate = "C3", "plans", "plans", true
+	random := slack.Channel{}
+	random.ID, random.Name, random.NameNormalized = "C4", "random", "random"
+	api := &infoAPI{channels: map[string]slack.Channel{"C2": hr, "C3": secret, "C4": random}}
+	ap := &ApiProvider{
+		client:   api,
+		logger:   zap.NewNop(),
+		channels: map[string]Channel{"C1": {ID: "C1", Name: "#general"}},
+	}
+	assert.True(t, ap.ReadableChannel(context.Background(), "C9"), "no policy, no lookup")
+	assert.Zero(t, api.calls)
+
+	policy, err := access.New("", "#hr-*, private")
+	require.NoError(t, err)
+	ap.readPolicy.Store(policy)
+
+	assert.True(t, ap.ReadableChannel(context.Background(), "C1"))
+	assert.Zero(t, api.calls, "cached channels are not looked up")
+	assert.False(t, ap.ReadableChannel(context.Background(), "C2"), "an uncached channel is matched by name")
+	assert.False(t, ap.ReadableChannel(context.Background(), "C3"), "an uncached channel is matched by class")
+	assert.True(t, ap.ReadableChannel(context.Background(), "C4"))
+	assert.False(t, ap.ReadableChannel(context.Background(), "C9"), "a failed lookup hides the channel")
+	assert.Equal(t, 4, api.calls)
+}
+
+func TestUnitSearchCursor(t *testing.T) {
+	pageCursor, skip, err := decodeSearchCursor("")
+	require.NoError(t, err)
+	assert.Equal(t, "", pageCursor)
+	assert.Equal(t, 0, skip)
+
+	pageCursor, skip, err = decodeSearchCursor(encodeSearchCursor("dGVhbTpDMDYx", 7))
+	require.NoError(t, err)
+	assert.Equal(t, "dGVhbTpDMDYx", pageCursor)
+	assert.Equal(t, 7, skip)
+
+	_, _, err = decodeSearchCursor("not base64!")
+	assert.Error(t, err)
+}
+
+func TestUnitSearchChannelsFallbackPages(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		page, _ := strconv.Atoi(strings.TrimPrefix(r.FormValue("cursor"), "p"))
+		fmt.Fprintf(w, `{"ok":true,"channels":[{"id":"C%d","name":"general-%d"}],"response_metadata":{"next_cursor":"p%d"}}`, page, page, page+1)
+	}))
+	defer srv.Close()
+	c := &MCPSlackClient{
+		slackClient: slack.New("xoxp-test", slack.OptionAPIURL(srv.URL+"/")),
+		isOAuth:     true,
+		listLimiter: rate.NewLimiter(rate.Inf, 1),
+	}
+
+	found, cursor, err := c.SearchChannelsContext(context.Background(), "incident", "", 10)
+	require.NoError(t, err)
+	assert.Empty(t, found)
+	assert.Equal(t, maxSearchChannelsPages, calls, "a search reads a bounded number of pages")
+	pageCursor, skip, err := decodeSearchCursor(cursor)
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("p%d", maxSearchChannelsPages), pageCursor, "the search resumes after the last page read")
+	assert.Zero(t, skip)
+}
+
+func TestUnitChannelMatches(t *testing.T) {
+	channel := slack.Channel{}
+	channel.Name = "inc-payments"
+	channel.Topic.Value = "Payments outage"
+	channel.Purpose.Value = "War room"
+
+	assert.True(t, channelMatches(channel, ""))
+	assert.True(t, channelMatches(channel, "inc-"))
+	assert.True(t, channelMatches(channel, "outage"))
+	assert.True(t, channelMatches(channel, "war room"))
+	assert.False(t, channelMatches(channel, "billing"))
+}
@@ -19,6 +19,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/korotovsky/slack-mcp-server/pkg/metrics"
 	"github.com/rusq/slackauth"
 	"github.com/rusq/slackdump/v3/auth"
 	"github.com/rusq/tagops"
@@ -297,6 +298,7 @@ func do(ctx context.Context, cl httpClient, req *http.Request) (*http.Response,
 			return nil, err
 		}
 		lg.InfoContext(ctx, "got rate limited, waiting", "delay", wait)
+		metrics.ObserveRateLimit("edge", wait)
 
 		time.Sleep(wait)
 		resp, err = cl.Do(req)
@@ -306,6 +308,7 @@ func do(ctx context.Context, cl httpClient, req *http.Request) (*http.Response,
 		// if we are still rate limited, then we are in trouble
 		if resp.StatusCode == http.StatusTooManyRequests {
 			lg.DebugContext(ctx, "edge.do: did my best, but still rate limited, giving up, not my problem")
+			metrics.ObserveRateLimit("edge", 0)
 			wait, err = parseRetryAfter(resp)
 			if err != nil {
 				return nil, err
@@ -100,13 +100,50 @@ func (cl *Client) SearchChannels(ctx context.Context, query string) ([]slack.Cha
 
 	trace.Logf(ctx, "params", "query=%q", query)
 
+	lim := limiter.Tier2boost.Limiter()
+	var (
+		cc     []slack.Channel
+		cursor string
+	)
+	for {
+		page, next, err := cl.SearchChannelsPage(ctx, query, cursor, perPage)
+		if err != nil {
+			return nil, err
+		}
+		cc = append(cc, page...)
+		if next == "" {
+			lg.Debug("no more channels")
+			break
+		}
+		lg.DebugContext(ctx, "pagination", "next_cursor", next)
+		cursor = next
+		if err := lim.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	trace.Logf(ctx, "info", "channels found=%d", len(cc))
+	lg.DebugContext(ctx, "channels", "count", len(cc))
+	return cc, nil
+}
+
+// SearchChannelsPage returns a single page of the channel browser search,
+// archived channels and channels the user is not a member of included. An
+// empty cursor starts from the first page, an empty next cursor means there
+// are no more results.
+func (cl *Client) SearchChannelsPage(ctx context.Context, query string, cursor string, count int) ([]slack.Channel, string, error) {
 	clientReq, err := uuid.NewRandom()
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	browseID, err := uuid.NewRandom()
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+	if cursor == "" {
+		cursor = "*"
+	}
+	if count <= 0 || count > perPage {
+		count = perPage
 	}
 	form := searchForm{
 		BaseRequest:          BaseRequest{Token: cl.token},
@@ -117,10 +154,10 @@ func (cl *Client) SearchChannels(ctx context.Context, query string) ([]slack.Cha
 		BrowseID:             browseID.String(),
 		Extracts:             0,
 		Highlight:            0,
-		Cursor:               "*",
+		Cursor:               cursor,
 		ExtraMsg:             0,
 		NoUserProfile:        1,
-		Count:                perPage,
+		Count:                count,
 		FileTitleOnly:        false,
 		QueryRewriteDisabled: false,
 		IncludeFilesShares:   1,
@@ -142,50 +179,36 @@ func (cl *Client) SearchChannels(ctx context.Context, query string) ([]slack.Cha
 	}
 
 	const ep = "search.modules.channels"
-	lim := limiter.Tier2boost.Limiter()
-	var cc []slack.Channel
-	for {
-		resp, err := cl.PostForm(ctx, ep, values(form, true))
-		if err != nil {
-			return nil, err
-		}
-		var sr SearchResponse[Channel]
-		if err := cl.ParseResponse(&sr, resp); err != nil {
-			return nil, err
-		}
-		if err := sr.validate(ep); err != nil {
-			return nil, err
-		}
+	resp, err := cl.PostForm(ctx, ep, values(form, true))
+	if err != nil {
+		return nil, "", err
+	}
+	var sr SearchResponse[Channel]
+	if err := cl.ParseResponse(&sr, resp); err != nil {
+		return nil, "", err
+	}
+	if err := sr.validate(ep); err != nil {
+		return nil, "", err
+	}
 
-		// fix for the members count, mapping is incorrect in the slack.Channel
-		// if the object is being used for search.modules.channels ep
-		for _, c := range sr.Items {
-			obj := slack.Channel{
-				GroupConversation: c.GroupConversation,
-				IsChannel:         true,
-				IsGeneral:         c.IsGeneral,
-				IsMember:          c.IsMember,
-				Locale:            c.Locale,
-				Properties:        c.Properties,
-			}
-			obj.NumMembers = c.NumMembers
-			if obj.NumMembers == 0 {
-				obj.IsArchived = true
-			}
-
-			cc = append(cc, obj)
+	// fix for the members count, mapping is incorrect in the slack.Channel
+	// if the object is being used for search.modules.channels ep
+	cc := make([]slack.Channel, 0, len(sr.Items))
+	for _, c := range sr.Items {
+		obj := slack.Channel{
+			GroupConversation: c.GroupConversation,
+			IsChannel:         true,
+			IsGeneral:         c.IsGeneral,
+			IsMember:          c.IsMember,
+			Locale:            c.Locale,
+			Properties:        c.Properties,
 		}
-		if sr.Pagination.NextCursor == "" {
-			lg.Debug("no more channels")
-			break
-		}
-		lg.DebugContext(ctx, "pagination", "next_cursor", sr.Pagination.NextCursor)
-		form.Cursor = sr.Pagination.NextCursor
-		if err := lim.Wait(ctx); err != nil {
-			return nil, err
+		obj.NumMembers = c.NumMembers
+		if obj.NumMembers == 0 {
+			obj.IsArchived = true
 		}
+
+		cc = append(cc, obj)
 	}
-	trace.Logf(ctx, "info", "channels found=%d", len(cc))
-	lg.DebugContext(ctx, "channels", "count", len(cc))
-	return cc, nil
+	return cc, sr.Pagination.NextCursor, nil
 }
@@ -0,0 +1,170 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/text"
+	"go.uber.org/zap"
+)
+
+const maxEmojiAliasDepth = 5
+
+// maxEmojiCacheAge is how long the emoji cache file is trusted when Slack
+// can't tell whether it is fresh, e.g. for OAuth tokens.
+const maxEmojiCacheAge = 24 * time.Hour
+
+// EmojiCache is the on-disk form of the workspace custom emoji, CacheTs is
+// the emoji_cache_ts reported by client.userBoot when the list was fetched.
+type EmojiCache struct {
+	CacheTs time.Time         `json:"cache_ts"`
+	Emoji   map[string]string `json:"emoji"`
+}
+
+// Emoji is a custom emoji or an alias of a custom or standard one.
+type Emoji struct {
+	Name     string
+	URL      string
+	AliasFor string
+	Unicode  string
+}
+
+// RefreshEmoji loads the custom emoji list. The cache file is reused until
+// Slack reports a newer emoji_cache_ts. When the boot call fails, as it does
+// for OAuth tokens, it is reused for maxEmojiCacheAge after it was written.
+// A stale cache file is still loaded when the emoji can't be fetched.
+func (ap *ApiProvider) RefreshEmoji(ctx context.Context) (err error) {
+	defer func() { ap.recordSync("emoji", err) }()
+
+	var (
+		cached   *EmojiCache
+		cachedAt time.Time
+	)
+	if data, err := os.ReadFile(ap.emojiCache); err == nil {
+		var c EmojiCache
+		if err := json.Unmarshal(data, &c); err != nil {
+			ap.logger.Warn("Failed to unmarshal emoji cache, will refetch",
+				zap.String("cache_file", ap.emojiCache),
+				zap.Error(err))
+		} else if info, err := os.Stat(ap.emojiCache); err == nil {
+			cached, cachedAt = &c, info.ModTime()
+		}
+	}
+
+	var (
+		cacheTs time.Time
+		fresh   bool
+	)
+	boot, err := ap.client.ClientUserBoot(ctx)
+	if err != nil {
+		ap.logger.Warn("Failed to fetch client user boot, emoji cache freshness is unknown", zap.Error(err))
+		fresh = cached != nil && time.Since(cachedAt) < maxEmojiCacheAge
+	} else {
+		cacheTs = time.Time(boot.EmojiCacheTs)
+		fresh = cached != nil && !cacheTs.After(cached.CacheTs)
+	}
+
+	if fresh {
+		ap.setEmoji(cached.Emoji)
+		ap.logger.Info("Loaded emoji from cache",
+			zap.Int("count", len(cached.Emoji)),
+			zap.String("cache_file", ap.emojiCache))
+		return nil
+	}
+
+	list, err := ap.client.GetEmojiContext(ctx)
+	if err != nil {
+		ap.logger.Error("Failed to fetch emoji", zap.Error(err))
+		if cached != nil {
+			ap.setEmoji(cached.Emoji)
+			ap.logger.Warn("Loaded stale emoji from cache",
+				zap.Int("count", len(cached.Emoji)),
+				zap.String("cache_file", ap.emojiCache))
+		}
+		return err
+	}
+	ap.setEmoji(list)
+
+	if data, err := json.MarshalIndent(EmojiCache{CacheTs: cacheTs, Emoji: list}, "", "  "); err != nil {
+		ap.logger.Error("Failed to marshal emoji for cache", zap.Error(err))
+	} else {
+		if err := writeCacheFile(ap.emojiCache, data); err != nil {
+			ap.logger.Error("Failed to write cache file",
+				zap.String("cache_file", ap.emojiCache),
+				zap.Error(err))
+		} else {
+			ap.logger.Info("Wrote emoji to cache",
+				zap.Int("count", len(list)),
+				zap.String("cache_file", ap.emojiCache))
+		}
+	}
+
+	return nil
+}
+
+func (ap *ApiProvider) setEmoji(list map[string]string) {
+	if list == nil {
+		list = map[string]string{}
+	}
+	ap.emojiMu.Lock()
+	ap.emoji = list
+	ap.emojiMu.Unlock()
+}
+
+// ProvideEmoji returns the custom emoji sorted by name, aliases resolved.
+func (ap *ApiProvider) ProvideEmoji() []Emoji {
+	ap.emojiMu.RLock()
+	list := ap.emoji
+	ap.emojiMu.RUnlock()
+
+	res := make([]Emoji, 0, len(list))
+	for name, value := range list {
+		e := Emoji{Name: name}
+		if alias, ok := strings.CutPrefix(value, "alias:"); ok {
+			e.AliasFor = alias
+			e.URL, e.Unicode = resolveEmoji(list, alias, 1)
+		} else {
+			e.URL = value
+		}
+		res = append(res, e)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
+
+// EmojiUnicode returns the Unicode form of a custom emoji aliased to a
+// standard one, empty for image emoji and unknown names.
+func (ap *ApiProvider) EmojiUnicode(name string) string {
+	ap.emojiMu.RLock()
+	list := ap.emoji
+	ap.emojiMu.RUnlock()
+
+	if _, ok := list[name]; !ok {
+		return ""
+	}
+	_, u := resolveEmoji(list, name, 0)
+	return u
+}
+
+// resolveEmoji follows alias chains to either an image URL of a custom emoji
+// or the Unicode of a standard one.
+func resolveEmoji(list map[string]string, name string, depth int) (url, unicode string) {
+	value, ok := list[name]
+	if !ok {
+		u, _ := text.EmojiUnicode(name)
+		return "", u
+	}
+	if alias, ok := strings.CutPrefix(value, "alias:"); ok {
+		if depth >= maxEmojiAliasDepth {
+			return "", ""
+		}
+		return resolveEmoji(list, alias, depth+1)
+	}
+	return value, ""
+}
@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/provider/edge"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// oauthEmojiAPI fails client.userBoot like Slack does for OAuth tokens.
+type oauthEmojiAPI struct {
+	SlackAPI
+	emoji map[string]string
+	err   error
+	calls int
+}
+
+func (a *oauthEmojiAPI) ClientUserBoot(ctx context.Context) (*edge.ClientUserBootResponse, error) {
+	return nil, errors.New("not_allowed_token_type")
+}
+
+func (a *oauthEmojiAPI) GetEmojiContext(ctx context.Context) (map[string]string, error) {
+	a.calls++
+	return a.emoji, a.err
+}
+
+func TestUnitRefreshEmojiWithoutBoot(t *testing.T) {
+	api := &oauthEmojiAPI{emoji: map[string]string{"parrot": "https://emoji/parrot.gif"}}
+	ap := &ApiProvider{client: api, logger: zap.NewNop(), emojiCache: filepath.Join(t.TempDir(), "emoji.json")}
+
+	require.NoError(t, ap.RefreshEmoji(context.Background()))
+	require.NoError(t, ap.RefreshEmoji(context.Background()))
+	assert.Equal(t, 1, api.calls, "a recent cache file is reused")
+
+	old := time.Now().Add(-maxEmojiCacheAge - time.Minute)
+	require.NoError(t, os.Chtimes(ap.emojiCache, old, old))
+	api.emoji = map[string]string{"parrot": "https://emoji/parrot.gif", "shipit": "https://emoji/shipit.png"}
+	require.NoError(t, ap.RefreshEmoji(context.Background()))
+	assert.Equal(t, 2, api.calls, "an old cache file is fetched again")
+	assert.Len(t, ap.ProvideEmoji(), 2)
+
+	require.NoError(t, os.Chtimes(ap.emojiCache, old, old))
+	api.err = errors.New("ratelimited")
+	assert.Error(t, ap.RefreshEmoji(context.Background()))
+	assert.Len(t, ap.ProvideEmoji(), 2, "the stale cache is loaded when fetching fails")
+}
+
+func TestUnitResolveEmoji(t *testing.T) {
+	list := map[string]string{
+		"parrot":   "https://emoji.slack-edge.com/T1/parrot/abc.gif",
+		"party":    "alias:parrot",
+		"yay":      "alias:tada",
+		"loop":     "alias:loop",
+		"double":   "alias:party",
+		"dangling": "alias:missing",
+	}
+
+	url, u := resolveEmoji(list, "party", 0)
+	assert.Equal(t, list["parrot"], url)
+	assert.Empty(t, u)
+
+	url, _ = resolveEmoji(list, "double", 0)
+	assert.Equal(t, list["parrot"], url)
+
+	_, u = resolveEmoji(list, "yay", 0)
+	assert.Equal(t, "\U0001f389", u)
+
+	url, u = resolveEmoji(list, "loop", 0)
+	assert.Empty(t, url)
+	assert.Empty(t, u)
+
+	url, u = resolveEmoji(list, "dangling", 0)
+	assert.Empty(t, url)
+	assert.Empty(t, u)
+}
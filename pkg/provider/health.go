@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// SyncStatus is the outcome of the last refresh of a cache.
+type SyncStatus struct {
+	Workspace string    `json:"workspace,omitempty"`
+	Cache     string    `json:"cache"`
+	At        time.Time `json:"at"`
+	Error     string    `json:"error,omitempty"`
+}
+
+func (ap *ApiProvider) recordSync(cache string, err error) {
+	status := SyncStatus{Cache: cache, At: time.Now()}
+	if err != nil {
+		status.Error = err.Error()
+	}
+
+	ap.syncMu.Lock()
+	defer ap.syncMu.Unlock()
+	if ap.syncs == nil {
+		ap.syncs = make(map[string]SyncStatus)
+	}
+	ap.syncs[cache] = status
+}
+
+// SyncStatuses returns the outcome of the last refresh of every cache that
+// was refreshed so far, sorted by cache name.
+func (ap *ApiProvider) SyncStatuses() []SyncStatus {
+	ap.syncMu.Lock()
+	defer ap.syncMu.Unlock()
+
+	res := make([]SyncStatus, 0, len(ap.syncs))
+	for _, s := range ap.syncs {
+		res = append(res, s)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Cache < res[j].Cache })
+	return res
+}
+
+// CheckAuth calls auth.test to tell whether the Slack token is still valid.
+func (ap *ApiProvider) CheckAuth(ctx context.Context) error {
+	if ap.demo {
+		return nil
+	}
+	_, err := ap.client.AuthTestContext(ctx)
+	return err
+}
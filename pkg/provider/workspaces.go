@@ -0,0 +1,126 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Workspaces are the providers of the workspaces a server serves, one per
+// configured workspace or a single one. It reports the readiness and sync
+// status of all of them to the health checks.
+type Workspaces struct {
+	providers []*ApiProvider
+	byName    map[string]*ApiProvider
+	def       *ApiProvider
+}
+
+// NewWorkspaces groups providers by workspace name. The default workspace is
+// used by tool calls without a workspace argument, an empty name picks the
+// first provider.
+func NewWorkspaces(providers []*ApiProvider, defaultName string) (*Workspaces, error) {
+	if len(providers) == 0 {
+		return nil, errors.New("no workspace is configured")
+	}
+	w := &Workspaces{
+		providers: providers,
+		byName:    make(map[string]*ApiProvider, len(providers)),
+		def:       providers[0],
+	}
+	for _, p := range providers {
+		if _, ok := w.byName[p.Workspace()]; ok {
+			return nil, fmt.Errorf("workspace %q is configured twice", p.Workspace())
+		}
+		w.byName[p.Workspace()] = p
+	}
+	if defaultName != "" {
+		def, ok := w.byName[defaultName]
+		if !ok {
+			return nil, fmt.Errorf("unknown default workspace %q", defaultName)
+		}
+		w.def = def
+	}
+	return w, nil
+}
+
+// All returns the providers in the configured order.
+func (w *Workspaces) All() []*ApiProvider {
+	return w.providers
+}
+
+// Default returns the provider of the default workspace.
+func (w *Workspaces) Default() *ApiProvider {
+	return w.def
+}
+
+// Names returns the workspace names in the configured order.
+func (w *Workspaces) Names() []string {
+	names := make([]string, 0, len(w.providers))
+	for _, p := range w.providers {
+		names = append(names, p.Workspace())
+	}
+	return names
+}
+
+// Get returns the provider of the named workspace, or of the default one
+// when name is empty.
+func (w *Workspaces) Get(name string) (*ApiProvider, error) {
+	if name == "" {
+		return w.def, nil
+	}
+	p, ok := w.byName[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown workspace %q, expected one of: %s", name, strings.Join(w.Names(), ", "))
+	}
+	return p, nil
+}
+
+// Multiple reports whether more than one workspace is served.
+func (w *Workspaces) Multiple() bool {
+	return len(w.providers) > 1
+}
+
+// IsReady reports whether the caches of every workspace are loaded.
+func (w *Workspaces) IsReady() (bool, error) {
+	for _, p := range w.providers {
+		if ready, err := p.IsReady(); !ready {
+			return false, w.wrap(p, err)
+		}
+	}
+	return true, nil
+}
+
+// CheckAuth checks the tokens of every workspace.
+func (w *Workspaces) CheckAuth(ctx context.Context) error {
+	var errs []error
+	for _, p := range w.providers {
+		if err := p.CheckAuth(ctx); err != nil {
+			errs = append(errs, w.wrap(p, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SyncStatuses returns the sync statuses of every workspace, labelled with
+// the workspace when there are several.
+func (w *Workspaces) SyncStatuses() []SyncStatus {
+	var res []SyncStatus
+	for _, p := range w.providers {
+		for _, s := range p.SyncStatuses() {
+			if w.Multiple() {
+				s.Workspace = p.Workspace()
+			}
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// wrap names the workspace in err when there are several.
+func (w *Workspaces) wrap(p *ApiProvider, err error) error {
+	if !w.Multiple() || err == nil {
+		return err
+	}
+	return fmt.Errorf("workspace %s: %w", p.Workspace(), err)
+}
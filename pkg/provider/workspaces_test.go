@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitWorkspaces(t *testing.T) {
+	acme := &ApiProvider{workspace: "acme", demo: true, usersReady: true, channelsReady: true}
+	beta := &ApiProvider{workspace: "beta", demo: true, usersReady: true}
+	beta.recordSync("users", nil)
+	beta.recordSync("channels", errors.New("rate limited"))
+
+	ws, err := NewWorkspaces([]*ApiProvider{acme, beta}, "beta")
+	require.NoError(t, err)
+	assert.True(t, ws.Multiple())
+	assert.Equal(t, []string{"acme", "beta"}, ws.Names())
+	assert.Same(t, beta, ws.Default())
+
+	p, err := ws.Get("")
+	require.NoError(t, err)
+	assert.Same(t, beta, p)
+	p, err = ws.Get("acme")
+	require.NoError(t, err)
+	assert.Same(t, acme, p)
+	_, err = ws.Get("gamma")
+	assert.EqualError(t, err, `unknown workspace "gamma", expected one of: acme, beta`)
+
+	ready, err := ws.IsReady()
+	assert.False(t, ready)
+	assert.ErrorIs(t, err, ErrChannelsNotReady)
+	assert.ErrorContains(t, err, "workspace beta:")
+	assert.NoError(t, ws.CheckAuth(context.Background()))
+
+	syncs := ws.SyncStatuses()
+	require.Len(t, syncs, 2)
+	assert.Equal(t, "beta", syncs[0].Workspace)
+	assert.Equal(t, "rate limited", syncs[0].Error)
+
+	_, err = NewWorkspaces([]*ApiProvider{acme, acme}, "")
+	assert.ErrorContains(t, err, "configured twice")
+	_, err = NewWorkspaces([]*ApiProvider{acme}, "beta")
+	assert.ErrorContains(t, err, "unknown default workspace")
+
+	single, err := NewWorkspaces([]*ApiProvider{beta}, "")
+	require.NoError(t, err)
+	_, err = single.IsReady()
+	assert.Equal(t, ErrChannelsNotReady, err, "a single workspace is not named in errors")
+	assert.Empty(t, single.SyncStatuses()[0].Workspace)
+}
+
+func TestUnitCacheFilesOfWorkspace(t *testing.T) {
+	cfg := config.Default()
+	cfg.XOXPToken = "xoxp-1"
+	assert.Equal(t, []CacheFile{
+		{Name: "users", Path: ".users_cache.json"},
+		{Name: "channels", Path: ".channels_cache.json"},
+		{Name: "emoji", Path: ".emoji_cache.json"},
+	}, CacheFiles(cfg))
+
+	cfg.WorkspaceName = "acme"
+	cfg.EmojiCache = "/var/cache/emoji.json"
+	assert.Equal(t, []CacheFile{
+		{Name: "users", Path: ".users_cache_acme.json"},
+		{Name: "channels", Path: ".channels_cache_acme.json"},
+		{Name: "emoji", Path: "/var/cache/emoji.json"},
+	}, CacheFiles(cfg))
+}
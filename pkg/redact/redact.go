@@ -0,0 +1,251 @@
+// Package redact removes personal data from content read from Slack before
+// it reaches the model. Values are replaced with pseudonyms derived from a
+// keyed hash, so the same email or person gets the same placeholder for as
+// long as the key stays the same.
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	FieldText     = "text"
+	FieldRealName = "real_name"
+	FieldUserName = "user_name"
+
+	DetectorEmail      = "email"
+	DetectorPhone      = "phone"
+	DetectorCreditCard = "credit_card"
+	DetectorPatterns   = "patterns"
+
+	ModeOff       = "off"
+	ModeRedact    = "redact"
+	ModePseudonym = "pseudonym"
+)
+
+const redacted = "[REDACTED]"
+
+var (
+	emailRe = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`)
+	phoneRe = regexp.MustCompile(`\+\d[\d ().-]{6,}\d|\(?\b\d{3}\)?[ .-]\d{3}[ .-]\d{4}\b`)
+	cardRe  = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+)
+
+type detector struct {
+	name  string
+	label string
+	re    *regexp.Regexp
+	valid func(string) bool
+}
+
+// Config configures the redaction rules per field.
+type Config struct {
+	// Rules are semicolon separated field=value pairs, e.g.
+	// "text=email,phone,credit_card,patterns;real_name=pseudonym;user_name=redact".
+	// The text field takes a list of detectors, name fields off, redact or
+	// pseudonym.
+	Rules string
+	// Patterns are extra regular expressions redacted by the patterns detector.
+	Patterns []string
+	// Key seeds the pseudonyms, a random key is used when empty, so
+	// pseudonyms only hold for the lifetime of the process.
+	Key string
+}
+
+// Redactor applies the configured rules. A nil Redactor leaves everything as is.
+type Redactor struct {
+	key       []byte
+	detectors []detector
+	realName  string
+	userName  string
+}
+
+func New(cfg Config) (*Redactor, error) {
+	r := &Redactor{realName: ModeOff, userName: ModeOff}
+
+	var text []string
+	for _, rule := range strings.Split(cfg.Rules, ";") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		field, value, ok := strings.Cut(rule, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid redaction rule %q, expected field=value", rule)
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(field) {
+		case FieldText:
+			for _, d := range strings.Split(value, ",") {
+				if d = strings.TrimSpace(d); d != "" {
+					text = append(text, d)
+				}
+			}
+		case FieldRealName, FieldUserName:
+			if value != ModeOff && value != ModeRedact && value != ModePseudonym {
+				return nil, fmt.Errorf("invalid redaction mode %q for %s, expected off, redact or pseudonym", value, field)
+			}
+			if strings.TrimSpace(field) == FieldRealName {
+				r.realName = value
+			} else {
+				r.userName = value
+			}
+		default:
+			return nil, fmt.Errorf("unknown redaction field %q, expected %s, %s or %s", field, FieldText, FieldRealName, FieldUserName)
+		}
+	}
+
+	for _, name := range text {
+		switch name {
+		case DetectorEmail:
+			r.detectors = append(r.detectors, detector{name: name, label: "EMAIL", re: emailRe})
+		case DetectorPhone:
+			r.detectors = append(r.detectors, detector{name: name, label: "PHONE", re: phoneRe, valid: validPhone})
+		case DetectorCreditCard:
+			r.detectors = append(r.detectors, detector{name: name, label: "CARD", re: cardRe, valid: luhn})
+		case DetectorPatterns:
+			for _, p := range cfg.Patterns {
+				re, err := regexp.Compile(p)
+				if err != nil {
+					return nil, fmt.Errorf("invalid redaction pattern %q: %w", p, err)
+				}
+				r.detectors = append(r.detectors, detector{name: name, label: "REDACTED", re: re})
+			}
+		default:
+			return nil, fmt.Errorf("unknown redaction detector %q, expected %s, %s, %s or %s",
+				name, DetectorEmail, DetectorPhone, DetectorCreditCard, DetectorPatterns)
+		}
+	}
+
+	if cfg.Key != "" {
+		r.key = []byte(cfg.Key)
+	} else {
+		r.key = make([]byte, 32)
+		if _, err := rand.Read(r.key); err != nil {
+			return nil, fmt.Errorf("failed to generate redaction key: %w", err)
+		}
+	}
+
+	return r, nil
+}
+
+// Enabled reports whether any field is redacted.
+func (r *Redactor) Enabled() bool {
+	return r != nil && (len(r.detectors) > 0 || r.realName != ModeOff || r.userName != ModeOff)
+}
+
+// RedactsNames reports whether real names are redacted, for texts made of
+// names that cannot be tied back to a user.
+func (r *Redactor) RedactsNames() bool {
+	return r != nil && r.realName != ModeOff
+}
+
+// Text replaces emails, phone numbers, card numbers and configured patterns
+// with placeholders such as [EMAIL_1a2b3c].
+func (r *Redactor) Text(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+	for _, d := range r.detectors {
+		s = d.re.ReplaceAllStringFunc(s, func(m string) string {
+			if d.valid != nil && !d.valid(m) {
+				return m
+			}
+			return "[" + d.label + "_" + r.pseudonym(d.label, normalize(d.name, m)) + "]"
+		})
+	}
+	return s
+}
+
+// RealName redacts the real name of a user, pseudonyms are derived from the
+// user ID when known, so they match the pseudonym of the user name.
+func (r *Redactor) RealName(userID, name string) string {
+	if r == nil || name == "" {
+		return name
+	}
+	return r.name(r.realName, "Person ", userID, name)
+}
+
+// UserName redacts the handle of a user.
+func (r *Redactor) UserName(userID, name string) string {
+	if r == nil || name == "" {
+		return name
+	}
+	return r.name(r.userName, "user_", userID, name)
+}
+
+func (r *Redactor) name(mode, prefix, userID, name string) string {
+	switch mode {
+	case ModeRedact:
+		return redacted
+	case ModePseudonym:
+		id := userID
+		if id == "" {
+			id = name
+		}
+		return prefix + r.pseudonym("USER", id)
+	default:
+		return name
+	}
+}
+
+func (r *Redactor) pseudonym(kind, value string) string {
+	mac := hmac.New(sha256.New, r.key)
+	mac.Write([]byte(kind + "\x00" + value))
+	return hex.EncodeToString(mac.Sum(nil))[:6]
+}
+
+// normalize makes formatting variants of the same value share a pseudonym.
+func normalize(detector, s string) string {
+	switch detector {
+	case DetectorEmail:
+		return strings.ToLower(s)
+	case DetectorPhone, DetectorCreditCard:
+		return digits(s)
+	default:
+		return s
+	}
+}
+
+func digits(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		if c >= '0' && c <= '9' {
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+func validPhone(s string) bool {
+	n := len(digits(s))
+	return n >= 9 && n <= 15
+}
+
+// luhn tells card numbers apart from other long numbers.
+func luhn(s string) bool {
+	d := digits(s)
+	if len(d) < 13 || len(d) > 19 {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(d) - 1; i >= 0; i-- {
+		n := int(d[i] - '0')
+		if double {
+			n *= 2
+			if n > 9 {
+				n -= 9
+			}
+		}
+		sum += n
+		double = !double
+	}
+	return sum%10 == 0
+}
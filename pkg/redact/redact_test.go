@@ -0,0 +1,95 @@
+package redact
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var placeholderRe = regexp.MustCompile(`\[(EMAIL|PHONE|CARD|REDACTED)_[0-9a-f]{6}\]`)
+
+func TestUnitRedactText(t *testing.T) {
+	r, err := New(Config{
+		Rules:    "text=email,phone,credit_card,patterns",
+		Patterns: []string{`EMP-\d{5}`},
+		Key:      "secret",
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		text  string
+		label string
+	}{
+		{"email", "write to Jane.Doe@example.co.uk today", "EMAIL"},
+		{"international phone", "call +44 20 7946 0958", "PHONE"},
+		{"us phone", "call (555) 123-4567", "PHONE"},
+		{"card", "card 4111 1111 1111 1111 expires", "CARD"},
+		{"pattern", "badge EMP-12345", "REDACTED"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := r.Text(tt.text)
+			m := placeholderRe.FindStringSubmatch(out)
+			require.NotNil(t, m, out)
+			assert.Equal(t, tt.label, m[1])
+		})
+	}
+
+	// numbers that fail the Luhn check or are too short stay
+	assert.Equal(t, "order 4111 1111 1111 1112", r.Text("order 4111 1111 1111 1112"))
+	assert.Equal(t, "build 1234567", r.Text("build 1234567"))
+	assert.Equal(t, "release 1.2.3", r.Text("release 1.2.3"))
+
+	// formatting variants share a pseudonym
+	assert.Equal(t, r.Text("JANE@example.com"), r.Text("jane@example.com"))
+	assert.Equal(t, r.Text("4111-1111-1111-1111"), r.Text("4111 1111 1111 1111"))
+	assert.NotEqual(t, r.Text("jane@example.com"), r.Text("john@example.com"))
+}
+
+func TestUnitRedactNames(t *testing.T) {
+	r, err := New(Config{Rules: "real_name=pseudonym;user_name=redact", Key: "secret"})
+	require.NoError(t, err)
+	assert.True(t, r.Enabled())
+	assert.True(t, r.RedactsNames())
+
+	assert.Equal(t, "Person "+r.pseudonym("USER", "U1"), r.RealName("U1", "Jane Doe"))
+	assert.Equal(t, r.RealName("U1", "Jane Doe"), r.RealName("U1", "Jane D."))
+	assert.NotEqual(t, r.RealName("U1", "Jane Doe"), r.RealName("U2", "Jane Doe"))
+	assert.Equal(t, "[REDACTED]", r.UserName("U1", "jane"))
+	assert.Equal(t, "", r.UserName("U1", ""))
+	assert.Equal(t, "jane@example.com", r.Text("jane@example.com"))
+
+	// the same key gives the same pseudonyms across restarts
+	r2, err := New(Config{Rules: "real_name=pseudonym", Key: "secret"})
+	require.NoError(t, err)
+	assert.Equal(t, r.RealName("U1", "Jane Doe"), r2.RealName("U1", "Jane Doe"))
+
+	r3, err := New(Config{Rules: "real_name=pseudonym", Key: "other"})
+	require.NoError(t, err)
+	assert.NotEqual(t, r.RealName("U1", "Jane Doe"), r3.RealName("U1", "Jane Doe"))
+
+	var nilRedactor *Redactor
+	assert.False(t, nilRedactor.Enabled())
+	assert.Equal(t, "jane@example.com", nilRedactor.Text("jane@example.com"))
+	assert.Equal(t, "Jane Doe", nilRedactor.RealName("U1", "Jane Doe"))
+}
+
+func TestUnitRedactConfig(t *testing.T) {
+	r, err := New(Config{Rules: "real_name=off"})
+	require.NoError(t, err)
+	assert.False(t, r.Enabled())
+
+	_, err = New(Config{Rules: "text=ssn"})
+	assert.Error(t, err)
+	_, err = New(Config{Rules: "real_name=hash"})
+	assert.Error(t, err)
+	_, err = New(Config{Rules: "email"})
+	assert.Error(t, err)
+	_, err = New(Config{Rules: "title=redact"})
+	assert.Error(t, err)
+	_, err = New(Config{Rules: "text=patterns", Patterns: []string{"("}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,206 @@
+package approval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"go.uber.org/zap"
+)
+
+// Preview describes what a write tool is about to do, so a human can approve it.
+type Preview struct {
+	Action      string
+	Channel     string
+	ChannelName string
+	ThreadTs    string
+	PostAt      string
+	Text        string
+	Warnings    []string
+}
+
+// PreviewFunc describes a write tool call. It returns an error for calls the
+// tool itself will reject, those are passed through without asking.
+type PreviewFunc func(ctx context.Context, req mcp.CallToolRequest) (*Preview, error)
+
+// Policy tells which channels need a human approval for writes and what to
+// do when the client cannot ask for one.
+type Policy struct {
+	all      bool
+	channels map[string]struct{}
+	// allowFallback approves writes when the client does not support
+	// elicitation, they are denied otherwise.
+	allowFallback bool
+}
+
+// ParsePolicy parses a comma separated list of channel IDs and names, or
+// true/1 for all channels, and the deny or allow fallback.
+func ParsePolicy(channels, fallback string) (*Policy, error) {
+	p := &Policy{channels: make(map[string]struct{})}
+
+	switch strings.ToLower(strings.TrimSpace(fallback)) {
+	case "", "deny":
+	case "allow":
+		p.allowFallback = true
+	default:
+		return nil, fmt.Errorf("invalid approval fallback %q, expected 'deny' or 'allow'", fallback)
+	}
+
+	channels = strings.TrimSpace(channels)
+	if channels == "true" || channels == "1" {
+		p.all = true
+		return p, nil
+	}
+	for _, c := range strings.Split(channels, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			p.channels[c] = struct{}{}
+		}
+	}
+	return p, nil
+}
+
+// Enabled reports whether any write needs an approval.
+func (p *Policy) Enabled() bool {
+	return p.all || len(p.channels) > 0
+}
+
+// Requires reports whether a write to the previewed channel needs an approval.
+func (p *Policy) Requires(preview *Preview) bool {
+	if p.all {
+		return true
+	}
+	if _, ok := p.channels[preview.Channel]; ok {
+		return true
+	}
+	_, ok := p.channels[preview.ChannelName]
+	return ok
+}
+
+// BuildMiddleware creates a middleware that asks the user to confirm calls of
+// the write tools in previews through MCP elicitation before they run. The
+// policy in effect is looked up on every call, so it can be reloaded.
+func BuildMiddleware(currentPolicy func() *Policy, previews map[string]PreviewFunc, logger *zap.Logger) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			policy := currentPolicy()
+			previewFn, ok := previews[req.Params.Name]
+			if !ok || !policy.Enabled() {
+				return next(ctx, req)
+			}
+
+			preview, err := previewFn(ctx, req)
+			if err != nil || !policy.Requires(preview) {
+				return next(ctx, req)
+			}
+
+			approved, err := ask(ctx, preview, policy, logger)
+			if err != nil {
+				return nil, err
+			}
+			if !approved {
+				logger.Info("Write rejected by user",
+					zap.String("tool", req.Params.Name),
+					zap.String("channel", preview.Channel),
+				)
+				return nil, fmt.Errorf("%s to %s was not approved by the user", req.Params.Name, preview.target())
+			}
+
+			logger.Debug("Write approved by user",
+				zap.String("tool", req.Params.Name),
+				zap.String("channel", preview.Channel),
+			)
+			return next(ctx, req)
+		}
+	}
+}
+
+func ask(ctx context.Context, preview *Preview, policy *Policy, logger *zap.Logger) (bool, error) {
+	s := server.ServerFromContext(ctx)
+	if s == nil || !clientSupportsElicitation(ctx) {
+		return fallback(preview, policy, logger)
+	}
+
+	res, err := s.RequestElicitation(ctx, mcp.ElicitationRequest{
+		Params: mcp.ElicitationParams{
+			Message: preview.Message(),
+			RequestedSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"approve": map[string]any{
+						"type":        "boolean",
+						"title":       "Approve",
+						"description": "Allow the assistant to " + preview.Action + " to " + preview.target(),
+					},
+				},
+				"required": []string{"approve"},
+			},
+		},
+	})
+	if errors.Is(err, server.ErrElicitationNotSupported) || errors.Is(err, server.ErrNoActiveSession) {
+		return fallback(preview, policy, logger)
+	}
+	if err != nil {
+		logger.Error("Elicitation request failed", zap.Error(err))
+		return false, fmt.Errorf("failed to ask the user for approval: %w", err)
+	}
+
+	if res.Action != mcp.ElicitationResponseActionAccept {
+		return false, nil
+	}
+	content, _ := res.Content.(map[string]any)
+	approve, _ := content["approve"].(bool)
+	return approve, nil
+}
+
+func fallback(preview *Preview, policy *Policy, logger *zap.Logger) (bool, error) {
+	if policy.allowFallback {
+		logger.Warn("Client does not support elicitation, write allowed by SLACK_MCP_APPROVAL_FALLBACK",
+			zap.String("channel", preview.Channel),
+		)
+		return true, nil
+	}
+	return false, fmt.Errorf("writing to %s requires a human approval, but the client does not support MCP elicitation; set SLACK_MCP_APPROVAL_FALLBACK=allow to skip the approval", preview.target())
+}
+
+func clientSupportsElicitation(ctx context.Context) bool {
+	session := server.ClientSessionFromContext(ctx)
+	if session == nil {
+		return false
+	}
+	if _, ok := session.(server.SessionWithElicitation); !ok {
+		return false
+	}
+	if info, ok := session.(server.SessionWithClientInfo); ok {
+		return info.GetClientCapabilities().Elicitation != nil
+	}
+	return true
+}
+
+// Message renders the preview shown to the user.
+func (p *Preview) Message() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "The assistant wants to %s to %s", p.Action, p.target())
+	if p.ThreadTs != "" {
+		fmt.Fprintf(&b, " in thread %s", p.ThreadTs)
+	}
+	if p.PostAt != "" {
+		fmt.Fprintf(&b, " at %s", p.PostAt)
+	}
+	b.WriteString(":\n\n")
+	b.WriteString(p.Text)
+	if len(p.Warnings) > 0 {
+		b.WriteString("\n\nWarnings:\n- ")
+		b.WriteString(strings.Join(p.Warnings, "\n- "))
+	}
+	return b.String()
+}
+
+func (p *Preview) target() string {
+	if p.ChannelName != "" && p.ChannelName != p.Channel {
+		return p.ChannelName + " (" + p.Channel + ")"
+	}
+	return p.Channel
+}
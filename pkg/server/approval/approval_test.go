@@ -0,0 +1,133 @@
+package approval
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type elicitFunc func(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error)
+
+func (f elicitFunc) Elicit(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+	return f(ctx, request)
+}
+
+func TestUnitParsePolicy(t *testing.T) {
+	p, err := ParsePolicy("", "")
+	require.NoError(t, err)
+	assert.False(t, p.Enabled())
+
+	p, err = ParsePolicy("C123, #announcements", "allow")
+	require.NoError(t, err)
+	assert.True(t, p.Enabled())
+	assert.True(t, p.allowFallback)
+	assert.True(t, p.Requires(&Preview{Channel: "C123"}))
+	assert.True(t, p.Requires(&Preview{Channel: "C999", ChannelName: "#announcements"}))
+	assert.False(t, p.Requires(&Preview{Channel: "C999", ChannelName: "#random"}))
+
+	p, err = ParsePolicy("true", "deny")
+	require.NoError(t, err)
+	assert.True(t, p.Requires(&Preview{Channel: "D1"}))
+
+	_, err = ParsePolicy("C123", "maybe")
+	assert.Error(t, err)
+}
+
+func TestUnitMiddlewareFallback(t *testing.T) {
+	previews := map[string]PreviewFunc{
+		"post": func(ctx context.Context, req mcp.CallToolRequest) (*Preview, error) {
+			return &Preview{Action: "post a message", Channel: req.GetString("channel_id", "")}, nil
+		},
+	}
+	next := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("posted"), nil
+	}
+	call := func(tool, channel string) mcp.CallToolRequest {
+		var req mcp.CallToolRequest
+		req.Params.Name = tool
+		req.Params.Arguments = map[string]any{"channel_id": channel}
+		return req
+	}
+
+	deny, err := ParsePolicy("C1", "deny")
+	require.NoError(t, err)
+	h := BuildMiddleware(static(deny), previews, zap.NewNop())(next)
+
+	_, err = h(context.Background(), call("post", "C1"))
+	assert.ErrorContains(t, err, "requires a human approval")
+
+	res, err := h(context.Background(), call("post", "C2"))
+	require.NoError(t, err)
+	assert.NotNil(t, res)
+
+	res, err = h(context.Background(), call("read", "C1"))
+	require.NoError(t, err)
+	assert.NotNil(t, res)
+
+	allow, err := ParsePolicy("C1", "allow")
+	require.NoError(t, err)
+	res, err = BuildMiddleware(static(allow), previews, zap.NewNop())(next)(context.Background(), call("post", "C1"))
+	require.NoError(t, err)
+	assert.NotNil(t, res)
+}
+
+func TestUnitMiddlewareElicitation(t *testing.T) {
+	policy, err := ParsePolicy("true", "deny")
+	require.NoError(t, err)
+
+	previews := map[string]PreviewFunc{
+		"post": func(ctx context.Context, req mcp.CallToolRequest) (*Preview, error) {
+			return &Preview{Action: "post a message", Channel: "C1", ChannelName: "#general", Text: req.GetString("payload", "")}, nil
+		},
+	}
+
+	s := server.NewMCPServer("test", "1.0",
+		server.WithElicitation(),
+		server.WithToolHandlerMiddleware(BuildMiddleware(static(policy), previews, zap.NewNop())),
+	)
+	s.AddTool(mcp.NewTool("post", mcp.WithString("payload")), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("posted"), nil
+	})
+
+	var asked string
+	approve := true
+	session := server.NewInProcessSessionWithHandlers("test", nil, elicitFunc(func(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+		asked = request.Params.Message
+		return &mcp.ElicitationResult{ElicitationResponse: mcp.ElicitationResponse{
+			Action:  mcp.ElicitationResponseActionAccept,
+			Content: map[string]any{"approve": approve},
+		}}, nil
+	}))
+	session.SetClientCapabilities(mcp.ClientCapabilities{Elicitation: &struct{}{}})
+	require.NoError(t, s.RegisterSession(context.Background(), session))
+	ctx := s.WithContext(context.Background(), session)
+
+	callTool := func() mcp.JSONRPCMessage {
+		raw, _ := json.Marshal(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      1,
+			"method":  "tools/call",
+			"params":  map[string]any{"name": "post", "arguments": map[string]any{"payload": "hello"}},
+		})
+		return s.HandleMessage(ctx, raw)
+	}
+
+	_, ok := callTool().(mcp.JSONRPCResponse)
+	assert.True(t, ok)
+	assert.Contains(t, asked, "#general (C1)")
+	assert.Contains(t, asked, "hello")
+
+	approve = false
+	_, ok = callTool().(mcp.JSONRPCError)
+	assert.True(t, ok)
+}
+
+func static(p *Policy) func() *Policy {
+	return func() *Policy { return p }
+}
@@ -0,0 +1,82 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/audit"
+	"github.com/korotovsky/slack-mcp-server/pkg/server/auth"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"go.uber.org/zap"
+)
+
+// BuildMiddleware creates a middleware recording every tool call with its
+// caller, outcome and the channel the handler resolved.
+func BuildMiddleware(auditor *audit.Auditor, logger *zap.Logger) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		if auditor == nil {
+			return next
+		}
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			call := &audit.Record{
+				Kind:   audit.KindToolCall,
+				Caller: callerFromContext(ctx),
+				Tool:   req.Params.Name,
+				Status: audit.StatusOK,
+			}
+			start := time.Now()
+
+			res, err := next(audit.WithCall(ctx, auditor, call), req)
+
+			call.DurationMs = time.Since(start).Milliseconds()
+			switch {
+			case err != nil:
+				call.Status = audit.StatusError
+				call.Error = err.Error()
+			case res != nil && res.IsError:
+				call.Status = audit.StatusError
+				call.Error = resultText(res)
+			}
+			auditor.Log(*call)
+
+			logger.Debug("Tool call audited",
+				zap.String("tool", call.Tool),
+				zap.String("status", call.Status),
+			)
+			return res, err
+		}
+	}
+}
+
+func callerFromContext(ctx context.Context) audit.Caller {
+	var c audit.Caller
+	if token := auth.TokenFromContext(ctx); token != "" {
+		sum := sha256.Sum256([]byte(token))
+		c.APIKey = "sha256:" + hex.EncodeToString(sum[:])[:12]
+	}
+	session := server.ClientSessionFromContext(ctx)
+	if session == nil {
+		return c
+	}
+	c.Session = session.SessionID()
+	if info, ok := session.(server.SessionWithClientInfo); ok {
+		impl := info.GetClientInfo()
+		c.Client = impl.Name
+		if impl.Version != "" {
+			c.Client += "/" + impl.Version
+		}
+	}
+	return c
+}
+
+func resultText(res *mcp.CallToolResult) string {
+	for _, c := range res.Content {
+		if t, ok := c.(mcp.TextContent); ok {
+			return t.Text
+		}
+	}
+	return ""
+}
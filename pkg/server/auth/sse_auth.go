@@ -5,7 +5,6 @@ import (
 	"crypto/subtle"
 	"fmt"
 	"net/http"
-	"os"
 	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -21,17 +20,32 @@ func withAuthKey(ctx context.Context, auth string) context.Context {
 	return context.WithValue(ctx, authKey{}, auth)
 }
 
+// TokenFromContext returns the Authorization header of the request, empty
+// for stdio.
+func TokenFromContext(ctx context.Context) string {
+	token, _ := ctx.Value(authKey{}).(string)
+	return strings.TrimPrefix(token, "Bearer ")
+}
+
+// slackTokensKey is the context key of the Slack tokens of a request.
+type slackTokensKey struct{}
+
+// slackTokens are the Slack tokens a client sends in multi-tenant mode.
+type slackTokens struct {
+	token  string
+	cookie string
+}
+
+// SlackTokensFromContext returns the Slack token and the xoxd cookie sent in
+// the X-Slack-Token and X-Slack-Cookie headers, empty for stdio.
+func SlackTokensFromContext(ctx context.Context) (token, cookie string) {
+	t, _ := ctx.Value(slackTokensKey{}).(slackTokens)
+	return t.token, t.cookie
+}
+
 // Authenticate checks if the request is authenticated based on the provided context.
-func validateToken(ctx context.Context, logger *zap.Logger) (bool, error) {
+func validateToken(ctx context.Context, keyA string, logger *zap.Logger) (bool, error) {
 	// no configured token means no authentication
-	keyA := os.Getenv("SLACK_MCP_API_KEY")
-	if keyA == "" {
-		keyA = os.Getenv("SLACK_MCP_SSE_API_KEY")
-		if keyA != "" {
-			logger.Warn("SLACK_MCP_SSE_API_KEY is deprecated, please use SLACK_MCP_API_KEY")
-		}
-	}
-
 	if keyA == "" {
 		logger.Debug("No SSE API key configured, skipping authentication",
 			zap.String("context", "http"),
@@ -69,16 +83,21 @@ func validateToken(ctx context.Context, logger *zap.Logger) (bool, error) {
 	return true, nil
 }
 
-// AuthFromRequest extracts the auth token from the request headers.
+// AuthFromRequest extracts the auth token and the Slack tokens from the
+// request headers.
 func AuthFromRequest(logger *zap.Logger) func(context.Context, *http.Request) context.Context {
 	return func(ctx context.Context, r *http.Request) context.Context {
 		authHeader := r.Header.Get("Authorization")
+		ctx = context.WithValue(ctx, slackTokensKey{}, slackTokens{
+			token:  strings.TrimSpace(r.Header.Get("X-Slack-Token")),
+			cookie: strings.TrimSpace(r.Header.Get("X-Slack-Cookie")),
+		})
 		return withAuthKey(ctx, authHeader)
 	}
 }
 
 // BuildMiddleware creates a middleware function that ensures authentication based on the provided transport type.
-func BuildMiddleware(transport string, logger *zap.Logger) server.ToolHandlerMiddleware {
+func BuildMiddleware(transport string, apiKey string, logger *zap.Logger) server.ToolHandlerMiddleware {
 	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
 		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			logger.Debug("Auth middleware invoked",
@@ -87,7 +106,7 @@ func BuildMiddleware(transport string, logger *zap.Logger) server.ToolHandlerMid
 				zap.String("tool", req.Params.Name),
 			)
 
-			if authenticated, err := IsAuthenticated(ctx, transport, logger); !authenticated {
+			if authenticated, err := IsAuthenticated(ctx, transport, apiKey, logger); !authenticated {
 				logger.Error("Authentication failed",
 					zap.String("context", "http"),
 					zap.String("transport", transport),
@@ -109,13 +128,13 @@ func BuildMiddleware(transport string, logger *zap.Logger) server.ToolHandlerMid
 }
 
 // IsAuthenticated public api
-func IsAuthenticated(ctx context.Context, transport string, logger *zap.Logger) (bool, error) {
+func IsAuthenticated(ctx context.Context, transport string, apiKey string, logger *zap.Logger) (bool, error) {
 	switch transport {
 	case "stdio":
 		return true, nil
 
 	case "sse", "http":
-		authenticated, err := validateToken(ctx, logger)
+		authenticated, err := validateToken(ctx, apiKey, logger)
 
 		if err != nil {
 			logger.Error("HTTP/SSE authentication error",
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/version"
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// CallTool calls a tool through an in-process MCP session, with the same
+// middlewares as a remote client. The arguments are given as text, e.g. from
+// the command line, and converted to the types of the tool input schema.
+func (s *MCPServer) CallTool(ctx context.Context, name string, args map[string]string) (*mcp.CallToolResult, error) {
+	tool := s.server.GetTool(name)
+	if tool == nil {
+		return nil, fmt.Errorf("unknown tool %q", name)
+	}
+	arguments, err := toolArguments(tool.Tool.InputSchema, args)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := client.NewInProcessClient(s.server)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	if err := c.Start(ctx); err != nil {
+		return nil, err
+	}
+
+	var initReq mcp.InitializeRequest
+	initReq.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initReq.Params.ClientInfo = mcp.Implementation{Name: "slack-mcp-server-cli", Version: version.Version}
+	if _, err := c.Initialize(ctx, initReq); err != nil {
+		return nil, err
+	}
+
+	var req mcp.CallToolRequest
+	req.Params.Name = name
+	req.Params.Arguments = arguments
+	return c.CallTool(ctx, req)
+}
+
+// toolArguments converts text arguments to the types declared in schema.
+// Arrays and objects are given as JSON, unknown arguments are rejected.
+func toolArguments(schema mcp.ToolInputSchema, args map[string]string) (map[string]any, error) {
+	res := make(map[string]any, len(args))
+	for k, raw := range args {
+		prop, ok := schema.Properties[k].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("unknown argument %q", k)
+		}
+
+		typ, _ := prop["type"].(string)
+		var (
+			v   any
+			err error
+		)
+		switch typ {
+		case "boolean":
+			v, err = strconv.ParseBool(raw)
+		case "number":
+			v, err = strconv.ParseFloat(raw, 64)
+		case "integer":
+			v, err = strconv.ParseInt(raw, 10, 64)
+		case "array", "object":
+			err = json.Unmarshal([]byte(raw), &v)
+		default:
+			v = raw
+		}
+		if err != nil {
+			return nil, fmt.Errorf("argument %q must be of type %s, got %q", k, typ, raw)
+		}
+		res[k] = v
+	}
+	return res, nil
+}
@@ -0,0 +1,38 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitToolArguments(t *testing.T) {
+	tool := mcp.NewTool("test",
+		mcp.WithString("channel_id"),
+		mcp.WithBoolean("include_activity_messages"),
+		mcp.WithNumber("limit"),
+		mcp.WithArray("ids"),
+	)
+
+	args, err := toolArguments(tool.InputSchema, map[string]string{
+		"channel_id":                "#general",
+		"include_activity_messages": "true",
+		"limit":                     "50",
+		"ids":                       `["a","b"]`,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"channel_id":                "#general",
+		"include_activity_messages": true,
+		"limit":                     50.0,
+		"ids":                       []any{"a", "b"},
+	}, args)
+
+	_, err = toolArguments(tool.InputSchema, map[string]string{"limit": "many"})
+	assert.ErrorContains(t, err, `argument "limit" must be of type number`)
+
+	_, err = toolArguments(tool.InputSchema, map[string]string{"bogus": "1"})
+	assert.ErrorContains(t, err, `unknown argument "bogus"`)
+}
@@ -0,0 +1,76 @@
+// Package drain tracks tool calls in flight so that a shutdown can wait for
+// them to finish while rejecting new ones.
+package drain
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Drainer counts the tool calls in flight. The zero value is ready to use.
+type Drainer struct {
+	mu       sync.Mutex
+	draining bool
+	active   sync.WaitGroup
+}
+
+func (d *Drainer) enter() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.draining {
+		return false
+	}
+	d.active.Add(1)
+	return true
+}
+
+// Begin stops accepting new tool calls.
+func (d *Drainer) Begin() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.draining = true
+}
+
+// Draining reports whether Begin was called.
+func (d *Drainer) Draining() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.draining
+}
+
+// Wait stops accepting new tool calls and waits for the ones in flight to
+// finish, or for ctx to be done.
+func (d *Drainer) Wait(ctx context.Context) error {
+	d.Begin()
+
+	done := make(chan struct{})
+	go func() {
+		d.active.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// BuildMiddleware creates a middleware that tracks tool calls for Wait and
+// rejects them with a tool error once the server is shutting down.
+func (d *Drainer) BuildMiddleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if !d.enter() {
+				return mcp.NewToolResultError("server is shutting down, retry the call on another instance or after the restart"), nil
+			}
+			defer d.active.Done()
+
+			return next(ctx, req)
+		}
+	}
+}
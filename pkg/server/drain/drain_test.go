@@ -0,0 +1,45 @@
+package drain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitDrainer(t *testing.T) {
+	var d Drainer
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := d.BuildMiddleware()(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		close(started)
+		<-release
+		return mcp.NewToolResultText("done"), nil
+	})
+
+	results := make(chan *mcp.CallToolResult, 1)
+	go func() {
+		res, _ := handler(context.Background(), mcp.CallToolRequest{})
+		results <- res
+	}()
+	<-started
+
+	// the call in flight outlives a short deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, d.Wait(ctx), context.DeadlineExceeded)
+	assert.True(t, d.Draining())
+
+	// new calls are rejected while draining
+	res, err := handler(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	assert.True(t, res.IsError)
+
+	close(release)
+	require.NoError(t, d.Wait(context.Background()))
+	assert.False(t, (<-results).IsError)
+}
@@ -0,0 +1,63 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/limiter"
+	"github.com/korotovsky/slack-mcp-server/pkg/server/approval"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"go.uber.org/zap"
+)
+
+// BuildMiddleware creates a middleware that charges calls of the write tools
+// in previews to the write quotas that quotaFor returns for the request. A
+// call over quota is rejected with a tool error whose structured content is
+// the limiter.QuotaError. Only writes that succeed stay charged and count for
+// the duplicate check.
+func BuildMiddleware(quotaFor func(context.Context) *limiter.Quota, previews map[string]approval.PreviewFunc, logger *zap.Logger) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			previewFn, ok := previews[req.Params.Name]
+			if !ok {
+				return next(ctx, req)
+			}
+			q := quotaFor(ctx)
+			if q == nil || !q.Config().Enabled() {
+				return next(ctx, req)
+			}
+
+			preview, err := previewFn(ctx, req)
+			if err != nil {
+				return next(ctx, req)
+			}
+
+			reservation, err := q.Reserve(preview.Channel, preview.ThreadTs, req.GetString("payload", ""), time.Now())
+			var qe *limiter.QuotaError
+			if errors.As(err, &qe) {
+				logger.Warn("Write quota exceeded",
+					zap.String("tool", req.Params.Name),
+					zap.String("scope", qe.Scope),
+					zap.String("key", qe.Key),
+					zap.Float64("retry_after", qe.RetryAfter),
+				)
+				res := mcp.NewToolResultStructured(qe, qe.Error())
+				res.IsError = true
+				return res, nil
+			}
+			if err != nil {
+				return next(ctx, req)
+			}
+
+			res, err := next(ctx, req)
+			if err != nil || res == nil || res.IsError {
+				reservation.Cancel()
+			} else {
+				reservation.Commit(time.Now())
+			}
+			return res, err
+		}
+	}
+}
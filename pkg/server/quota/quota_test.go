@@ -0,0 +1,66 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/limiter"
+	"github.com/korotovsky/slack-mcp-server/pkg/server/approval"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestUnitMiddlewareChargesSuccessfulWrites(t *testing.T) {
+	channel, _ := limiter.ParseQuotaRate("2/h")
+	q := limiter.NewQuota(limiter.QuotaConfig{Channel: channel, DuplicateWindow: time.Hour})
+	previews := map[string]approval.PreviewFunc{
+		"post": func(ctx context.Context, req mcp.CallToolRequest) (*approval.Preview, error) {
+			return &approval.Preview{Channel: req.GetString("channel_id", "")}, nil
+		},
+	}
+	var fail error
+	var failResult bool
+	next := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if fail != nil {
+			return nil, fail
+		}
+		if failResult {
+			return mcp.NewToolResultError("channel_not_found"), nil
+		}
+		return mcp.NewToolResultText("posted"), nil
+	}
+	h := BuildMiddleware(func(context.Context) *limiter.Quota { return q }, previews, zap.NewNop())(next)
+	call := func(payload string) *mcp.CallToolResult {
+		t.Helper()
+		var req mcp.CallToolRequest
+		req.Params.Name = "post"
+		req.Params.Arguments = map[string]any{"channel_id": "C1", "payload": payload}
+		res, _ := h(context.Background(), req)
+		return res
+	}
+
+	fail = errors.New("denied")
+	for range 3 {
+		assert.Nil(t, call("hello"))
+	}
+	fail, failResult = nil, true
+	assert.Equal(t, "channel_not_found", call("hello").Content[0].(mcp.TextContent).Text)
+
+	failResult = false
+	res := call("hello")
+	require.False(t, res.IsError, "failed writes charged nothing")
+	assert.Equal(t, "posted", res.Content[0].(mcp.TextContent).Text)
+
+	res = call("hello")
+	require.True(t, res.IsError)
+	assert.Equal(t, limiter.ScopeDuplicate, res.StructuredContent.(*limiter.QuotaError).Scope)
+
+	assert.False(t, call("world").IsError)
+	res = call("again")
+	require.True(t, res.IsError)
+	assert.Equal(t, limiter.ScopeChannel, res.StructuredContent.(*limiter.QuotaError).Scope)
+}
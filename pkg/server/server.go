@@ -2,38 +2,212 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
 	"github.com/korotovsky/slack-mcp-server/pkg/handler"
+	"github.com/korotovsky/slack-mcp-server/pkg/health"
+	"github.com/korotovsky/slack-mcp-server/pkg/limiter"
+	"github.com/korotovsky/slack-mcp-server/pkg/metrics"
 	"github.com/korotovsky/slack-mcp-server/pkg/provider"
+	"github.com/korotovsky/slack-mcp-server/pkg/server/approval"
+	"github.com/korotovsky/slack-mcp-server/pkg/server/audit"
 	"github.com/korotovsky/slack-mcp-server/pkg/server/auth"
-	"github.com/korotovsky/slack-mcp-server/pkg/text"
+	"github.com/korotovsky/slack-mcp-server/pkg/server/drain"
+	"github.com/korotovsky/slack-mcp-server/pkg/server/quota"
+	"github.com/korotovsky/slack-mcp-server/pkg/tracing"
 	"github.com/korotovsky/slack-mcp-server/pkg/version"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
 type MCPServer struct {
-	server *server.MCPServer
-	logger *zap.Logger
+	server     *server.MCPServer
+	workspaces *provider.Workspaces
+	config     *config.Live
+	logger     *zap.Logger
+
+	// tenants are the clients of a multi-tenant server, nil otherwise
+	tenants *tenantPool
+
+	// approvalPolicy is swapped on reload, see Reload.
+	approvalPolicy *atomic.Pointer[approval.Policy]
+
+	drainer   *drain.Drainer
+	checker   *health.Checker
+	transport shutdowner
+	auditor   io.Closer
+
+	// background jobs run until Shutdown
+	ctx        context.Context
+	cancel     context.CancelFunc
+	background sync.WaitGroup
+}
+
+type shutdowner interface {
+	Shutdown(ctx context.Context) error
 }
 
-func NewMCPServer(provider *provider.ApiProvider, logger *zap.Logger) *MCPServer {
+func NewMCPServer(live *config.Live, workspaces *provider.Workspaces, logger *zap.Logger) *MCPServer {
+	return newMCPServer(live, workspaces, logger)
+}
+
+// NewMultiTenantMCPServer returns a server without Slack tokens of its own:
+// every client sends its tokens in the X-Slack-Token and X-Slack-Cookie
+// headers and gets a provider and caches of its own, see tenantPool.
+func NewMultiTenantMCPServer(live *config.Live, logger *zap.Logger) *MCPServer {
+	return newMCPServer(live, nil, logger)
+}
+
+// newMCPServer serves workspaces, or the tenants of the requests when
+// workspaces is nil.
+func newMCPServer(live *config.Live, workspaces *provider.Workspaces, logger *zap.Logger) *MCPServer {
+	cfg := live.Get()
+
+	var (
+		conversationsHandler *handler.ConversationsHandler
+		tenants              *tenantPool
+		conversations        func(toolMethod[*handler.ConversationsHandler]) server.ToolHandlerFunc
+		channels             func(toolMethod[*handler.ChannelsHandler]) server.ToolHandlerFunc
+		emoji                func(toolMethod[*handler.EmojiHandler]) server.ToolHandlerFunc
+		writePreviews        map[string]approval.PreviewFunc
+		quotaFor             func(context.Context) *limiter.Quota
+		quotaStatus          server.ToolHandlerFunc
+		newTool              = mcp.NewTool
+		hooks                = &server.Hooks{}
+
+		channelsHandlers      = make(map[string]*handler.ChannelsHandler)
+		conversationsHandlers = make(map[string]*handler.ConversationsHandler)
+	)
+	quotaHandler := handler.NewQuotaHandler(cfg, logger)
+	if workspaces == nil {
+		// the handlers of every tenant share the content filters and the
+		// audit log, the outbox is disabled, every tenant has its own
+		// write quotas
+		conversationsHandler = handler.NewConversationsHandler(nil, live, logger)
+		tenants = newTenantPool(live, func(p *provider.ApiProvider) *toolHandlers {
+			return &toolHandlers{
+				conversations: conversationsHandler.WithProvider(p),
+				channels:      handler.NewChannelsHandler(p, live, logger),
+				emoji:         handler.NewEmojiHandler(p, logger),
+				quota:         quotaHandler.Fresh(),
+			}
+		}, logger)
+		hooks.AddOnUnregisterSession(func(_ context.Context, session server.ClientSession) {
+			tenants.forget(session.SessionID())
+		})
+
+		conversations = func(method toolMethod[*handler.ConversationsHandler]) server.ToolHandlerFunc {
+			return byTenant(tenants, pickConversations, method)
+		}
+		channels = func(method toolMethod[*handler.ChannelsHandler]) server.ToolHandlerFunc {
+			return byTenant(tenants, pickChannels, method)
+		}
+		emoji = func(method toolMethod[*handler.EmojiHandler]) server.ToolHandlerFunc {
+			return byTenant(tenants, pickEmoji, method)
+		}
+		writePreviews = map[string]approval.PreviewFunc{
+			"conversations_add_message": byTenant(tenants, pickConversations, (*handler.ConversationsHandler).AddMessagePreview),
+			"messages_schedule":         byTenant(tenants, pickConversations, (*handler.ConversationsHandler).SchedulePreview),
+		}
+		quotaFor = tenants.quota
+		quotaStatus = byTenant(tenants, pickQuota, (*handler.QuotaHandler).QuotaStatusHandler)
+	} else {
+		defaultProvider := workspaces.Default()
+
+		// the tools of each workspace, the outbox tools are served by the
+		// handler of the default workspace for all of them
+		conversationsHandler = handler.NewConversationsHandler(defaultProvider, live, logger)
+		emojiHandlers := make(map[string]*handler.EmojiHandler)
+		for _, p := range workspaces.All() {
+			ch := conversationsHandler
+			if p != defaultProvider {
+				ch = conversationsHandler.ForWorkspace(p)
+			}
+			conversationsHandlers[p.Workspace()] = ch
+			channelsHandlers[p.Workspace()] = handler.NewChannelsHandler(p, live, logger)
+			emojiHandlers[p.Workspace()] = handler.NewEmojiHandler(p, logger)
+		}
+		conversations = func(method toolMethod[*handler.ConversationsHandler]) server.ToolHandlerFunc {
+			return byWorkspace(workspaces, conversationsHandlers, method)
+		}
+		channels = func(method toolMethod[*handler.ChannelsHandler]) server.ToolHandlerFunc {
+			return byWorkspace(workspaces, channelsHandlers, method)
+		}
+		emoji = func(method toolMethod[*handler.EmojiHandler]) server.ToolHandlerFunc {
+			return byWorkspace(workspaces, emojiHandlers, method)
+		}
+		writePreviews = map[string]approval.PreviewFunc{
+			"conversations_add_message": byWorkspace(workspaces, conversationsHandlers, (*handler.ConversationsHandler).AddMessagePreview),
+			"messages_schedule":         byWorkspace(workspaces, conversationsHandlers, (*handler.ConversationsHandler).SchedulePreview),
+		}
+		newTool = workspaceTool(workspaces)
+		quotaFor = func(context.Context) *limiter.Quota { return quotaHandler.Quota() }
+		quotaStatus = quotaHandler.QuotaStatusHandler
+	}
+
+	policy, err := approval.ParsePolicy(cfg.ApprovalChannels, cfg.ApprovalFallback)
+	if err != nil {
+		logger.Fatal("Invalid write approval policy", zap.Error(err))
+	}
+	approvalPolicy := &atomic.Pointer[approval.Policy]{}
+	approvalPolicy.Store(policy)
+	drainer := &drain.Drainer{}
+
 	s := server.NewMCPServer(
 		"Slack MCP Server",
 		version.Version,
 		server.WithLogging(),
 		server.WithRecovery(),
+		server.WithElicitation(),
+		server.WithHooks(hooks),
+		server.WithToolHandlerMiddleware(drainer.BuildMiddleware()),
+		server.WithToolHandlerMiddleware(buildTracingMiddleware()),
 		server.WithToolHandlerMiddleware(buildLoggerMiddleware(logger)),
-		server.WithToolHandlerMiddleware(auth.BuildMiddleware(provider.ServerTransport(), logger)),
+		server.WithToolHandlerMiddleware(buildMetricsMiddleware()),
+		server.WithToolHandlerMiddleware(audit.BuildMiddleware(conversationsHandler.Auditor(), logger)),
+		server.WithToolHandlerMiddleware(auth.BuildMiddleware(cfg.Transport, cfg.APIKey, logger)),
+		// the quota is reserved before a human is asked to approve a write,
+		// a denied write gives it back
+		server.WithToolHandlerMiddleware(quota.BuildMiddleware(quotaFor, writePreviews, logger)),
+		server.WithToolHandlerMiddleware(approval.BuildMiddleware(approvalPolicy.Load, writePreviews, logger)),
 	)
 
-	conversationsHandler := handler.NewConversationsHandler(provider, logger)
+	ctx, cancel := context.WithCancel(context.Background())
+	ms := &MCPServer{
+		server:     s,
+		workspaces: workspaces,
+		tenants:    tenants,
+		config:     live,
+		logger:     logger,
 
-	s.AddTool(mcp.NewTool("conversations_history",
+		approvalPolicy: approvalPolicy,
+		drainer:        drainer,
+		auditor:        conversationsHandler.Auditor(),
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+	ms.goBackground(conversationsHandler.RunOutbox)
+	if tenants != nil {
+		ms.goBackground(tenants.Run)
+	}
+
+	if cfg.Metrics && workspaces != nil {
+		registerCacheMetrics(workspaces, logger)
+	}
+
+	s.AddTool(newTool("conversations_history",
 		mcp.WithDescription("Get messages from the channel (or DM) by channel_id, the last row/column in the response is used as 'cursor' parameter for pagination if not empty"),
 		mcp.WithString("channel_id",
 			mcp.Required(),
@@ -50,9 +224,13 @@ func NewMCPServer(provider *provider.ApiProvider, logger *zap.Logger) *MCPServer
 			mcp.DefaultString("1d"),
 			mcp.Description("Limit of messages to fetch in format of maximum ranges of time (e.g. 1d - 1 day, 1w - 1 week, 30d - 30 days, 90d - 90 days which is a default limit for free tier history) or number of messages (e.g. 50). Must be empty when 'cursor' is provided."),
 		),
-	), conversationsHandler.ConversationsHistoryHandler)
+		mcp.WithBoolean("include_reaction_users",
+			mcp.Description("If true, the Reactions column will also list who added each reaction, e.g. '✅ 2 (alice, bob)'. Default is boolean false."),
+			mcp.DefaultBool(false),
+		),
+	), conversations((*handler.ConversationsHandler).ConversationsHistoryHandler))
 
-	s.AddTool(mcp.NewTool("conversations_replies",
+	s.AddTool(newTool("conversations_replies",
 		mcp.WithDescription("Get a thread of messages posted to a conversation by channelID and thread_ts, the last row/column in the response is used as 'cursor' parameter for pagination if not empty"),
 		mcp.WithString("channel_id",
 			mcp.Required(),
@@ -73,9 +251,13 @@ func NewMCPServer(provider *provider.ApiProvider, logger *zap.Logger) *MCPServer
 			mcp.DefaultString("1d"),
 			mcp.Description("Limit of messages to fetch in format of maximum ranges of time (e.g. 1d - 1 day, 30d - 30 days, 90d - 90 days which is a default limit for free tier history) or number of messages (e.g. 50). Must be empty when 'cursor' is provided."),
 		),
-	), conversationsHandler.ConversationsRepliesHandler)
+		mcp.WithBoolean("include_reaction_users",
+			mcp.Description("If true, the Reactions column will also list who added each reaction, e.g. '✅ 2 (alice, bob)'. Default is boolean false."),
+			mcp.DefaultBool(false),
+		),
+	), conversations((*handler.ConversationsHandler).ConversationsRepliesHandler))
 
-	s.AddTool(mcp.NewTool("conversations_add_message",
+	s.AddTool(newTool("conversations_add_message",
 		mcp.WithDescription("Add a message to a public channel, private channel, or direct message (DM, or IM) conversation by channel_id and thread_ts."),
 		mcp.WithString("channel_id",
 			mcp.Required(),
@@ -91,9 +273,81 @@ func NewMCPServer(provider *provider.ApiProvider, logger *zap.Logger) *MCPServer
 			mcp.DefaultString("text/markdown"),
 			mcp.Description("Content type of the message. Default is 'text/markdown'. Allowed values: 'text/markdown', 'text/plain'."),
 		),
-	), conversationsHandler.ConversationsAddMessageHandler)
+	), conversations((*handler.ConversationsHandler).ConversationsAddMessageHandler))
+
+	s.AddTool(mcp.NewTool("outbox_list",
+		mcp.WithDescription("List messages queued by conversations_add_message that have not been posted yet. Only available when the outbox is enabled with SLACK_MCP_OUTBOX_DELAY."),
+	), conversationsHandler.OutboxListHandler)
+
+	s.AddTool(mcp.NewTool("outbox_cancel",
+		mcp.WithDescription("Cancel a message queued by conversations_add_message before it is posted"),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("Outbox ID of the queued message as returned by conversations_add_message or outbox_list, e.g. OB1a2b3c4d5e6f."),
+		),
+	), conversationsHandler.OutboxCancelHandler)
+
+	s.AddTool(mcp.NewTool("outbox_flush",
+		mcp.WithDescription("Post queued messages right away instead of waiting for the outbox delay, failed messages are retried"),
+		mcp.WithString("ids",
+			mcp.Description("Comma-separated list of outbox IDs to post. If not provided, every queued message is posted."),
+		),
+	), conversationsHandler.OutboxFlushHandler)
+
+	s.AddTool(mcp.NewTool("quota_status",
+		mcp.WithDescription("Get the configured write quotas and how many writes are left in each, as set by SLACK_MCP_WRITE_QUOTA_* variables. Write tools fail with a quota error once a quota is used up."),
+	), quotaStatus)
+
+	s.AddTool(newTool("messages_schedule",
+		mcp.WithDescription("Schedule a message to be posted later to a public channel, private channel, or direct message (DM, or IM) conversation. Subject to the same channel policy as conversations_add_message."),
+		mcp.WithString("channel_id",
+			mcp.Required(),
+			mcp.Description("ID of the channel in format Cxxxxxxxxxx or its name starting with #... or @... aka #general or @username_dm."),
+		),
+		mcp.WithString("post_at",
+			mcp.Required(),
+			mcp.Description("When to post the message, in the time zone set by SLACK_MCP_TIMEZONE, or else the Slack time zone of the authenticated user (UTC when unknown), unless an offset is given. Examples: 'tomorrow 9am', 'in 2 hours', 'next monday at 14:30', '2025-07-01 14:30', '2025-07-01T14:30:00Z' or a Unix timestamp. A date without a time means 09:00. Must be in the future and at most 120 days ahead."),
+		),
+		mcp.WithString("thread_ts",
+			mcp.Description("Timestamp in format 1234567890.123456 of the thread's parent message. Optional, if not provided the message will be posted to the channel itself, otherwise to the thread."),
+		),
+		mcp.WithString("payload",
+			mcp.Description("Message payload in specified content_type format. Example: 'Hello, world!' for text/plain or '# Hello, world!' for text/markdown."),
+		),
+		mcp.WithString("content_type",
+			mcp.DefaultString("text/markdown"),
+			mcp.Description("Content type of the message. Default is 'text/markdown'. Allowed values: 'text/markdown', 'text/plain'."),
+		),
+	), conversations((*handler.ConversationsHandler).MessagesScheduleHandler))
+
+	s.AddTool(newTool("messages_scheduled_list",
+		mcp.WithDescription("List messages scheduled by the authenticated user that have not been posted yet"),
+		mcp.WithString("channel_id",
+			mcp.Description("ID of the channel in format Cxxxxxxxxxx or its name starting with #... or @... aka #general or @username_dm. If not provided, scheduled messages of all conversations are returned."),
+		),
+		mcp.WithString("cursor",
+			mcp.DefaultString(""),
+			mcp.Description("Cursor for pagination. Use the value of the last row and column in the response as next_cursor field returned from the previous request."),
+		),
+		mcp.WithNumber("limit",
+			mcp.DefaultNumber(100),
+			mcp.Description("The maximum number of items to return. Must be an integer between 1 and 1000."),
+		),
+	), conversations((*handler.ConversationsHandler).MessagesScheduledListHandler))
+
+	s.AddTool(newTool("messages_schedule_cancel",
+		mcp.WithDescription("Cancel a scheduled message before it is posted"),
+		mcp.WithString("channel_id",
+			mcp.Required(),
+			mcp.Description("ID of the channel the message is scheduled in, in format Cxxxxxxxxxx or its name starting with #... or @... aka #general or @username_dm."),
+		),
+		mcp.WithString("scheduled_message_id",
+			mcp.Required(),
+			mcp.Description("ID of the scheduled message as returned by messages_schedule or messages_scheduled_list, e.g. Q1298393284."),
+		),
+	), conversations((*handler.ConversationsHandler).MessagesScheduleCancelHandler))
 
-	s.AddTool(mcp.NewTool("conversations_search_messages",
+	s.AddTool(newTool("conversations_search_messages",
 		mcp.WithDescription("Search messages in a public channel, private channel, or direct message (DM, or IM) conversation using filters. All filters are optional, if not provided then search_query is required."),
 		mcp.WithString("search_query",
 			mcp.Description("Search query to filter messages. Example: 'marketing report' or full URL of Slack message e.g. 'https://slack.com/archives/C1234567890/p1234567890123456', then the tool will return a single message matching given URL, herewith all other parameters will be ignored."),
@@ -133,11 +387,57 @@ func NewMCPServer(provider *provider.ApiProvider, logger *zap.Logger) *MCPServer
 			mcp.DefaultNumber(20),
 			mcp.Description("The maximum number of items to return. Must be an integer between 1 and 100."),
 		),
-	), conversationsHandler.ConversationsSearchHandler)
+		mcp.WithNumber("context_messages",
+			mcp.DefaultNumber(0),
+			mcp.Description("Number of messages before and after each match to include for context. Overlapping context is deduplicated, rows are grouped by conversation and the 'Match' column marks the actual search hits. Must be an integer between 0 and 20."),
+		),
+		mcp.WithBoolean("context_thread",
+			mcp.Description("If true, the whole thread of each threaded match is included for context. Default is boolean false."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithNumber("context_budget",
+			mcp.DefaultNumber(200),
+			mcp.Description("The maximum total number of Slack calls and context messages across all matches, each costs one unit. Matches beyond the budget are returned without context. Must be an integer between 1 and 1000."),
+		),
+		mcp.WithBoolean("include_reaction_users",
+			mcp.Description("If true, the Reactions column will also list who added each reaction, e.g. '✅ 2 (alice, bob)'. Search results carry no reactions, so they are looked up per message, which is slower. Default is boolean false."),
+			mcp.DefaultBool(false),
+		),
+	), conversations((*handler.ConversationsHandler).ConversationsSearchHandler))
+
+	s.AddTool(newTool("messages_get",
+		mcp.WithDescription("Get one or more messages by their Slack permalinks or channel and timestamp pairs, optionally with surrounding messages or the whole thread"),
+		mcp.WithString("messages",
+			mcp.Required(),
+			mcp.Description("Comma-separated list of up to 20 messages, each either a Slack permalink e.g. 'https://example.slack.com/archives/C1234567890/p1234567890123456' or a pair in format channel_id:ts e.g. 'C1234567890:1234567890.123456' or '#general:1234567890.123456'."),
+		),
+		mcp.WithNumber("context_before",
+			mcp.DefaultNumber(0),
+			mcp.Description("Number of messages posted right before each requested message to include. Replies take their context from the thread. Must be an integer between 0 and 100."),
+		),
+		mcp.WithNumber("context_after",
+			mcp.DefaultNumber(0),
+			mcp.Description("Number of messages posted right after each requested message to include. Replies take their context from the thread. Must be an integer between 0 and 100."),
+		),
+		mcp.WithBoolean("include_thread",
+			mcp.Description("If true, the response will include the whole thread each requested message belongs to. Default is boolean false."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithBoolean("include_activity_messages",
+			mcp.Description("If true, the response will include activity messages such as 'channel_join' or 'channel_leave'. Default is boolean false."),
+			mcp.DefaultBool(false),
+		),
+	), conversations((*handler.ConversationsHandler).MessagesGetHandler))
 
-	channelsHandler := handler.NewChannelsHandler(provider, logger)
+	s.AddTool(newTool("reactions_get",
+		mcp.WithDescription("Get all reactions of a single message with the users who added them, one row per reaction and user. Useful to find out who approved a message with a reaction."),
+		mcp.WithString("message",
+			mcp.Required(),
+			mcp.Description("The message, either a Slack permalink or a pair in format channel_id:ts e.g. 'C1234567890:1234567890.123456' or '#general:1234567890.123456'."),
+		),
+	), conversations((*handler.ConversationsHandler).ReactionsGetHandler))
 
-	s.AddTool(mcp.NewTool("channels_list",
+	s.AddTool(newTool("channels_list",
 		mcp.WithDescription("Get list of channels"),
 		mcp.WithString("channel_types",
 			mcp.Required(),
@@ -153,54 +453,107 @@ func NewMCPServer(provider *provider.ApiProvider, logger *zap.Logger) *MCPServer
 		mcp.WithString("cursor",
 			mcp.Description("Cursor for pagination. Use the value of the last row and column in the response as next_cursor field returned from the previous request."),
 		),
-	), channelsHandler.ChannelsHandler)
+	), channels((*handler.ChannelsHandler).ChannelsHandler))
 
-	logger.Info("Authenticating with Slack API...",
-		zap.String("context", "console"),
-	)
-	ar, err := provider.Slack().AuthTest()
-	if err != nil {
-		logger.Fatal("Failed to authenticate with Slack",
+	s.AddTool(newTool("channels_search",
+		mcp.WithDescription("Search channels by name, topic or purpose, including archived channels and channels you are not a member of"),
+		mcp.WithString("query",
+			mcp.Required(),
+			mcp.Description("Text to look for in channel names, topics and purposes. Example: 'incident'"),
+		),
+		mcp.WithNumber("limit",
+			mcp.DefaultNumber(20),
+			mcp.Description("The maximum number of items to return. Must be an integer between 1 and 100."),
+		),
+		mcp.WithString("cursor",
+			mcp.Description("Cursor for pagination. Use the value of the last row and column in the response as next_cursor field returned from the previous request."),
+		),
+	), channels((*handler.ChannelsHandler).ChannelsSearchHandler))
+
+	s.AddTool(newTool("emoji_list",
+		mcp.WithDescription("Get list of the workspace custom emoji, e.g. to pick one to react with"),
+		mcp.WithString("query",
+			mcp.Description("Only return emoji whose name or alias target contains this text. Example: 'party'"),
+		),
+		mcp.WithNumber("limit",
+			mcp.DefaultNumber(100),
+			mcp.Description("The maximum number of items to return. Must be an integer between 1 and 1000."),
+		),
+		mcp.WithString("cursor",
+			mcp.Description("Cursor for pagination. Use the value of the last row and column in the response as next_cursor field returned from the previous request."),
+		),
+	), emoji((*handler.EmojiHandler).EmojiListHandler))
+
+	if tenants != nil {
+		logger.Info("Multi-tenant mode, clients send their Slack tokens in the X-Slack-Token and X-Slack-Cookie headers",
 			zap.String("context", "console"),
-			zap.Error(err),
 		)
+
+		s.AddResourceTemplate(mcp.NewResourceTemplate(
+			"slack://{workspace}/channels",
+			"Directory of Slack channels",
+			mcp.WithTemplateDescription("This resource provides a directory of Slack channels of the workspace of your Slack token."),
+			mcp.WithTemplateMIMEType("text/csv"),
+		), tenantResource(tenants, "channels", func(h *toolHandlers) server.ResourceHandlerFunc {
+			return h.channels.ChannelsResource
+		}))
+
+		s.AddResourceTemplate(mcp.NewResourceTemplate(
+			"slack://{workspace}/users",
+			"Directory of Slack users",
+			mcp.WithTemplateDescription("This resource provides a directory of Slack users of the workspace of your Slack token."),
+			mcp.WithTemplateMIMEType("text/csv"),
+		), tenantResource(tenants, "users", func(h *toolHandlers) server.ResourceHandlerFunc {
+			return h.conversations.UsersResource
+		}))
+
+		return ms
 	}
 
-	logger.Info("Successfully authenticated with Slack",
+	logger.Info("Authenticating with Slack API...",
 		zap.String("context", "console"),
-		zap.String("team", ar.Team),
-		zap.String("user", ar.User),
-		zap.String("enterprise", ar.EnterpriseID),
-		zap.String("url", ar.URL),
 	)
+	for _, p := range workspaces.All() {
+		ar, err := p.Slack().AuthTest()
+		if err != nil {
+			logger.Fatal("Failed to authenticate with Slack",
+				zap.String("context", "console"),
+				zap.String("workspace", p.Workspace()),
+				zap.Error(err),
+			)
+		}
 
-	ws, err := text.Workspace(ar.URL)
-	if err != nil {
-		logger.Fatal("Failed to parse workspace from URL",
+		logger.Info("Successfully authenticated with Slack",
 			zap.String("context", "console"),
+			zap.String("workspace", p.Workspace()),
+			zap.String("team", ar.Team),
+			zap.String("user", ar.User),
+			zap.String("enterprise", ar.EnterpriseID),
 			zap.String("url", ar.URL),
-			zap.Error(err),
 		)
-	}
 
-	s.AddResource(mcp.NewResource(
-		"slack://"+ws+"/channels",
-		"Directory of Slack channels",
-		mcp.WithResourceDescription("This resource provides a directory of Slack channels."),
-		mcp.WithMIMEType("text/csv"),
-	), channelsHandler.ChannelsResource)
-
-	s.AddResource(mcp.NewResource(
-		"slack://"+ws+"/users",
-		"Directory of Slack users",
-		mcp.WithResourceDescription("This resource provides a directory of Slack users."),
-		mcp.WithMIMEType("text/csv"),
-	), conversationsHandler.UsersResource)
-
-	return &MCPServer{
-		server: s,
-		logger: logger,
+		ws := p.Workspace()
+		suffix := ""
+		if workspaces.Multiple() {
+			suffix = " of " + ws
+		}
+
+		s.AddResource(mcp.NewResource(
+			"slack://"+ws+"/channels",
+			"Directory of Slack channels"+suffix,
+			mcp.WithResourceDescription("This resource provides a directory of Slack channels."),
+			mcp.WithMIMEType("text/csv"),
+		), channelsHandlers[ws].ChannelsResource)
+
+		s.AddResource(mcp.NewResource(
+			"slack://"+ws+"/users",
+			"Directory of Slack users"+suffix,
+			mcp.WithResourceDescription("This resource provides a directory of Slack users."),
+			mcp.WithMIMEType("text/csv"),
+		), conversationsHandlers[ws].UsersResource)
 	}
+
+	return ms
 }
 
 func (s *MCPServer) ServeSSE(addr string) *server.SSEServer {
@@ -211,14 +564,19 @@ func (s *MCPServer) ServeSSE(addr string) *server.SSEServer {
 		zap.String("commit_hash", version.CommitHash),
 		zap.String("address", addr),
 	)
-	return server.NewSSEServer(s.server,
+	httpServer := &http.Server{}
+	sseServer := server.NewSSEServer(s.server,
 		server.WithBaseURL(fmt.Sprintf("http://%s", addr)),
+		server.WithHTTPServer(httpServer),
 		server.WithSSEContextFunc(func(ctx context.Context, r *http.Request) context.Context {
 			ctx = auth.AuthFromRequest(s.logger)(ctx, r)
 
 			return ctx
 		}),
 	)
+	httpServer.Handler = s.withOpsEndpoints("/", sseServer)
+	s.transport = sseServer
+	return sseServer
 }
 
 func (s *MCPServer) ServeHTTP(addr string) *server.StreamableHTTPServer {
@@ -229,27 +587,188 @@ func (s *MCPServer) ServeHTTP(addr string) *server.StreamableHTTPServer {
 		zap.String("commit_hash", version.CommitHash),
 		zap.String("address", addr),
 	)
-	return server.NewStreamableHTTPServer(s.server,
+	httpServer := &http.Server{}
+	mcpServer := server.NewStreamableHTTPServer(s.server,
 		server.WithEndpointPath("/mcp"),
+		server.WithStreamableHTTPServer(httpServer),
 		server.WithHTTPContextFunc(func(ctx context.Context, r *http.Request) context.Context {
 			ctx = auth.AuthFromRequest(s.logger)(ctx, r)
 
 			return ctx
 		}),
 	)
+	httpServer.Handler = s.withOpsEndpoints("/mcp", mcpServer)
+	s.transport = mcpServer
+	return mcpServer
+}
+
+// withOpsEndpoints serves the MCP handler at pattern next to the endpoints
+// for operators: /healthz, /readyz and /metrics.
+func (s *MCPServer) withOpsEndpoints(pattern string, mcpHandler http.Handler) http.Handler {
+	var source health.Source = s.workspaces
+	if s.tenants != nil {
+		source = s.tenants
+	}
+	s.checker = health.NewChecker(source, s.config.Get().AuthCheckInterval, s.logger)
+	s.goBackground(s.checker.Run)
+
+	mux := http.NewServeMux()
+	mux.Handle(pattern, s.rejectNewSessions(mcpHandler))
+	mux.HandleFunc("/healthz", s.checker.Healthz)
+	mux.HandleFunc("/readyz", s.checker.Readyz)
+	if s.config.Get().Metrics {
+		mux.Handle("/metrics", metrics.Handler())
+	}
+	return mux
+}
+
+// rejectNewSessions answers 503 to requests that would open a new MCP
+// session once the server is shutting down, requests of existing sessions
+// are still served.
+func (s *MCPServer) rejectNewSessions(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		newSession := r.Header.Get(server.HeaderKeySessionID) == "" && !r.URL.Query().Has("sessionId")
+		if newSession && s.drainer.Draining() {
+			w.Header().Set("Connection", "close")
+			http.Error(w, "server is shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
 }
 
-func (s *MCPServer) ServeStdio() error {
+// ServeStdio serves MCP over stdin and stdout until stdin is closed or ctx
+// is done.
+func (s *MCPServer) ServeStdio(ctx context.Context) error {
 	s.logger.Info("Starting STDIO server",
 		zap.String("version", version.Version),
 		zap.String("build_time", version.BuildTime),
 		zap.String("commit_hash", version.CommitHash),
 	)
-	err := server.ServeStdio(s.server)
-	if err != nil {
+	err := server.NewStdioServer(s.server).Listen(ctx, os.Stdin, os.Stdout)
+	if err != nil && !errors.Is(err, context.Canceled) {
 		s.logger.Error("STDIO server error", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Reload applies the reloadable settings of next, see config.Config.Reload:
+// the read and approval policies are rebuilt and the configuration read by
+// the tools is swapped. Nothing changes when a policy is invalid.
+//
+// Each of them is swapped on its own, in that order, so a tool call running
+// during a reload can see the new read policy with the old approval policy
+// or configuration. Tool calls started after Reload returns see all of it.
+func (s *MCPServer) Reload(next *config.Config) error {
+	policy, err := approval.ParsePolicy(next.ApprovalChannels, next.ApprovalFallback)
+	if err != nil {
+		return err
+	}
+	if s.tenants != nil {
+		if err := s.tenants.reload(next); err != nil {
+			return err
+		}
+	} else {
+		for _, p := range s.workspaces.All() {
+			if err := p.ReloadReadPolicy(next); err != nil {
+				return err
+			}
+		}
+	}
+	s.approvalPolicy.Store(policy)
+	s.config.Set(next)
+	return nil
+}
+
+// Shutdown stops accepting new sessions and tool calls, waits for the tool
+// calls in flight, stops the SSE or HTTP server and the background jobs and
+// closes the audit log. It gives up waiting when ctx is done.
+func (s *MCPServer) Shutdown(ctx context.Context) error {
+	s.drainer.Begin()
+	if s.checker != nil {
+		s.checker.Drain()
+	}
+
+	s.logger.Info("Waiting for tool calls in flight",
+		zap.String("context", "console"),
+	)
+	var errs []error
+	if err := s.drainer.Wait(ctx); err != nil {
+		s.logger.Warn("Tool calls still running, abandoning them", zap.Error(err))
+		errs = append(errs, err)
+	}
+
+	if s.transport != nil {
+		if err := s.transport.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop the server: %w", err))
+		}
+	}
+
+	s.cancel()
+	done := make(chan struct{})
+	go func() {
+		s.background.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		errs = append(errs, fmt.Errorf("background jobs did not stop: %w", ctx.Err()))
+	}
+
+	if err := s.auditor.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to close the audit log: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+func (s *MCPServer) goBackground(run func(ctx context.Context)) {
+	s.background.Add(1)
+	go func() {
+		defer s.background.Done()
+		run(s.ctx)
+	}()
+}
+
+func buildTracingMiddleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			attrs := []attribute.KeyValue{tracing.ToolName.String(req.Params.Name)}
+			if session := server.ClientSessionFromContext(ctx); session != nil {
+				attrs = append(attrs, tracing.SessionID.String(session.SessionID()))
+			}
+			ctx, span := tracing.Tracer().Start(ctx, "tools/call "+req.Params.Name,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(attrs...),
+			)
+			defer span.End()
+
+			res, err := next(ctx, req)
+
+			switch {
+			case err != nil:
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			case res != nil && res.IsError:
+				span.SetStatus(codes.Error, "tool returned an error")
+			}
+			return res, err
+		}
+	}
+}
+
+func buildMetricsMiddleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			startTime := time.Now()
+
+			res, err := next(ctx, req)
+
+			metrics.ObserveToolCall(req.Params.Name, time.Since(startTime), err != nil || (res != nil && res.IsError))
+			return res, err
+		}
 	}
-	return err
 }
 
 func buildLoggerMiddleware(logger *zap.Logger) server.ToolHandlerMiddleware {
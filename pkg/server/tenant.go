@@ -0,0 +1,418 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
+	"github.com/korotovsky/slack-mcp-server/pkg/handler"
+	"github.com/korotovsky/slack-mcp-server/pkg/limiter"
+	"github.com/korotovsky/slack-mcp-server/pkg/metrics"
+	"github.com/korotovsky/slack-mcp-server/pkg/provider"
+	"github.com/korotovsky/slack-mcp-server/pkg/server/auth"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"go.uber.org/zap"
+)
+
+// toolHandlers are the handlers of the Slack tools of one provider.
+type toolHandlers struct {
+	conversations *handler.ConversationsHandler
+	channels      *handler.ChannelsHandler
+	emoji         *handler.EmojiHandler
+	quota         *handler.QuotaHandler
+}
+
+// tenant is a client of a multi-tenant server, known by the Slack tokens it
+// sends. Every tenant has its own provider, cache files and handlers.
+type tenant struct {
+	// id is a hash of the tokens, it names the cache directory and is
+	// logged instead of the tokens
+	id       string
+	provider *provider.ApiProvider
+	handlers *toolHandlers
+
+	// ready is closed once the provider is built or failed with err
+	ready chan struct{}
+	err   error
+
+	// cancel stops the cache refresh
+	cancel   context.CancelFunc
+	lastUsed time.Time
+}
+
+// sessionBinding ties an MCP session to the tenant of its first call.
+type sessionBinding struct {
+	tenant   string
+	lastUsed time.Time
+}
+
+// tenantPool keeps the tenants of a multi-tenant server. Tenants are built on
+// their first call, dropped when idle for longer than the idle timeout and,
+// least recently used first, when there are more than the maximum.
+type tenantPool struct {
+	config *config.Live
+	logger *zap.Logger
+	max    int
+	idle   time.Duration
+
+	// open builds the provider of a tenant, it is provider.Open
+	open func(cfg *config.Config, logger *zap.Logger) (*provider.ApiProvider, error)
+	// handlers builds the tool handlers of a tenant
+	handlers func(p *provider.ApiProvider) *toolHandlers
+	now      func() time.Time
+
+	// ctx is the parent of the cache refreshes, wg waits for them
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu       sync.Mutex
+	tenants  map[string]*tenant
+	sessions map[string]*sessionBinding
+}
+
+func newTenantPool(live *config.Live, handlers func(p *provider.ApiProvider) *toolHandlers, logger *zap.Logger) *tenantPool {
+	cfg := live.Get()
+	ctx, cancel := context.WithCancel(context.Background())
+	return &tenantPool{
+		config:   live,
+		logger:   logger,
+		max:      cfg.TenantMax,
+		idle:     cfg.TenantIdleTimeout,
+		open:     provider.Open,
+		handlers: handlers,
+		now:      time.Now,
+		ctx:      ctx,
+		cancel:   cancel,
+		tenants:  make(map[string]*tenant),
+		sessions: make(map[string]*sessionBinding),
+	}
+}
+
+// tenantID hashes the tokens of a tenant.
+func tenantID(token, cookie string) string {
+	sum := sha256.Sum256([]byte(token + "\x00" + cookie))
+	return hex.EncodeToString(sum[:12])
+}
+
+// get returns the tenant of the Slack tokens of the request and builds it on
+// its first call. A session keeps the tenant of its first call, a request
+// with other tokens fails.
+func (tp *tenantPool) get(ctx context.Context) (*tenant, error) {
+	token, cookie := auth.SlackTokensFromContext(ctx)
+	if token == "" {
+		return nil, errors.New("missing Slack token, send an xoxp or xoxc token in the X-Slack-Token header")
+	}
+	if strings.HasPrefix(token, "xoxc-") && cookie == "" {
+		return nil, errors.New("missing Slack cookie, send the xoxd cookie of the xoxc token in the X-Slack-Cookie header")
+	}
+	id := tenantID(token, cookie)
+
+	var sessionID string
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		sessionID = session.SessionID()
+	}
+
+	tp.mu.Lock()
+	if err := tp.checkSession(sessionID, id); err != nil {
+		tp.mu.Unlock()
+		return nil, err
+	}
+	t, ok := tp.tenants[id]
+	if !ok {
+		t = &tenant{id: id, ready: make(chan struct{}), lastUsed: tp.now()}
+		tp.tenants[id] = t
+	}
+	tp.mu.Unlock()
+
+	if !ok {
+		tp.build(t, token, cookie)
+	}
+	select {
+	case <-t.ready:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if t.err != nil {
+		return nil, t.err
+	}
+
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	if err := tp.checkSession(sessionID, id); err != nil {
+		return nil, err
+	}
+	now := tp.now()
+	t.lastUsed = now
+	if sessionID != "" {
+		tp.sessions[sessionID] = &sessionBinding{tenant: id, lastUsed: now}
+	}
+	return t, nil
+}
+
+// checkSession fails when the session is bound to another tenant, tp.mu must
+// be held.
+func (tp *tenantPool) checkSession(sessionID, id string) error {
+	if b, ok := tp.sessions[sessionID]; ok && b.tenant != id {
+		return errors.New("the Slack tokens of a session cannot change, start a new session to use other tokens")
+	}
+	return nil
+}
+
+// build opens the provider of t and starts its cache refresh. A tenant that
+// fails is not kept, the next call tries again.
+func (tp *tenantPool) build(t *tenant, token, cookie string) {
+	logger := tp.logger.With(zap.String("tenant", t.id))
+	defer close(t.ready)
+
+	cfg, err := tp.tenantConfig(t.id, token, cookie)
+	var p *provider.ApiProvider
+	if err == nil {
+		p, err = tp.open(cfg, logger)
+	}
+
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	if err != nil {
+		logger.Warn("Failed to connect a tenant to Slack", zap.Error(err))
+		if tp.tenants[t.id] == t {
+			delete(tp.tenants, t.id)
+		}
+		t.err = fmt.Errorf("failed to connect to Slack with the tokens of the request: %w", err)
+		return
+	}
+
+	t.provider = p
+	t.handlers = tp.handlers(p)
+	var ctx context.Context
+	ctx, t.cancel = context.WithCancel(tp.ctx)
+	logger.Info("Connected a tenant to Slack", zap.String("workspace", p.Workspace()))
+	if ctx.Err() == nil {
+		tp.refresh(ctx, t, logger)
+	}
+
+	for len(tp.tenants) > tp.max {
+		oldest := tp.leastRecentlyUsed(t)
+		if oldest == nil {
+			break
+		}
+		tp.evict(oldest, "capacity")
+	}
+	metrics.SetTenants(len(tp.tenants))
+}
+
+// tenantConfig is the server configuration with the tokens of a tenant and
+// cache files in a directory of its own.
+func (tp *tenantPool) tenantConfig(id, token, cookie string) (*config.Config, error) {
+	cfg := *tp.config.Get()
+	cfg.XOXPToken, cfg.XOXCToken, cfg.XOXDToken = token, "", ""
+	if strings.HasPrefix(token, "xoxc-") {
+		cfg.XOXPToken, cfg.XOXCToken, cfg.XOXDToken = "", token, cookie
+	}
+
+	dir := filepath.Join(cfg.TenantCacheDir, id)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create the cache directory of the tenant: %w", err)
+	}
+	cfg.UsersCache = filepath.Join(dir, "users_cache.json")
+	cfg.ChannelsCache = filepath.Join(dir, "channels_cache.json")
+	cfg.EmojiCache = filepath.Join(dir, "emoji_cache.json")
+	return &cfg, nil
+}
+
+// refresh loads the caches of t in the background. A tenant whose users or
+// channels cannot be loaded is dropped, so that its next call starts over.
+func (tp *tenantPool) refresh(ctx context.Context, t *tenant, logger *zap.Logger) {
+	if t.provider.Demo() {
+		return
+	}
+	tp.wg.Add(1)
+	go func() {
+		defer tp.wg.Done()
+		for _, step := range []struct {
+			name string
+			run  func(context.Context) error
+		}{
+			{"users", t.provider.RefreshUsers},
+			{"channels", t.provider.RefreshChannels},
+		} {
+			if err := step.run(ctx); err != nil {
+				if ctx.Err() == nil {
+					logger.Warn("Failed to cache "+step.name+" of a tenant", zap.Error(err))
+					tp.mu.Lock()
+					tp.evict(t, "failed")
+					tp.mu.Unlock()
+				}
+				return
+			}
+		}
+		// custom emoji are cosmetic, they are rendered as :name: without the cache
+		if err := t.provider.RefreshEmoji(ctx); err != nil && ctx.Err() == nil {
+			logger.Warn("Failed to cache emoji of a tenant", zap.Error(err))
+		}
+	}()
+}
+
+// leastRecentlyUsed returns the built tenant used last longest ago other
+// than keep, tp.mu must be held.
+func (tp *tenantPool) leastRecentlyUsed(keep *tenant) *tenant {
+	var oldest *tenant
+	for _, t := range tp.tenants {
+		if t == keep || t.provider == nil {
+			continue
+		}
+		if oldest == nil || t.lastUsed.Before(oldest.lastUsed) {
+			oldest = t
+		}
+	}
+	return oldest
+}
+
+// evict drops t and stops its cache refresh, tp.mu must be held. Calls in
+// flight finish with the provider they have, the cache files are kept for
+// the next time the tenant calls.
+func (tp *tenantPool) evict(t *tenant, reason string) {
+	if tp.tenants[t.id] != t {
+		return
+	}
+	delete(tp.tenants, t.id)
+	t.cancel()
+	metrics.ObserveTenantEviction(reason)
+	metrics.SetTenants(len(tp.tenants))
+	tp.logger.Info("Dropped a tenant",
+		zap.String("tenant", t.id),
+		zap.String("reason", reason),
+	)
+}
+
+// evictIdle drops the tenants and session bindings not used for longer than
+// the idle timeout.
+func (tp *tenantPool) evictIdle() {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	deadline := tp.now().Add(-tp.idle)
+	for _, t := range tp.tenants {
+		if t.provider != nil && t.lastUsed.Before(deadline) {
+			tp.evict(t, "idle")
+		}
+	}
+	for id, b := range tp.sessions {
+		if b.lastUsed.Before(deadline) {
+			delete(tp.sessions, id)
+		}
+	}
+}
+
+// forget drops the binding of a closed session.
+func (tp *tenantPool) forget(sessionID string) {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	delete(tp.sessions, sessionID)
+}
+
+// Run drops idle tenants until ctx is done, then stops the cache refreshes
+// and waits for them.
+func (tp *tenantPool) Run(ctx context.Context) {
+	ticker := time.NewTicker(min(tp.idle, time.Minute))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			tp.evictIdle()
+		case <-ctx.Done():
+			tp.cancel()
+			tp.wg.Wait()
+			return
+		}
+	}
+}
+
+// reload applies the read policy of cfg to every tenant, the tenants built
+// later use cfg anyway.
+func (tp *tenantPool) reload(cfg *config.Config) error {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	for _, t := range tp.tenants {
+		if t.provider == nil {
+			continue
+		}
+		if err := t.provider.ReloadReadPolicy(cfg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsReady reports a multi-tenant server as ready, tenants are connected on
+// their first call.
+func (tp *tenantPool) IsReady() (bool, error) {
+	return true, nil
+}
+
+// CheckAuth has no tokens to check, they are sent by the tenants.
+func (tp *tenantPool) CheckAuth(ctx context.Context) error {
+	return nil
+}
+
+// SyncStatuses reports nothing, the health endpoints are not per tenant.
+func (tp *tenantPool) SyncStatuses() []provider.SyncStatus {
+	return nil
+}
+
+func pickConversations(h *toolHandlers) *handler.ConversationsHandler { return h.conversations }
+func pickChannels(h *toolHandlers) *handler.ChannelsHandler           { return h.channels }
+func pickEmoji(h *toolHandlers) *handler.EmojiHandler                 { return h.emoji }
+func pickQuota(h *toolHandlers) *handler.QuotaHandler                 { return h.quota }
+
+// quota returns the write quotas of the tenant of the request, nil when
+// there is none.
+func (tp *tenantPool) quota(ctx context.Context) *limiter.Quota {
+	t, err := tp.get(ctx)
+	if err != nil {
+		return nil
+	}
+	return t.handlers.quota.Quota()
+}
+
+// byTenant returns a function that calls method on the handler picked from
+// the tool handlers of the tenant of the request.
+func byTenant[H, R any](tenants *tenantPool, pick func(*toolHandlers) H, method func(H, context.Context, mcp.CallToolRequest) (R, error)) func(context.Context, mcp.CallToolRequest) (R, error) {
+	return func(ctx context.Context, req mcp.CallToolRequest) (R, error) {
+		t, err := tenants.get(ctx)
+		if err != nil {
+			var zero R
+			return zero, err
+		}
+		return method(pick(t.handlers), ctx, req)
+	}
+}
+
+// tenantResource serves the resource of the tenant of the request, its URI
+// names the workspace of the tenant, e.g. slack://acme/channels.
+func tenantResource(tenants *tenantPool, name string, pick func(*toolHandlers) server.ResourceHandlerFunc) server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		// resources bypass the tool middlewares, check the API key before
+		// connecting to Slack
+		cfg := tenants.config.Get()
+		if authenticated, err := auth.IsAuthenticated(ctx, cfg.Transport, cfg.APIKey, tenants.logger); !authenticated {
+			return nil, err
+		}
+		t, err := tenants.get(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if uri := "slack://" + t.provider.Workspace() + "/" + name; req.Params.URI != uri {
+			return nil, fmt.Errorf("unknown resource %s, the %s of your workspace are at %s", req.Params.URI, name, uri)
+		}
+		return pick(t.handlers)(ctx, req)
+	}
+}
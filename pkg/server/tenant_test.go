@@ -0,0 +1,199 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
+	"github.com/korotovsky/slack-mcp-server/pkg/handler"
+	"github.com/korotovsky/slack-mcp-server/pkg/provider"
+	"github.com/korotovsky/slack-mcp-server/pkg/server/auth"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type testSession string
+
+func (s testSession) Initialize()                                         {}
+func (s testSession) Initialized() bool                                   { return true }
+func (s testSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return nil }
+func (s testSession) SessionID() string                                   { return string(s) }
+
+// tenantContext is the context of a request with the Slack tokens in its
+// headers, in the session when it is not empty.
+func tenantContext(token, cookie, session string) context.Context {
+	r := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	r.Header.Set("X-Slack-Token", token)
+	r.Header.Set("X-Slack-Cookie", cookie)
+	ctx := auth.AuthFromRequest(zap.NewNop())(context.Background(), r)
+	if session != "" {
+		ctx = server.NewMCPServer("test", "1").WithContext(ctx, testSession(session))
+	}
+	return ctx
+}
+
+// testTenantPool returns a pool of demo providers named after the token,
+// e.g. acme for xoxp-acme, and the configurations they were opened with.
+// The token xoxp-bad is rejected.
+func testTenantPool(t *testing.T, max int) (*tenantPool, *[]*config.Config) {
+	cfg := config.Default()
+	cfg.MultiTenant = true
+	cfg.Transport = "http"
+	cfg.TenantMax = max
+	cfg.TenantCacheDir = t.TempDir()
+	cfg.WriteQuotaChannel = "1/h"
+
+	quota := handler.NewQuotaHandler(cfg, zap.NewNop())
+	tp := newTenantPool(config.NewLive(cfg), func(p *provider.ApiProvider) *toolHandlers {
+		return &toolHandlers{emoji: handler.NewEmojiHandler(p, zap.NewNop()), quota: quota.Fresh()}
+	}, zap.NewNop())
+	var opened []*config.Config
+	tp.open = func(cfg *config.Config, logger *zap.Logger) (*provider.ApiProvider, error) {
+		opened = append(opened, cfg)
+		token := cfg.XOXPToken + cfg.XOXCToken
+		if token == "xoxp-bad" {
+			return nil, errors.New("invalid_auth")
+		}
+		demo := *cfg
+		demo.WorkspaceName = strings.SplitN(token, "-", 2)[1]
+		demo.XOXPToken, demo.XOXCToken, demo.XOXDToken = "demo", "", ""
+		return provider.Open(&demo, logger)
+	}
+	t.Cleanup(tp.cancel)
+	return tp, &opened
+}
+
+func TestUnitTenantIsolation(t *testing.T) {
+	tp, opened := testTenantPool(t, 10)
+
+	acme, err := tp.get(tenantContext("xoxp-acme", "", "s1"))
+	require.NoError(t, err)
+	beta, err := tp.get(tenantContext("xoxc-beta", "xoxd-beta", "s2"))
+	require.NoError(t, err)
+	assert.Equal(t, "acme", acme.provider.Workspace())
+	assert.Equal(t, "beta", beta.provider.Workspace())
+	assert.NotSame(t, acme.provider, beta.provider)
+	assert.NotSame(t, acme.handlers.emoji, beta.handlers.emoji)
+
+	require.Len(t, *opened, 2)
+	acmeCfg, betaCfg := (*opened)[0], (*opened)[1]
+	assert.Equal(t, "xoxp-acme", acmeCfg.XOXPToken)
+	assert.Empty(t, acmeCfg.XOXCToken)
+	assert.Equal(t, "xoxc-beta", betaCfg.XOXCToken)
+	assert.Equal(t, "xoxd-beta", betaCfg.XOXDToken)
+	assert.Empty(t, betaCfg.XOXPToken)
+	for _, cfg := range []*config.Config{acmeCfg, betaCfg} {
+		for _, f := range provider.CacheFiles(cfg) {
+			assert.True(t, strings.HasPrefix(f.Path, filepath.Join(cfg.TenantCacheDir, "")), f.Path)
+		}
+	}
+	assert.NotEqual(t, filepath.Dir(acmeCfg.UsersCache), filepath.Dir(betaCfg.UsersCache))
+	info, err := os.Stat(filepath.Dir(acmeCfg.UsersCache))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o700), info.Mode().Perm())
+	assert.NotContains(t, acmeCfg.UsersCache, "xoxp-acme", "the tokens are hashed")
+
+	again, err := tp.get(tenantContext("xoxp-acme", "", "s3"))
+	require.NoError(t, err)
+	assert.Same(t, acme, again, "sessions with the same tokens share the tenant")
+	assert.Len(t, *opened, 2)
+
+	call := byTenant(tp, pickEmoji, func(h *handler.EmojiHandler, _ context.Context, _ mcp.CallToolRequest) (*handler.EmojiHandler, error) {
+		return h, nil
+	})
+	got, err := call(tenantContext("xoxc-beta", "xoxd-beta", ""), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	assert.Same(t, beta.handlers.emoji, got)
+
+	now := time.Now()
+	require.NoError(t, tp.quota(tenantContext("xoxp-acme", "", "s1")).Allow("C1", "", "hi", now))
+	assert.NoError(t, tp.quota(tenantContext("xoxc-beta", "xoxd-beta", "s2")).Allow("C1", "", "hi", now), "every tenant has its own quotas")
+	assert.Error(t, tp.quota(tenantContext("xoxp-acme", "", "s1")).Allow("C1", "", "bye", now))
+	require.NoError(t, tp.quota(tenantContext("xoxp-acme", "", "s1")).Allow("CACME", "", "hi", now))
+	status, err := byTenant(tp, pickQuota, (*handler.QuotaHandler).QuotaStatusHandler)(tenantContext("xoxc-beta", "xoxd-beta", "s2"), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	assert.Contains(t, status.Content[0].(mcp.TextContent).Text, "C1")
+	assert.NotContains(t, status.Content[0].(mcp.TextContent).Text, "CACME", "quota_status shows the buckets of the tenant only")
+
+	_, err = tp.get(tenantContext("xoxc-beta", "xoxd-beta", "s1"))
+	assert.ErrorContains(t, err, "cannot change", "a session keeps its tokens")
+	tp.forget("s1")
+	_, err = tp.get(tenantContext("xoxc-beta", "xoxd-beta", "s1"))
+	assert.NoError(t, err, "a closed session id may be reused")
+
+	var req mcp.ReadResourceRequest
+	req.Params.URI = "slack://acme/channels"
+	_, err = tenantResource(tp, "channels", func(h *toolHandlers) server.ResourceHandlerFunc {
+		return h.channels.ChannelsResource
+	})(tenantContext("xoxc-beta", "xoxd-beta", ""), req)
+	assert.EqualError(t, err, "unknown resource slack://acme/channels, the channels of your workspace are at slack://beta/channels")
+}
+
+func TestUnitTenantErrors(t *testing.T) {
+	tp, opened := testTenantPool(t, 10)
+
+	_, err := tp.get(context.Background())
+	assert.ErrorContains(t, err, "missing Slack token")
+	_, err = tp.get(tenantContext("xoxc-acme", "", ""))
+	assert.ErrorContains(t, err, "missing Slack cookie")
+	assert.Empty(t, *opened)
+
+	for range 2 {
+		_, err = tp.get(tenantContext("xoxp-bad", "", "s1"))
+		assert.EqualError(t, err, "failed to connect to Slack with the tokens of the request: invalid_auth")
+		assert.NotContains(t, err.Error(), "xoxp-bad")
+	}
+	assert.Len(t, *opened, 2, "failed tenants are not kept")
+	assert.Empty(t, tp.tenants)
+
+	_, err = tp.get(tenantContext("xoxp-acme", "", "s1"))
+	assert.NoError(t, err, "a failed call does not bind the session")
+}
+
+func TestUnitTenantEviction(t *testing.T) {
+	tp, _ := testTenantPool(t, 2)
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	tp.now = func() time.Time { return now }
+	get := func(token, session string) *tenant {
+		t.Helper()
+		now = now.Add(time.Second)
+		tn, err := tp.get(tenantContext(token, "", session))
+		require.NoError(t, err)
+		return tn
+	}
+	ids := func() []string {
+		var ids []string
+		for _, tn := range tp.tenants {
+			ids = append(ids, tn.provider.Workspace())
+		}
+		return ids
+	}
+
+	get("xoxp-acme", "s1")
+	beta := get("xoxp-beta", "s2")
+	get("xoxp-acme", "s1")
+	get("xoxp-gamma", "s3")
+	assert.ElementsMatch(t, []string{"acme", "gamma"}, ids(), "the least recently used tenant is dropped")
+
+	again := get("xoxp-beta", "s2")
+	assert.NotSame(t, beta, again, "a dropped tenant is built again")
+	assert.ElementsMatch(t, []string{"beta", "gamma"}, ids())
+
+	now = now.Add(tp.idle)
+	get("xoxp-gamma", "s3")
+	now = now.Add(time.Minute)
+	tp.evictIdle()
+	assert.ElementsMatch(t, []string{"gamma"}, ids(), "idle tenants are dropped")
+	assert.Contains(t, tp.sessions, "s3")
+	assert.NotContains(t, tp.sessions, "s2")
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/metrics"
+	"github.com/korotovsky/slack-mcp-server/pkg/provider"
+	"github.com/mark3labs/mcp-go/mcp"
+	"go.uber.org/zap"
+)
+
+// toolMethod is a tool handler method of a handler type, e.g.
+// (*handler.ChannelsHandler).ChannelsHandler.
+type toolMethod[H any] func(H, context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error)
+
+// byWorkspace returns a function that calls method on the handler of the
+// workspace named by the workspace argument of the call, or of the default
+// workspace when it is not given.
+func byWorkspace[H, R any](workspaces *provider.Workspaces, handlers map[string]H, method func(H, context.Context, mcp.CallToolRequest) (R, error)) func(context.Context, mcp.CallToolRequest) (R, error) {
+	return func(ctx context.Context, req mcp.CallToolRequest) (R, error) {
+		p, err := workspaces.Get(req.GetString("workspace", ""))
+		if err != nil {
+			var zero R
+			return zero, err
+		}
+		return method(handlers[p.Workspace()], ctx, req)
+	}
+}
+
+// workspaceTool returns mcp.NewTool, which also adds the workspace argument
+// when the server serves several workspaces.
+func workspaceTool(workspaces *provider.Workspaces) func(name string, opts ...mcp.ToolOption) mcp.Tool {
+	if !workspaces.Multiple() {
+		return mcp.NewTool
+	}
+	names := workspaces.Names()
+	def := workspaces.Default().Workspace()
+	arg := mcp.WithString("workspace",
+		mcp.Enum(names...),
+		mcp.DefaultString(def),
+		mcp.Description(fmt.Sprintf("Slack workspace to use, one of: %s. Default is '%s'.", strings.Join(names, ", "), def)),
+	)
+	return func(name string, opts ...mcp.ToolOption) mcp.Tool {
+		return mcp.NewTool(name, append(opts, arg)...)
+	}
+}
+
+// registerCacheMetrics adds the readiness and cache metrics, labelled with
+// the workspace when there are several.
+func registerCacheMetrics(workspaces *provider.Workspaces, logger *zap.Logger) {
+	if !workspaces.Multiple() {
+		if err := metrics.RegisterSource(workspaces.Default()); err != nil {
+			logger.Error("Failed to register cache metrics", zap.Error(err))
+		}
+		return
+	}
+	for _, p := range workspaces.All() {
+		if err := metrics.RegisterWorkspaceSource(p.Workspace(), p); err != nil {
+			logger.Error("Failed to register cache metrics",
+				zap.String("workspace", p.Workspace()),
+				zap.Error(err),
+			)
+		}
+	}
+}
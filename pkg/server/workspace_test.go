@@ -0,0 +1,63 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
+	"github.com/korotovsky/slack-mcp-server/pkg/provider"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func demoWorkspaces(t *testing.T, names ...string) *provider.Workspaces {
+	cfg := config.Default()
+	cfg.Workspaces = make([]config.Workspace, 0, len(names))
+	for _, name := range names {
+		cfg.Workspaces = append(cfg.Workspaces, config.Workspace{Name: name, XOXPToken: "demo"})
+	}
+
+	var providers []*provider.ApiProvider
+	for _, wc := range cfg.WorkspaceConfigs() {
+		providers = append(providers, provider.New(wc, zap.NewNop()))
+	}
+	workspaces, err := provider.NewWorkspaces(providers, names[len(names)-1])
+	require.NoError(t, err)
+	return workspaces
+}
+
+func TestUnitByWorkspace(t *testing.T) {
+	workspaces := demoWorkspaces(t, "acme", "beta")
+	handlers := map[string]string{"acme": "acme handler", "beta": "beta handler"}
+	call := byWorkspace(workspaces, handlers, func(h string, _ context.Context, _ mcp.CallToolRequest) (string, error) {
+		return h, nil
+	})
+
+	var req mcp.CallToolRequest
+	got, err := call(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, "beta handler", got, "the default workspace without an argument")
+
+	req.Params.Arguments = map[string]any{"workspace": "acme"}
+	got, err = call(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, "acme handler", got)
+
+	req.Params.Arguments = map[string]any{"workspace": "gamma"}
+	_, err = call(context.Background(), req)
+	assert.EqualError(t, err, `unknown workspace "gamma", expected one of: acme, beta`)
+}
+
+func TestUnitWorkspaceTool(t *testing.T) {
+	tool := workspaceTool(demoWorkspaces(t, "acme", "beta"))("channels_list", mcp.WithString("cursor"))
+	prop, ok := tool.InputSchema.Properties["workspace"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, []string{"acme", "beta"}, prop["enum"])
+	assert.Equal(t, "beta", prop["default"])
+	assert.Contains(t, tool.InputSchema.Properties, "cursor")
+
+	single := workspaceTool(demoWorkspaces(t, "acme"))("channels_list", mcp.WithString("cursor"))
+	assert.NotContains(t, single.InputSchema.Properties, "workspace")
+}
@@ -0,0 +1,62 @@
+package text
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/kyokomi/emoji/v2"
+)
+
+var (
+	emojiShortcodeRe = regexp.MustCompile(`:([a-z0-9_+'-]+)(::skin-tone-[2-6])?:`)
+
+	skinTones = map[string]string{
+		"skin-tone-2": "\U0001F3FB",
+		"skin-tone-3": "\U0001F3FC",
+		"skin-tone-4": "\U0001F3FD",
+		"skin-tone-5": "\U0001F3FE",
+		"skin-tone-6": "\U0001F3FF",
+	}
+)
+
+// EmojiUnicode returns the Unicode form of a standard Slack emoji name such as
+// "thumbsup" or "+1::skin-tone-3", ok is false for unknown and custom emoji.
+func EmojiUnicode(name string) (string, bool) {
+	name = strings.Trim(name, ":")
+	base, tone, _ := strings.Cut(name, "::")
+
+	u, ok := emoji.CodeMap()[":"+base+":"]
+	if !ok {
+		u, ok = emoji.CodeMap()[":"+strings.ReplaceAll(base, "-", "_")+":"]
+	}
+	if !ok {
+		return "", false
+	}
+	return u + skinTones[tone], true
+}
+
+// RenderEmoji renders an emoji name for output: Unicode when one is known,
+// :name: otherwise, so custom emoji keep a form agents can react with.
+func RenderEmoji(name string, r Resolver) string {
+	name = strings.Trim(name, ":")
+	if r.Emoji != nil {
+		if u := r.Emoji(name); u != "" {
+			return u
+		}
+	}
+	if u, ok := EmojiUnicode(name); ok {
+		return u
+	}
+	return ":" + name + ":"
+}
+
+// replaceEmojiShortcodes swaps :shortcode: sequences for Unicode, unknown and
+// custom ones are kept as they are.
+func replaceEmojiShortcodes(s string, r Resolver) string {
+	if !strings.Contains(s, ":") {
+		return s
+	}
+	return emojiShortcodeRe.ReplaceAllStringFunc(s, func(code string) string {
+		return RenderEmoji(code, r)
+	})
+}
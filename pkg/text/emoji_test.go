@@ -0,0 +1,35 @@
+package text
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnitEmojiUnicode(t *testing.T) {
+	u, ok := EmojiUnicode("thumbsup")
+	assert.True(t, ok)
+	assert.Equal(t, "\U0001f44d", u)
+
+	u, ok = EmojiUnicode(":+1::skin-tone-3:")
+	assert.True(t, ok)
+	assert.Equal(t, "\U0001f44d\U0001F3FC", u)
+
+	_, ok = EmojiUnicode("partyparrot")
+	assert.False(t, ok)
+}
+
+func TestUnitRenderEmoji(t *testing.T) {
+	r := Resolver{
+		Emoji: func(name string) string {
+			if name == "yay" {
+				return "\U0001f389"
+			}
+			return ""
+		},
+	}
+
+	assert.Equal(t, "\U0001f389", RenderEmoji("yay", r))
+	assert.Equal(t, ":partyparrot:", RenderEmoji("partyparrot", r))
+	assert.Equal(t, "ship it \U0001f680 :partyparrot: at 10:30:00", MrkdwnToMarkdown("ship it :rocket: :partyparrot: at 10:30:00", r))
+}
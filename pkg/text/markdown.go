@@ -0,0 +1,358 @@
+package text
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	// RendererMarkdown renders Slack mrkdwn and blocks as Markdown.
+	RendererMarkdown = "markdown"
+	// RendererLegacy strips everything outside a small character whitelist.
+	RendererLegacy = "legacy"
+)
+
+// Resolver looks up display names for mentions and the Unicode form of
+// emoji not known to the standard set, empty means unknown.
+type Resolver struct {
+	User    func(id string) string
+	Channel func(id string) string
+	Emoji   func(name string) string
+}
+
+var (
+	mrkdwnCodeRe   = regexp.MustCompile("`[^`\n]+`")
+	mrkdwnTokenRe  = regexp.MustCompile(`<([^<>\n]+)>`)
+	mrkdwnBoldRe   = regexp.MustCompile(`(^|[\s(\[{>_~"'])\*([^*\n]*[^*\s\n])\*($|[\s.,;:!?)\]}_~"'])`)
+	mrkdwnStrikeRe = regexp.MustCompile(`(^|[\s(\[{>_*"'])~([^~\n]*[^~\s\n])~($|[\s.,;:!?)\]}_*"'])`)
+	placeholderRe  = regexp.MustCompile("\x00(\\d+)\x00")
+)
+
+var entityReplacer = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&")
+
+// MrkdwnToMarkdown converts Slack mrkdwn into Markdown keeping the formatting,
+// code fences, quotes and lists, links and mentions become Markdown links and
+// @names/#names. Text inside code spans and fences is left as is.
+func MrkdwnToMarkdown(s string, r Resolver) string {
+	var out strings.Builder
+
+	for i, part := range strings.Split(s, "```") {
+		if i%2 == 1 {
+			out.WriteString(renderFence(part))
+			continue
+		}
+		out.WriteString(renderMrkdwnSpan(part, r))
+	}
+
+	return strings.TrimSpace(out.String())
+}
+
+func renderFence(code string) string {
+	code = entityReplacer.Replace(code)
+	code = strings.TrimPrefix(code, "\n")
+	code = strings.TrimSuffix(code, "\n")
+	return "\n```\n" + code + "\n```\n"
+}
+
+func renderMrkdwnSpan(s string, r Resolver) string {
+	var protected []string
+	protect := func(v string) string {
+		protected = append(protected, v)
+		return "\x00" + strconv.Itoa(len(protected)-1) + "\x00"
+	}
+
+	// inline code first, nothing inside of it is formatted
+	s = mrkdwnCodeRe.ReplaceAllStringFunc(s, func(code string) string {
+		return protect(entityReplacer.Replace(code))
+	})
+
+	s = mrkdwnTokenRe.ReplaceAllStringFunc(s, func(token string) string {
+		return protect(renderMrkdwnToken(token[1:len(token)-1], r))
+	})
+
+	s = entityReplacer.Replace(s)
+	s = mrkdwnBoldRe.ReplaceAllString(s, "$1**$2**$3")
+	s = mrkdwnStrikeRe.ReplaceAllString(s, "$1~~$2~~$3")
+	s = replaceEmojiShortcodes(s, r)
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = renderMrkdwnLine(line)
+	}
+	s = strings.Join(lines, "\n")
+
+	return placeholderRe.ReplaceAllStringFunc(s, func(p string) string {
+		n, _ := strconv.Atoi(strings.Trim(p, "\x00"))
+		return protected[n]
+	})
+}
+
+func renderMrkdwnLine(line string) string {
+	trimmed := strings.TrimLeft(line, " \t")
+	indent := line[:len(line)-len(trimmed)]
+	for _, bullet := range []string{"•", "◦", "▪", "▫", "‣"} {
+		if strings.HasPrefix(trimmed, bullet+" ") {
+			return indent + "- " + strings.TrimPrefix(trimmed, bullet+" ")
+		}
+	}
+	return line
+}
+
+// renderMrkdwnToken renders the inside of a <...> control sequence.
+func renderMrkdwnToken(token string, r Resolver) string {
+	target, label, _ := strings.Cut(token, "|")
+
+	switch {
+	case strings.HasPrefix(target, "@"):
+		id := target[1:]
+		if r.User != nil {
+			if name := r.User(id); name != "" {
+				return "@" + name
+			}
+		}
+		if label != "" {
+			return "@" + strings.TrimPrefix(label, "@")
+		}
+		return "@" + id
+	case strings.HasPrefix(target, "#"):
+		id := target[1:]
+		if label != "" {
+			return "#" + strings.TrimPrefix(label, "#")
+		}
+		if r.Channel != nil {
+			if name := r.Channel(id); name != "" {
+				return "#" + strings.TrimPrefix(name, "#")
+			}
+		}
+		return "#" + id
+	case strings.HasPrefix(target, "!"):
+		cmd := target[1:]
+		switch {
+		case cmd == "here" || cmd == "channel" || cmd == "everyone":
+			return "@" + cmd
+		case strings.HasPrefix(cmd, "subteam^"):
+			if label != "" {
+				return "@" + strings.TrimPrefix(label, "@")
+			}
+			return "@" + strings.TrimPrefix(cmd, "subteam^")
+		case strings.HasPrefix(cmd, "date^"):
+			if label != "" {
+				return label
+			}
+			parts := strings.Split(cmd, "^")
+			if sec, err := strconv.ParseInt(parts[1], 10, 64); err == nil {
+				return time.Unix(sec, 0).UTC().Format(time.RFC3339)
+			}
+		}
+		if label != "" {
+			return label
+		}
+		return "@" + cmd
+	}
+
+	target = entityReplacer.Replace(target)
+	label = entityReplacer.Replace(label)
+	if label == "" || label == target || label == strings.TrimPrefix(target, "mailto:") {
+		if strings.HasPrefix(target, "mailto:") {
+			return strings.TrimPrefix(target, "mailto:")
+		}
+		return target
+	}
+	return "[" + label + "](" + target + ")"
+}
+
+// BlocksToMarkdown renders the text carrying blocks of a message, rich_text,
+// section, header, context and markdown ones, interactive blocks are skipped.
+func BlocksToMarkdown(blocks slack.Blocks, r Resolver) string {
+	var parts []string
+	for _, block := range blocks.BlockSet {
+		var s string
+		switch b := block.(type) {
+		case *slack.RichTextBlock:
+			s = renderRichText(b.Elements, r)
+		case *slack.SectionBlock:
+			var texts []string
+			if b.Text != nil {
+				texts = append(texts, renderTextObject(b.Text, r))
+			}
+			for _, f := range b.Fields {
+				texts = append(texts, renderTextObject(f, r))
+			}
+			s = strings.Join(texts, "\n")
+		case *slack.HeaderBlock:
+			if b.Text != nil {
+				s = "## " + b.Text.Text
+			}
+		case *slack.ContextBlock:
+			var texts []string
+			for _, e := range b.ContextElements.Elements {
+				if t, ok := e.(*slack.TextBlockObject); ok {
+					texts = append(texts, renderTextObject(t, r))
+				}
+			}
+			s = strings.Join(texts, " ")
+		case *slack.MarkdownBlock:
+			s = b.Text
+		case *slack.DividerBlock:
+			s = "---"
+		}
+		if s = strings.TrimSpace(s); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+func renderTextObject(t *slack.TextBlockObject, r Resolver) string {
+	if t == nil {
+		return ""
+	}
+	if t.Type == slack.MarkdownType {
+		return MrkdwnToMarkdown(t.Text, r)
+	}
+	return t.Text
+}
+
+func renderRichText(elements []slack.RichTextElement, r Resolver) string {
+	var parts []string
+	for _, e := range elements {
+		var s string
+		switch el := e.(type) {
+		case *slack.RichTextSection:
+			s = renderRichTextSection(el.Elements, r)
+		case *slack.RichTextQuote:
+			lines := strings.Split(renderRichTextSection(el.Elements, r), "\n")
+			for i, line := range lines {
+				lines[i] = "> " + line
+			}
+			s = strings.Join(lines, "\n")
+		case *slack.RichTextPreformatted:
+			var code strings.Builder
+			for _, se := range el.Elements {
+				switch t := se.(type) {
+				case *slack.RichTextSectionTextElement:
+					code.WriteString(t.Text)
+				case *slack.RichTextSectionLinkElement:
+					code.WriteString(t.URL)
+				}
+			}
+			s = strings.TrimSpace(renderFence(code.String()))
+		case *slack.RichTextList:
+			s = renderRichTextList(el, r)
+		}
+		if s != "" {
+			parts = append(parts, strings.TrimRight(s, "\n"))
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+func renderRichTextList(list *slack.RichTextList, r Resolver) string {
+	indent := strings.Repeat("  ", list.Indent)
+	var lines []string
+	for i, e := range list.Elements {
+		marker := "- "
+		if list.Style == slack.RTEListOrdered {
+			marker = strconv.Itoa(list.Offset+i+1) + ". "
+		}
+		switch item := e.(type) {
+		case *slack.RichTextSection:
+			lines = append(lines, indent+marker+renderRichTextSection(item.Elements, r))
+		case *slack.RichTextList:
+			lines = append(lines, renderRichTextList(item, r))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func renderRichTextSection(elements []slack.RichTextSectionElement, r Resolver) string {
+	var b strings.Builder
+	for _, e := range elements {
+		switch el := e.(type) {
+		case *slack.RichTextSectionTextElement:
+			b.WriteString(styleText(el.Text, el.Style))
+		case *slack.RichTextSectionLinkElement:
+			link := el.URL
+			if el.Text != "" && el.Text != el.URL {
+				link = "[" + el.Text + "](" + el.URL + ")"
+			}
+			b.WriteString(styleText(link, el.Style))
+		case *slack.RichTextSectionUserElement:
+			b.WriteString(styleText(renderMrkdwnToken("@"+el.UserID, r), el.Style))
+		case *slack.RichTextSectionChannelElement:
+			b.WriteString(styleText(renderMrkdwnToken("#"+el.ChannelID, r), el.Style))
+		case *slack.RichTextSectionUserGroupElement:
+			b.WriteString("@" + el.UsergroupID)
+		case *slack.RichTextSectionBroadcastElement:
+			b.WriteString("@" + el.Range)
+		case *slack.RichTextSectionEmojiElement:
+			b.WriteString(renderEmoji(el, r))
+		case *slack.RichTextSectionDateElement:
+			if el.Fallback != nil {
+				b.WriteString(*el.Fallback)
+			} else {
+				b.WriteString(el.Timestamp.Time().UTC().Format(time.RFC3339))
+			}
+		case *slack.RichTextSectionColorElement:
+			b.WriteString(el.Value)
+		}
+	}
+	return b.String()
+}
+
+// styleText wraps text into Markdown emphasis, keeping surrounding whitespace
+// outside of the markers so the result still parses.
+func styleText(s string, style *slack.RichTextSectionTextStyle) string {
+	if style == nil {
+		return s
+	}
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		return s
+	}
+	lead := s[:strings.Index(s, trimmed)]
+	trail := s[len(lead)+len(trimmed):]
+
+	if style.Code {
+		trimmed = "`" + trimmed + "`"
+	}
+	if style.Strike {
+		trimmed = "~~" + trimmed + "~~"
+	}
+	if style.Italic {
+		trimmed = "_" + trimmed + "_"
+	}
+	if style.Bold {
+		trimmed = "**" + trimmed + "**"
+	}
+	return lead + trimmed + trail
+}
+
+func renderEmoji(el *slack.RichTextSectionEmojiElement, r Resolver) string {
+	if el.Unicode != "" {
+		var b strings.Builder
+		for _, cp := range strings.Split(el.Unicode, "-") {
+			n, err := strconv.ParseInt(cp, 16, 32)
+			if err != nil {
+				return RenderEmoji(el.Name, r)
+			}
+			b.WriteRune(rune(n))
+		}
+		return b.String()
+	}
+	return RenderEmoji(el.Name, r)
+}
+
+// IsTruncatedText reports whether the top level text of a message is empty
+// or a shortened fallback, so its blocks are the better source of content.
+func IsTruncatedText(s string) bool {
+	s = strings.TrimSpace(s)
+	return s == "" ||
+		s == "This content can't be displayed." ||
+		strings.HasSuffix(s, "…") ||
+		strings.HasSuffix(s, "...")
+}
@@ -0,0 +1,474 @@
+package text
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	maxMessageBlocks = 50
+	maxSectionText   = 3000
+	maxHeaderText    = 150
+)
+
+// Mentions looks up IDs for @username and #channel references, empty means
+// the name is unknown and the reference is kept as plain text.
+type Mentions struct {
+	User    func(name string) string
+	Channel func(name string) string
+}
+
+// Conversion is the result of MarkdownToBlocks. Text is the whole message as
+// mrkdwn, used for notifications and as the body when Blocks is empty.
+type Conversion struct {
+	Blocks   []slack.Block
+	Text     string
+	Warnings []string
+}
+
+var (
+	mdFenceRe     = regexp.MustCompile("^\\s*(```|~~~)")
+	mdHeadingRe   = regexp.MustCompile(`^\s{0,3}(#{1,6})\s+(.*?)\s*#*\s*$`)
+	mdRuleRe      = regexp.MustCompile(`^\s{0,3}([-*_])(\s*[-*_]){2,}\s*$`)
+	mdListRe      = regexp.MustCompile(`^(\s*)([-*+]|\d{1,9}[.)])\s+(.*)$`)
+	mdTaskRe      = regexp.MustCompile(`^\[([ xX])\]\s+`)
+	mdQuoteRe     = regexp.MustCompile(`^\s{0,3}>\s?(.*)$`)
+	mdTableSepRe  = regexp.MustCompile(`^\s*\|?\s*:?-{1,}:?\s*(\|\s*:?-{1,}:?\s*)*\|?\s*$`)
+	mdCodeSpanRe  = regexp.MustCompile("`[^`\n]+`")
+	mdLinkRe      = regexp.MustCompile(`!?\[([^\]]*)\]\(([^)\s]+)(?:\s+"[^"]*")?\)`)
+	mdAutoLinkRe  = regexp.MustCompile(`<((?:https?|mailto):[^>\s]+)>`)
+	mdURLRe       = regexp.MustCompile(`https?://[^\s<>()]+[^\s<>().,;:!?'"]`)
+	mdMentionRe   = regexp.MustCompile(`(?i)(^|[^\w@#/&])([@#])([a-z0-9][a-z0-9._-]*[a-z0-9_]|[a-z0-9])`)
+	mdBoldRe      = regexp.MustCompile(`(\*\*|__)([^\s*_](?:[^\n]*?[^\s])?)(\*\*|__)`)
+	mdItalicStar  = regexp.MustCompile(`(^|[^\w*])\*([^\s*](?:[^*\n]*[^\s*])?)\*([^\w*]|$)`)
+	mdStrikeRe    = regexp.MustCompile(`~~([^~\n]+)~~`)
+	mdPlaceholder = regexp.MustCompile("\x00(\\d+)\x00")
+)
+
+var mrkdwnEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// MarkdownToBlocks converts Markdown into Block Kit blocks. Headings become
+// header blocks, rules dividers and everything else mrkdwn sections: code
+// fences are kept, nested lists are indented, tables are aligned inside a
+// preformatted block. Anything that could not be converted faithfully is
+// reported in Warnings instead of failing the whole message.
+func MarkdownToBlocks(md string, m Mentions) Conversion {
+	c := &converter{mentions: m}
+	c.parse(strings.Split(strings.ReplaceAll(md, "\r\n", "\n"), "\n"))
+	c.flush()
+
+	conv := Conversion{
+		Blocks:   c.blocks,
+		Text:     strings.Join(c.texts, "\n\n"),
+		Warnings: c.warnings,
+	}
+	if len(conv.Blocks) > maxMessageBlocks {
+		conv.Warnings = append(conv.Warnings, fmt.Sprintf("message needs %d blocks, Slack allows %d, it was sent as plain mrkdwn text instead", len(conv.Blocks), maxMessageBlocks))
+		conv.Blocks = nil
+	}
+	return conv
+}
+
+type converter struct {
+	mentions Mentions
+	blocks   []slack.Block
+	texts    []string
+	warnings []string
+	pending  []string
+	unknown  map[string]bool
+}
+
+func (c *converter) warn(format string, args ...any) {
+	c.warnings = append(c.warnings, fmt.Sprintf(format, args...))
+}
+
+func (c *converter) parse(lines []string) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+
+		switch {
+		case strings.TrimSpace(line) == "":
+			i++
+		case mdFenceRe.MatchString(line):
+			i = c.parseFence(lines, i)
+		case mdHeadingRe.MatchString(line):
+			c.addHeading(mdHeadingRe.FindStringSubmatch(line)[2])
+			i++
+		case mdRuleRe.MatchString(line):
+			c.flush()
+			c.blocks = append(c.blocks, slack.NewDividerBlock())
+			c.texts = append(c.texts, "---")
+			i++
+		case i+1 < len(lines) && strings.Contains(line, "|") && mdTableSepRe.MatchString(lines[i+1]) && strings.Contains(lines[i+1], "-"):
+			i = c.parseTable(lines, i)
+		case mdListRe.MatchString(line):
+			i = c.parseList(lines, i)
+		case mdQuoteRe.MatchString(line):
+			i = c.parseQuote(lines, i)
+		default:
+			i = c.parseParagraph(lines, i)
+		}
+	}
+}
+
+func (c *converter) parseFence(lines []string, start int) int {
+	marker := mdFenceRe.FindStringSubmatch(lines[start])[1]
+	var code []string
+	i := start + 1
+	for ; i < len(lines); i++ {
+		if strings.HasPrefix(strings.TrimSpace(lines[i]), marker) {
+			c.addCode(code)
+			return i + 1
+		}
+		code = append(code, lines[i])
+	}
+	c.warn("code block opened on line %d is never closed, it runs to the end of the message", start+1)
+	c.addCode(code)
+	return i
+}
+
+func (c *converter) parseTable(lines []string, start int) int {
+	rows := [][]string{splitTableRow(lines[start])}
+	i := start + 2
+	for ; i < len(lines) && strings.Contains(lines[i], "|") && strings.TrimSpace(lines[i]) != ""; i++ {
+		rows = append(rows, splitTableRow(lines[i]))
+	}
+
+	cols := 0
+	for _, row := range rows {
+		cols = max(cols, len(row))
+	}
+	widths := make([]int, cols)
+	for _, row := range rows {
+		for j, cell := range row {
+			widths[j] = max(widths[j], utf8.RuneCountInString(cell))
+		}
+	}
+
+	var out []string
+	for r, row := range rows {
+		cells := make([]string, cols)
+		for j := range cells {
+			cell := ""
+			if j < len(row) {
+				cell = row[j]
+			}
+			cells[j] = cell + strings.Repeat(" ", widths[j]-utf8.RuneCountInString(cell))
+		}
+		out = append(out, strings.TrimRight(strings.Join(cells, " | "), " "))
+		if r == 0 {
+			seps := make([]string, cols)
+			for j, w := range widths {
+				seps[j] = strings.Repeat("-", max(w, 1))
+			}
+			out = append(out, strings.Join(seps, "-+-"))
+		}
+	}
+
+	c.addCode(out)
+	return i
+}
+
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	line = strings.TrimSuffix(line, "|")
+	cells := strings.Split(line, "|")
+	for i, cell := range cells {
+		cells[i] = strings.TrimSpace(cell)
+	}
+	return cells
+}
+
+func (c *converter) parseList(lines []string, start int) int {
+	var out []string
+	var indents []int
+	counters := map[int]int{}
+
+	i := start
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			if i+1 < len(lines) && mdListRe.MatchString(lines[i+1]) {
+				continue
+			}
+			break
+		}
+		match := mdListRe.FindStringSubmatch(line)
+		if match == nil {
+			if len(out) == 0 || !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+				break
+			}
+			// continuation of the previous item
+			out[len(out)-1] += " " + c.inline(strings.TrimSpace(line))
+			continue
+		}
+
+		indent := len(strings.ReplaceAll(match[1], "\t", "    "))
+		for len(indents) > 0 && indent < indents[len(indents)-1] {
+			delete(counters, len(indents)-1)
+			indents = indents[:len(indents)-1]
+		}
+		if len(indents) == 0 || indent > indents[len(indents)-1] {
+			indents = append(indents, indent)
+		}
+		level := len(indents) - 1
+
+		item := match[3]
+		if task := mdTaskRe.FindStringSubmatch(item); task != nil {
+			box := "☐"
+			if task[1] != " " {
+				box = "☑"
+			}
+			item = box + " " + item[len(task[0]):]
+		}
+
+		bullet := []string{"•", "◦", "▪"}[min(level, 2)]
+		if n, err := strconv.Atoi(strings.TrimRight(match[2], ".)")); err == nil {
+			if _, ok := counters[level]; !ok {
+				counters[level] = n
+			} else {
+				counters[level]++
+			}
+			bullet = strconv.Itoa(counters[level]) + "."
+		}
+
+		out = append(out, strings.Repeat("    ", level)+bullet+" "+c.inline(item))
+	}
+
+	c.addText(strings.Join(out, "\n"))
+	return i
+}
+
+func (c *converter) parseQuote(lines []string, start int) int {
+	var out []string
+	i := start
+	for ; i < len(lines); i++ {
+		match := mdQuoteRe.FindStringSubmatch(lines[i])
+		if match == nil {
+			break
+		}
+		out = append(out, "> "+c.inline(match[1]))
+	}
+	c.addText(strings.Join(out, "\n"))
+	return i
+}
+
+func (c *converter) parseParagraph(lines []string, start int) int {
+	var out []string
+	i := start
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" || mdFenceRe.MatchString(line) || mdHeadingRe.MatchString(line) ||
+			mdRuleRe.MatchString(line) || mdQuoteRe.MatchString(line) || (i > start && mdListRe.MatchString(line)) {
+			break
+		}
+		out = append(out, c.inline(strings.TrimSpace(line)))
+	}
+	c.addText(strings.Join(out, "\n"))
+	return i
+}
+
+func (c *converter) addHeading(heading string) {
+	c.flush()
+	if utf8.RuneCountInString(heading) > maxHeaderText {
+		c.warn("heading %q is longer than %d characters, it was sent as bold text", truncateRunes(heading, 30)+"…", maxHeaderText)
+		c.addText("*" + c.inline(heading) + "*")
+		c.flush()
+		return
+	}
+	plain := stripMarkdown(heading)
+	c.blocks = append(c.blocks, slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, plain, true, false)))
+	c.texts = append(c.texts, "*"+mrkdwnEscaper.Replace(plain)+"*")
+}
+
+func (c *converter) addCode(code []string) {
+	body := mrkdwnEscaper.Replace(strings.Join(code, "\n"))
+	if len(body)+8 <= maxSectionText {
+		c.addText("```\n" + body + "\n```")
+		return
+	}
+
+	// a single code block over the section limit is split into several ones
+	c.warn("code block of %d characters was split into several blocks", len(body))
+	var chunk []string
+	size := 0
+	for _, line := range chunkLines(body, maxSectionText-8) {
+		if size+len(line)+1 > maxSectionText-8 && len(chunk) > 0 {
+			c.addText("```\n" + strings.Join(chunk, "\n") + "\n```")
+			chunk, size = nil, 0
+		}
+		chunk = append(chunk, line)
+		size += len(line) + 1
+	}
+	if len(chunk) > 0 {
+		c.addText("```\n" + strings.Join(chunk, "\n") + "\n```")
+	}
+}
+
+// addText queues mrkdwn to the current section, the section is flushed when
+// the next chunk would not fit.
+func (c *converter) addText(s string) {
+	if s == "" {
+		return
+	}
+	if len(s) > maxSectionText {
+		c.warn("paragraph of %d characters was split into several sections", len(s))
+		for _, part := range splitLines(s, maxSectionText) {
+			c.addText(part)
+		}
+		return
+	}
+	if len(strings.Join(append(c.pending, s), "\n\n")) > maxSectionText {
+		c.flush()
+	}
+	c.pending = append(c.pending, s)
+}
+
+func (c *converter) flush() {
+	if len(c.pending) == 0 {
+		return
+	}
+	s := strings.Join(c.pending, "\n\n")
+	c.pending = nil
+	c.blocks = append(c.blocks, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, s, false, false), nil, nil))
+	c.texts = append(c.texts, s)
+}
+
+// inline converts Markdown emphasis, links and mentions of a single line into
+// mrkdwn. Code spans and URLs are left untouched.
+func (c *converter) inline(s string) string {
+	var protected []string
+	protect := func(v string) string {
+		protected = append(protected, v)
+		return "\x00" + strconv.Itoa(len(protected)-1) + "\x00"
+	}
+
+	s = mdCodeSpanRe.ReplaceAllStringFunc(s, func(code string) string {
+		return protect(mrkdwnEscaper.Replace(code))
+	})
+	s = mdLinkRe.ReplaceAllStringFunc(s, func(link string) string {
+		match := mdLinkRe.FindStringSubmatch(link)
+		label, target := stripMarkdown(match[1]), match[2]
+		if label == "" || label == target {
+			return protect("<" + target + ">")
+		}
+		return protect("<" + target + "|" + mrkdwnEscaper.Replace(label) + ">")
+	})
+	s = mdAutoLinkRe.ReplaceAllStringFunc(s, func(link string) string {
+		return protect(link)
+	})
+	s = mdURLRe.ReplaceAllStringFunc(s, func(u string) string {
+		return protect("<" + u + ">")
+	})
+	s = mdMentionRe.ReplaceAllStringFunc(s, func(match string) string {
+		sub := mdMentionRe.FindStringSubmatch(match)
+		return sub[1] + protect(c.mention(sub[2], sub[3]))
+	})
+
+	s = mrkdwnEscaper.Replace(s)
+	s = mdBoldRe.ReplaceAllString(s, "\x01$2\x01")
+	s = mdItalicStar.ReplaceAllString(s, "${1}_${2}_$3")
+	s = mdStrikeRe.ReplaceAllString(s, "~$1~")
+	s = strings.ReplaceAll(s, "\x01", "*")
+
+	return mdPlaceholder.ReplaceAllStringFunc(s, func(p string) string {
+		n, _ := strconv.Atoi(strings.Trim(p, "\x00"))
+		return protected[n]
+	})
+}
+
+func (c *converter) mention(sigil, name string) string {
+	if sigil == "@" {
+		switch name {
+		case "here", "channel", "everyone":
+			return "<!" + name + ">"
+		}
+		if c.mentions.User != nil {
+			if id := c.mentions.User(name); id != "" {
+				return "<@" + id + ">"
+			}
+		}
+		if !c.unknown[name] {
+			if c.unknown == nil {
+				c.unknown = make(map[string]bool)
+			}
+			c.unknown[name] = true
+			c.warn("user @%s was not found, it was left as plain text", name)
+		}
+		return "@" + name
+	}
+
+	if c.mentions.Channel != nil {
+		if id := c.mentions.Channel(name); id != "" {
+			return "<#" + id + ">"
+		}
+	}
+	return "#" + name
+}
+
+// stripMarkdown drops emphasis and code markers for plain text contexts.
+func stripMarkdown(s string) string {
+	s = mdLinkRe.ReplaceAllString(s, "$1")
+	return strings.NewReplacer("**", "", "__", "", "~~", "", "`", "").Replace(s)
+}
+
+func splitLines(s string, limit int) []string {
+	var parts []string
+	var cur []string
+	size := 0
+	for _, line := range chunkLines(s, limit) {
+		if size+len(line)+1 > limit && len(cur) > 0 {
+			parts = append(parts, strings.Join(cur, "\n"))
+			cur, size = nil, 0
+		}
+		cur = append(cur, line)
+		size += len(line) + 1
+	}
+	if len(cur) > 0 {
+		parts = append(parts, strings.Join(cur, "\n"))
+	}
+	return parts
+}
+
+// chunkLines splits s into lines of at most limit bytes. Longer lines are
+// broken at the last space that fits, or at a rune boundary when there is
+// none, so nothing is lost.
+func chunkLines(s string, limit int) []string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		for len(line) > limit {
+			cut := truncateBytes(line, limit)
+			if i := strings.LastIndexAny(cut, " \t"); i > 0 {
+				cut = cut[:i+1]
+			}
+			lines = append(lines, strings.TrimRight(cut, " \t"))
+			line = line[len(cut):]
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
+
+func truncateBytes(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
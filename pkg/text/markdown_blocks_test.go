@@ -0,0 +1,139 @@
+package text
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testMentions = Mentions{
+	User: func(name string) string {
+		if name == "alice" {
+			return "U123"
+		}
+		return ""
+	},
+	Channel: func(name string) string {
+		if name == "general" {
+			return "C123"
+		}
+		return ""
+	},
+}
+
+func sectionText(t *testing.T, b slack.Block) string {
+	t.Helper()
+	s, ok := b.(*slack.SectionBlock)
+	require.True(t, ok, "expected section block, got %T", b)
+	return s.Text.Text
+}
+
+func TestUnitMarkdownToBlocksInline(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"bold", "this is **important**", "this is *important*"},
+		{"italic", "an *italic* and _other_ word", "an _italic_ and _other_ word"},
+		{"strike", "~~gone~~", "~gone~"},
+		{"link", "see [the docs](https://example.com)", "see <https://example.com|the docs>"},
+		{"bare url with underscores", "https://example.com/a_b_c", "<https://example.com/a_b_c>"},
+		{"escaping", "a < b && c > d", "a &lt; b &amp;&amp; c &gt; d"},
+		{"code span untouched", "run `**x** <y>`", "run `**x** &lt;y&gt;`"},
+		{"user mention", "ping @alice please", "ping <@U123> please"},
+		{"channel mention", "see #general", "see <#C123>"},
+		{"broadcast", "@here deploy", "<!here> deploy"},
+		{"email is not a mention", "bob@example.com", "bob@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv := MarkdownToBlocks(tt.input, testMentions)
+			require.Len(t, conv.Blocks, 1)
+			assert.Equal(t, tt.want, sectionText(t, conv.Blocks[0]))
+		})
+	}
+}
+
+func TestUnitMarkdownToBlocksStructure(t *testing.T) {
+	md := strings.Join([]string{
+		"# Release notes",
+		"",
+		"- first",
+		"  - nested",
+		"    1. deep",
+		"- [x] done",
+		"",
+		"```go",
+		"if a < b {",
+		"```",
+		"",
+		"---",
+		"| Name | Count |",
+		"|------|------:|",
+		"| a | 10 |",
+		"| long name | 2 |",
+	}, "\n")
+
+	conv := MarkdownToBlocks(md, testMentions)
+	assert.Empty(t, conv.Warnings)
+	require.Len(t, conv.Blocks, 4)
+
+	header, ok := conv.Blocks[0].(*slack.HeaderBlock)
+	require.True(t, ok)
+	assert.Equal(t, "Release notes", header.Text.Text)
+
+	assert.Equal(t, "• first\n    ◦ nested\n        1. deep\n• ☑ done\n\n```\nif a &lt; b {\n```", sectionText(t, conv.Blocks[1]))
+
+	_, ok = conv.Blocks[2].(*slack.DividerBlock)
+	assert.True(t, ok)
+
+	assert.Equal(t, "```\nName      | Count\n----------+------\na         | 10\nlong name | 2\n```", sectionText(t, conv.Blocks[3]))
+}
+
+func TestUnitMarkdownToBlocksWarnings(t *testing.T) {
+	conv := MarkdownToBlocks("hi @nobody and @nobody\n```\nunterminated", testMentions)
+	require.Len(t, conv.Warnings, 2)
+	assert.Contains(t, conv.Warnings[0], "@nobody")
+	assert.Contains(t, conv.Warnings[1], "never closed")
+
+	conv = MarkdownToBlocks(strings.Repeat("# h\n", maxMessageBlocks+1), testMentions)
+	assert.Empty(t, conv.Blocks)
+	assert.NotEmpty(t, conv.Text)
+	require.Len(t, conv.Warnings, 1)
+}
+
+func TestUnitMarkdownToBlocksLongLines(t *testing.T) {
+	var words []string
+	for i := range 1000 {
+		words = append(words, fmt.Sprintf("w%03d", i))
+	}
+	paragraph := strings.Join(words, " ") // 4999 characters on one line
+
+	conv := MarkdownToBlocks(paragraph, testMentions)
+	require.Len(t, conv.Blocks, 2)
+	var got []string
+	for _, b := range conv.Blocks {
+		s := sectionText(t, b)
+		assert.LessOrEqual(t, len(s), maxSectionText)
+		got = append(got, strings.Fields(s)...)
+	}
+	assert.Equal(t, words, got, "the paragraph is split at spaces, nothing is dropped")
+
+	code := strings.Repeat("é", 2500) // 5000 bytes without a space
+	conv = MarkdownToBlocks("```\n"+code+"\n```", testMentions)
+	var body string
+	for _, b := range conv.Blocks {
+		s := sectionText(t, b)
+		assert.LessOrEqual(t, len(s), maxSectionText)
+		for _, part := range strings.Split(s, "```") {
+			body += strings.TrimSpace(part)
+		}
+	}
+	assert.Equal(t, code, body, "a long code line is split at rune boundaries")
+}
@@ -0,0 +1,83 @@
+package text
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitMrkdwnToMarkdown(t *testing.T) {
+	resolver := Resolver{
+		User: func(id string) string {
+			if id == "U123" {
+				return "alice"
+			}
+			return ""
+		},
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"bold", "this is *important* stuff", "this is **important** stuff"},
+		{"italic kept", "an _italic_ word", "an _italic_ word"},
+		{"strike", "~gone~ now", "~~gone~~ now"},
+		{"labelled link", "see <https://example.com|the docs>", "see [the docs](https://example.com)"},
+		{"bare link", "see <https://example.com>", "see https://example.com"},
+		{"mailto", "<mailto:bob@example.com|bob@example.com>", "bob@example.com"},
+		{"user mention resolved", "ping <@U123>", "ping @alice"},
+		{"user mention unknown", "ping <@U999>", "ping @U999"},
+		{"channel mention", "in <#C123|general>", "in #general"},
+		{"broadcast", "<!here> deploy", "@here deploy"},
+		{"entities", "a &lt; b &amp;&amp; c &gt; d", "a < b && c > d"},
+		{"quote", "&gt; quoted line", "> quoted line"},
+		{"bullets", "• one\n• two", "- one\n- two"},
+		{"inline code untouched", "run `*not bold* &lt;x&gt;`", "run `*not bold* <x>`"},
+		{"fence", "before ```*x* <y>``` after", "before \n```\n*x* <y>\n```\n after"},
+		{"non ascii kept", "naïve — “quoted” 🎉", "naïve — “quoted” 🎉"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, MrkdwnToMarkdown(tt.input, resolver))
+		})
+	}
+}
+
+func TestUnitBlocksToMarkdown(t *testing.T) {
+	raw := `[
+		{"type": "rich_text", "elements": [
+			{"type": "rich_text_section", "elements": [
+				{"type": "text", "text": "Hello "},
+				{"type": "user", "user_id": "U123"},
+				{"type": "text", "text": " done", "style": {"bold": true}},
+				{"type": "emoji", "name": "tada", "unicode": "1f389"}
+			]},
+			{"type": "rich_text_list", "style": "ordered", "indent": 0, "elements": [
+				{"type": "rich_text_section", "elements": [{"type": "text", "text": "first"}]},
+				{"type": "rich_text_section", "elements": [{"type": "link", "url": "https://example.com", "text": "second"}]}
+			]},
+			{"type": "rich_text_preformatted", "elements": [{"type": "text", "text": "go test ./..."}]}
+		]},
+		{"type": "section", "text": {"type": "mrkdwn", "text": "*Status*: ok"}}
+	]`
+
+	var blocks slack.Blocks
+	require.NoError(t, json.Unmarshal([]byte(raw), &blocks))
+
+	got := BlocksToMarkdown(blocks, Resolver{User: func(string) string { return "alice" }})
+	want := "Hello @alice **done**🎉\n1. first\n2. [second](https://example.com)\n```\ngo test ./...\n```\n\n**Status**: ok"
+	assert.Equal(t, want, got)
+}
+
+func TestUnitIsTruncatedText(t *testing.T) {
+	assert.True(t, IsTruncatedText(""))
+	assert.True(t, IsTruncatedText("This content can't be displayed."))
+	assert.True(t, IsTruncatedText("A very long message…"))
+	assert.False(t, IsTruncatedText("complete"))
+}
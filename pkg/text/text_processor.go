@@ -152,25 +152,127 @@ func Workspace(rawURL string) (string, error) {
 	return parts[0], nil
 }
 
+// Permalink builds a message link in the same format the Slack client uses,
+// e.g. https://team.slack.com/archives/C1234567890/p1700000000123456. Replies
+// carry the parent thread_ts so the link opens inside the thread.
+func Permalink(workspaceURL, channelID, ts, threadTs string) string {
+	if workspaceURL == "" || channelID == "" || ts == "" {
+		return ""
+	}
+
+	link := strings.TrimSuffix(workspaceURL, "/") + "/archives/" + channelID + "/p" + strings.Replace(ts, ".", "", 1)
+	if threadTs != "" && threadTs != ts {
+		q := url.Values{}
+		q.Set("thread_ts", threadTs)
+		q.Set("cid", channelID)
+		link += "?" + q.Encode()
+	}
+
+	return link
+}
+
+// ParsePermalink extracts channel ID, message ts and optional thread_ts from
+// a Slack message link produced by Permalink or by the Slack client.
+func ParsePermalink(rawURL string) (channelID, ts, threadTs string, err error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", "", "", err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return "", "", "", fmt.Errorf("invalid Slack permalink: %q", rawURL)
+	}
+
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) != 3 || parts[0] != "archives" || parts[1] == "" {
+		return "", "", "", fmt.Errorf("invalid Slack permalink: %q", rawURL)
+	}
+
+	raw := strings.TrimPrefix(parts[2], "p")
+	if len(raw) != 16 || raw == parts[2] {
+		return "", "", "", fmt.Errorf("invalid message id in Slack permalink: %q", rawURL)
+	}
+	if _, err := strconv.ParseInt(raw, 10, 64); err != nil {
+		return "", "", "", fmt.Errorf("invalid message id in Slack permalink: %q", rawURL)
+	}
+
+	return parts[1], raw[:10] + "." + raw[10:], u.Query().Get("thread_ts"), nil
+}
+
 func TimestampToIsoRFC3339(slackTS string) (string, error) {
+	return TimestampToIsoRFC3339In(slackTS, time.UTC)
+}
+
+// TimestampToIsoRFC3339In formats a Slack timestamp as RFC3339 in the given
+// location, so the offset in the output matches the reader's wall clock.
+func TimestampToIsoRFC3339In(slackTS string, loc *time.Location) (string, error) {
+	t, err := TimestampToTime(slackTS)
+	if err != nil {
+		return "", err
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	return t.In(loc).Format(time.RFC3339), nil
+}
+
+func TimestampToTime(slackTS string) (time.Time, error) {
 	parts := strings.Split(slackTS, ".")
 	if len(parts) != 2 {
-		return "", fmt.Errorf("invalid slack timestamp format: %s", slackTS)
+		return time.Time{}, fmt.Errorf("invalid slack timestamp format: %s", slackTS)
 	}
 
 	seconds, err := strconv.ParseInt(parts[0], 10, 64)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse seconds: %v", err)
+		return time.Time{}, fmt.Errorf("failed to parse seconds: %v", err)
 	}
 
 	microseconds, err := strconv.ParseInt(parts[1], 10, 64)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse microseconds: %v", err)
+		return time.Time{}, fmt.Errorf("failed to parse microseconds: %v", err)
 	}
 
-	t := time.Unix(seconds, microseconds*1000)
+	return time.Unix(seconds, microseconds*1000), nil
+}
+
+// HumanizeTimeSince renders t relative to now, e.g. "5 minutes ago" or
+// "in 2 days". Months and years are approximated as 30 and 365 days.
+func HumanizeTimeSince(t, now time.Time) string {
+	d := now.Sub(t)
+	future := d < 0
+	if future {
+		d = -d
+	}
+
+	const day = 24 * time.Hour
+
+	var s string
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		s = pluralize(int(d/time.Minute), "minute")
+	case d < day:
+		s = pluralize(int(d/time.Hour), "hour")
+	case d < 30*day:
+		s = pluralize(int(d/day), "day")
+	case d < 365*day:
+		s = pluralize(int(d/(30*day)), "month")
+	default:
+		s = pluralize(int(d/(365*day)), "year")
+	}
 
-	return t.UTC().Format(time.RFC3339), nil
+	if future {
+		return "in " + s
+	}
+	return s + " ago"
+}
+
+func pluralize(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
 }
 
 func ProcessText(s string) string {
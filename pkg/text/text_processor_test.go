@@ -2,6 +2,7 @@ package text
 
 import (
 	"testing"
+	"time"
 )
 
 func TestIsUnfurlingEnabled(t *testing.T) {
@@ -160,3 +161,115 @@ func TestFilterSpecialCharsWithCommas(t *testing.T) {
 		})
 	}
 }
+
+func TestUnitTimestampToIsoRFC3339In(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("tzdata not available: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		ts      string
+		loc     *time.Location
+		want    string
+		wantErr bool
+	}{
+		{"utc", "1700000000.123456", time.UTC, "2023-11-14T22:13:20Z", false},
+		{"nil location falls back to utc", "1700000000.000000", nil, "2023-11-14T22:13:20Z", false},
+		{"tokyo", "1700000000.000000", tokyo, "2023-11-15T07:13:20+09:00", false},
+		{"invalid", "1700000000", time.UTC, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TimestampToIsoRFC3339In(tt.ts, tt.loc)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TimestampToIsoRFC3339In() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("TimestampToIsoRFC3339In() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnitHumanizeTimeSince(t *testing.T) {
+	now := time.Date(2025, 7, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		t    time.Time
+		want string
+	}{
+		{"seconds", now.Add(-30 * time.Second), "just now"},
+		{"one minute", now.Add(-time.Minute), "1 minute ago"},
+		{"minutes", now.Add(-45 * time.Minute), "45 minutes ago"},
+		{"hours", now.Add(-5 * time.Hour), "5 hours ago"},
+		{"days", now.Add(-3 * 24 * time.Hour), "3 days ago"},
+		{"months", now.Add(-65 * 24 * time.Hour), "2 months ago"},
+		{"years", now.Add(-800 * 24 * time.Hour), "2 years ago"},
+		{"future", now.Add(2 * time.Hour), "in 2 hours"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HumanizeTimeSince(tt.t, now); got != tt.want {
+				t.Errorf("HumanizeTimeSince() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnitPermalink(t *testing.T) {
+	tests := []struct {
+		name     string
+		channel  string
+		ts       string
+		threadTs string
+		want     string
+	}{
+		{"channel message", "C1234567890", "1700000000.123456", "", "https://team.slack.com/archives/C1234567890/p1700000000123456"},
+		{"thread parent", "C1234567890", "1700000000.123456", "1700000000.123456", "https://team.slack.com/archives/C1234567890/p1700000000123456"},
+		{"thread reply", "C1234567890", "1700000001.000100", "1700000000.123456", "https://team.slack.com/archives/C1234567890/p1700000001000100?cid=C1234567890&thread_ts=1700000000.123456"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Permalink("https://team.slack.com/", tt.channel, tt.ts, tt.threadTs)
+			if got != tt.want {
+				t.Fatalf("Permalink() = %q, want %q", got, tt.want)
+			}
+
+			channel, ts, threadTs, err := ParsePermalink(got)
+			if err != nil {
+				t.Fatalf("ParsePermalink() error = %v", err)
+			}
+			if channel != tt.channel || ts != tt.ts {
+				t.Errorf("ParsePermalink() = %q, %q; want %q, %q", channel, ts, tt.channel, tt.ts)
+			}
+			if tt.threadTs != tt.ts && threadTs != tt.threadTs {
+				t.Errorf("ParsePermalink() thread_ts = %q, want %q", threadTs, tt.threadTs)
+			}
+		})
+	}
+}
+
+func TestUnitParsePermalinkInvalid(t *testing.T) {
+	invalid := []string{
+		"marketing report",
+		"C1234567890:1700000000.123456",
+		"https://team.slack.com/archives/C1234567890",
+		"https://team.slack.com/archives/C1234567890/1700000000123456",
+		"https://team.slack.com/archives/C1234567890/p17000000001234",
+		"https://team.slack.com/messages/C1234567890/p1700000000123456",
+	}
+
+	for _, in := range invalid {
+		t.Run(in, func(t *testing.T) {
+			if _, _, _, err := ParsePermalink(in); err == nil {
+				t.Errorf("expected error for %q", in)
+			}
+		})
+	}
+}
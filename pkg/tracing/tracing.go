@@ -0,0 +1,66 @@
+// Package tracing sets up the optional OpenTelemetry tracing of tool calls
+// and Slack API requests, exported over OTLP/HTTP.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/version"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/korotovsky/slack-mcp-server"
+
+// Span attributes specific to this server.
+const (
+	ToolName         = attribute.Key("mcp.tool.name")
+	SessionID        = attribute.Key("mcp.session.id")
+	SlackMethod      = attribute.Key("slack.method")
+	SlackRateLimited = attribute.Key("slack.rate_limited")
+	SlackRetryAfter  = attribute.Key("slack.retry_after_seconds")
+)
+
+// Tracer returns the tracer of the server, it does nothing until Setup
+// installed an exporter.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName, trace.WithInstrumentationVersion(version.Version))
+}
+
+// Setup exports spans to the OTLP/HTTP collector at endpoint, e.g.
+// http://localhost:4318, sampling the given ratio of traces. It returns a
+// function that flushes the pending spans, tracing stays disabled and the
+// function is a no-op when endpoint is empty.
+func Setup(ctx context.Context, endpoint string, ratio float64) (func(context.Context) error, error) {
+	if endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the OTLP exporter for %q: %w", endpoint, err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(version.BinaryName),
+		semconv.ServiceVersion(version.Version),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(tp)
+
+	return tp.Shutdown, nil
+}
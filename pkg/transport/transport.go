@@ -11,12 +11,18 @@ import (
 	"net"
 	"net/http"
 	"net/url"
-	"os"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/korotovsky/slack-mcp-server/pkg/config"
+	"github.com/korotovsky/slack-mcp-server/pkg/metrics"
 	"github.com/korotovsky/slack-mcp-server/pkg/text"
+	"github.com/korotovsky/slack-mcp-server/pkg/tracing"
 	utls "github.com/refraction-networking/utls"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"golang.org/x/net/http2"
 )
@@ -79,6 +85,60 @@ func (t *UserAgentTransport) RoundTrip(req *http.Request) (*http.Response, error
 	return resp, err
 }
 
+// metricsTransport counts Slack API requests by method and status.
+type metricsTransport struct {
+	roundTripper http.RoundTripper
+}
+
+func (t metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.roundTripper.RoundTrip(req)
+	status := 0
+	if err == nil {
+		status = resp.StatusCode
+	}
+	metrics.ObserveSlackRequest(req.URL.Path, status, time.Since(start))
+	return resp, err
+}
+
+// tracingTransport records a client span per Slack API request. Trace
+// context is not propagated to Slack.
+type tracingTransport struct {
+	roundTripper http.RoundTripper
+}
+
+func (t tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	method := metrics.MethodFromPath(req.URL.Path)
+	ctx, span := tracing.Tracer().Start(req.Context(), "slack "+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			tracing.SlackMethod.String(method),
+			semconv.HTTPRequestMethodKey.String(req.Method),
+			semconv.ServerAddress(req.URL.Host),
+		),
+	)
+	defer span.End()
+
+	resp, err := t.roundTripper.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return resp, err
+	}
+
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	if resp.StatusCode == http.StatusTooManyRequests {
+		span.SetAttributes(tracing.SlackRateLimited.Bool(true))
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			span.SetAttributes(tracing.SlackRetryAfter.Int(secs))
+		}
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+	return resp, err
+}
+
 // uTLSTransport is a custom http.RoundTripper that uses uTLS for TLS connections
 type uTLSTransport struct {
 	dialer         *net.Dialer
@@ -333,20 +393,31 @@ func detectBrowserFromUserAgent(userAgent string) utls.ClientHelloID {
 	return utls.HelloChrome_Auto
 }
 
-// ProvideHTTPClient creates an HTTP client with optional uTLS support
-func ProvideHTTPClient(cookies []*http.Cookie, logger *zap.Logger) *http.Client {
-	if os.Getenv("SLACK_MCP_PROXY") != "" && os.Getenv("SLACK_MCP_CUSTOM_TLS") != "" {
-		logger.Fatal("SLACK_MCP_PROXY and SLACK_MCP_CUSTOM_TLS cannot be used together",
-			zap.String("reason", "Custom TLS fingerprinting has no effect when using a proxy, as the target server sees the proxy's TLS handshake"))
+// Fingerprint names the browser whose TLS handshake is mimicked, it is empty
+// when SLACK_MCP_CUSTOM_TLS is off.
+func Fingerprint(cfg *config.Config) string {
+	if !cfg.CustomTLS {
+		return ""
 	}
+	userAgent := defaultUA
+	if cfg.UserAgent != "" {
+		userAgent = cfg.UserAgent
+	}
+	t := &uTLSTransport{clientHelloID: detectBrowserFromUserAgent(userAgent)}
+	return t.getClientHelloName()
+}
 
+// ProvideHTTPClient creates an HTTP client with optional uTLS support
+func ProvideHTTPClient(cfg *config.Config, cookies []*http.Cookie, logger *zap.Logger) *http.Client {
 	var proxy func(*http.Request) (*url.URL, error)
-	if proxyURL := os.Getenv("SLACK_MCP_PROXY"); proxyURL != "" {
+	if proxyURL := cfg.Proxy; proxyURL != "" {
 		parsed, err := url.Parse(proxyURL)
 		if err != nil {
-			logger.Fatal("Failed to parse proxy URL",
-				zap.String("proxy_url", proxyURL),
-				zap.Error(err))
+			// the error quotes the URL along with its credentials
+			if ue, ok := err.(*url.Error); ok {
+				err = ue.Err
+			}
+			logger.Fatal("Failed to parse SLACK_MCP_PROXY", zap.Error(err))
 		}
 		proxy = http.ProxyURL(parsed)
 	}
@@ -356,13 +427,13 @@ func ProvideHTTPClient(cookies []*http.Cookie, logger *zap.Logger) *http.Client
 		rootCAs = x509.NewCertPool()
 	}
 
-	if isToolkit := os.Getenv("SLACK_MCP_SERVER_CA_TOOLKIT"); isToolkit != "" {
+	if cfg.ServerCAToolkit {
 		if ok := rootCAs.AppendCertsFromPEM([]byte(toolkitPEM)); !ok {
 			logger.Warn("Failed to append toolkit certificate")
 		}
 	}
 
-	if localCertFile := os.Getenv("SLACK_MCP_SERVER_CA"); localCertFile != "" {
+	if localCertFile := cfg.ServerCA; localCertFile != "" {
 		certs, err := ioutil.ReadFile(localCertFile)
 		if err != nil {
 			logger.Fatal("Failed to read local certificate file",
@@ -374,22 +445,16 @@ func ProvideHTTPClient(cookies []*http.Cookie, logger *zap.Logger) *http.Client
 		}
 	}
 
-	insecure := false
-	if os.Getenv("SLACK_MCP_SERVER_CA_INSECURE") != "" {
-		if localCertFile := os.Getenv("SLACK_MCP_SERVER_CA"); localCertFile != "" {
-			logger.Fatal("SLACK_MCP_SERVER_CA and SLACK_MCP_SERVER_CA_INSECURE cannot be used together")
-		}
-		insecure = true
-	}
+	insecure := cfg.ServerCAInsecure
 
 	userAgent := defaultUA
-	if ua := os.Getenv("SLACK_MCP_USER_AGENT"); ua != "" {
+	if ua := cfg.UserAgent; ua != "" {
 		userAgent = ua
 	}
 
 	var transport http.RoundTripper
 
-	if useCustomTLS := os.Getenv("SLACK_MCP_CUSTOM_TLS"); useCustomTLS != "" {
+	if cfg.CustomTLS {
 		logger.Debug("Custom TLS handshake enabled",
 			zap.String("user_agent", userAgent))
 
@@ -441,6 +506,8 @@ func ProvideHTTPClient(cookies []*http.Cookie, logger *zap.Logger) *http.Client
 	}
 
 	transport = NewUserAgentTransport(transport, userAgent, cookies, logger)
+	transport = metricsTransport{roundTripper: transport}
+	transport = tracingTransport{roundTripper: transport}
 
 	client := &http.Client{
 		Transport: transport,
@@ -0,0 +1,60 @@
+package transport
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestUnitTracingTransport(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("traceparent"))
+		if r.URL.Path == "/api/search.messages" {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: tracingTransport{roundTripper: http.DefaultTransport}}
+	for _, path := range []string{"/api/conversations.history", "/api/search.messages"} {
+		resp, err := client.Get(srv.URL + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	attrs := func(s sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+		m := make(map[attribute.Key]attribute.Value)
+		for _, kv := range s.Attributes() {
+			m[kv.Key] = kv.Value
+		}
+		return m
+	}
+
+	assert.Equal(t, "slack conversations.history", spans[0].Name())
+	assert.Equal(t, "conversations.history", attrs(spans[0])[tracing.SlackMethod].AsString())
+	assert.Equal(t, int64(200), attrs(spans[0])["http.response.status_code"].AsInt64())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+
+	assert.Equal(t, "slack search.messages", spans[1].Name())
+	assert.True(t, attrs(spans[1])[tracing.SlackRateLimited].AsBool())
+	assert.Equal(t, int64(7), attrs(spans[1])[tracing.SlackRetryAfter].AsInt64())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+}